@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type httpArticleRepository struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPArticleRepository will create an object that represent the domain.ArticleRepository interface, reading
+// the article service at baseURL. Both reads are a GET /articles answering a JSON array of articles, filtered by
+// ids=1,2 or by author_ids=1,2 with per_author latest articles of each author.
+func NewHTTPArticleRepository(baseURL string, client *http.Client) domain.ArticleRepository {
+	return &httpArticleRepository{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+func (m *httpArticleRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	return m.fetch(ctx, url.Values{"ids": {joinIDs(ids)}})
+}
+
+func (m *httpArticleRepository) FetchByAuthorIDs(ctx context.Context, authorIDs []int64, num int64) ([]domain.Article, error) {
+	return m.fetch(ctx, url.Values{"author_ids": {joinIDs(authorIDs)}, "per_author": {strconv.FormatInt(num, 10)}})
+}
+
+func (m *httpArticleRepository) fetch(ctx context.Context, query url.Values) (res []domain.Article, err error) {
+	req, err := http.NewRequest(http.MethodGet, m.baseURL+"/articles?"+query.Encode(), nil)
+	if err != nil {
+		return
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("article service answered %s", resp.Status)
+	}
+	res = make([]domain.Article, 0)
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	return
+}
+
+func joinIDs(ids []int64) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,45 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	articleHttpRepo "github.com/diantanjung/blogo/user-service/article/repository/http"
+)
+
+func TestFetchByAuthorIDs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/articles", r.URL.Path)
+		assert.Equal(t, "1,2", r.URL.Query().Get("author_ids"))
+		assert.Equal(t, "5", r.URL.Query().Get("per_author"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":10,"author_id":1,"title":"Hello","slug":"hello","published_at":"2024-01-02T03:04:05Z"}]`))
+	}))
+	defer srv.Close()
+
+	r := articleHttpRepo.NewHTTPArticleRepository(srv.URL+"/", srv.Client())
+
+	list, err := r.FetchByAuthorIDs(context.TODO(), []int64{1, 2}, 5)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(1), list[0].AuthorID)
+	assert.Equal(t, "Hello", list[0].Title)
+}
+
+func TestGetArticlesByIDsFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "3,4", r.URL.Query().Get("ids"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	r := articleHttpRepo.NewHTTPArticleRepository(srv.URL, srv.Client())
+
+	_, err := r.GetByIDs(context.TODO(), []int64{3, 4})
+	assert.Error(t, err)
+}
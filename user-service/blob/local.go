@@ -0,0 +1,81 @@
+// Package blob stores the files uploaded by users, on the local filesystem or an S3 compatible service
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore is a domain.BlobStore keeping the blobs in a directory, the service serves them itself
+type LocalStore struct {
+	dir     string
+	baseURL string
+	files   http.Handler
+}
+
+// NewLocalStore will create a LocalStore writing to dir, the URL of a blob is its key appended to baseURL
+func NewLocalStore(dir, baseURL string) *LocalStore {
+	return &LocalStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		files:   http.FileServer(http.Dir(dir)),
+	}
+}
+
+// Put will write the blob to a temporary file renamed over the key, readers never see a partial blob
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
+	if err = os.Chmod(f.Name(), 0644); err != nil {
+		return "", err
+	}
+	if err = os.Rename(f.Name(), name); err != nil {
+		return "", err
+	}
+	return s.baseURL + "/" + key, nil
+}
+
+// ServeHTTP serves the blobs by the path of their key, directories are not listed
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/") || strings.Contains(path.Base(r.URL.Path), ".upload-") {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	s.files.ServeHTTP(w, r)
+}
+
+// path will resolve key within the directory of the store, keys may not escape it
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
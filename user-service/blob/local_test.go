@@ -0,0 +1,49 @@
+package blob_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/blob"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	s := blob.NewLocalStore(dir, "http://localhost:9090/blobs/")
+
+	url, err := s.Put(context.TODO(), "avatars/1/64.jpg", strings.NewReader("first"), "image/jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9090/blobs/avatars/1/64.jpg", url)
+	_, err = s.Put(context.TODO(), "avatars/1/64.jpg", strings.NewReader("second"), "image/jpeg")
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(dir, "avatars", "1", "64.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(b))
+	entries, err := os.ReadDir(filepath.Join(dir, "avatars", "1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are removed")
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/avatars/1/64.jpg", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "second", rec.Body.String())
+	assert.NotEmpty(t, rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/avatars/1/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "directories are not listed")
+
+	for _, key := range []string{"", "../secret", "avatars/../../secret", "/etc/passwd", "avatars//1.jpg"} {
+		_, err = s.Put(context.TODO(), key, strings.NewReader("x"), "text/plain")
+		assert.Error(t, err, key)
+	}
+}
@@ -0,0 +1,31 @@
+package client
+
+import "net/http"
+
+// Authenticator adds credentials to the requests of a Client
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc is an Authenticator calling itself, e.g. to fetch a fresh access token
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken will authenticate requests with an access token from POST /auth/login
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKey will identify requests with an API key, which the rate limits can count by
+func APIKey(key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("X-API-Key", key)
+		return nil
+	})
+}
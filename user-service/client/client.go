@@ -0,0 +1,233 @@
+// Package client is a typed Go client of the user service HTTP API
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// Client calls the user service at a base URL, e.g. http://localhost:9090
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	auth       Authenticator
+	retry      RetryPolicy
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient will make the Client send its requests with c, http.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *Client) { cl.httpClient = c }
+}
+
+// WithAuth will make the Client authenticate every request with a
+func WithAuth(a Authenticator) Option {
+	return func(cl *Client) { cl.auth = a }
+}
+
+// WithRetry will replace the DefaultRetryPolicy of the Client
+func WithRetry(p RetryPolicy) Option {
+	return func(cl *Client) { cl.retry = p }
+}
+
+// NewClient will create a Client of the user service at baseURL
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ListOptions selects a page of users, Num defaults to 10 on the server
+type ListOptions struct {
+	Num    int64
+	Cursor string
+}
+
+// List will return a page of users and the cursor of the next one, empty on the last page
+func (c *Client) List(ctx context.Context, opts ListOptions) ([]domain.User, string, error) {
+	q := url.Values{}
+	if opts.Num > 0 {
+		q.Set("num", strconv.FormatInt(opts.Num, 10))
+	}
+	if opts.Cursor != "" {
+		q.Set("cursor", opts.Cursor)
+	}
+
+	var users []domain.User
+	res, err := c.do(ctx, http.MethodGet, "/users?"+q.Encode(), nil, &users)
+	if err != nil {
+		return nil, "", err
+	}
+	return users, res.Header.Get("X-Cursor"), nil
+}
+
+// Get will return the user with the given id, domain.ErrNotFound when there is none
+func (c *Client) Get(ctx context.Context, id int64) (domain.User, error) {
+	var u domain.User
+	_, err := c.do(ctx, http.MethodGet, "/users/"+strconv.FormatInt(id, 10), nil, &u)
+	return u, err
+}
+
+// Create will sign up u and return the created user
+func (c *Client) Create(ctx context.Context, u domain.User) (domain.User, error) {
+	var res domain.User
+	_, err := c.do(ctx, http.MethodPost, "/users", u, &res)
+	return res, err
+}
+
+// Update will replace the profile of the user u.ID and return the updated user
+func (c *Client) Update(ctx context.Context, u domain.User) (domain.User, error) {
+	var res domain.User
+	_, err := c.do(ctx, http.MethodPatch, "/users/"+strconv.FormatInt(u.ID, 10), u, &res)
+	return res, err
+}
+
+// Delete will delete the user with the given id
+func (c *Client) Delete(ctx context.Context, id int64) error {
+	_, err := c.do(ctx, http.MethodDelete, "/users/"+strconv.FormatInt(id, 10), nil, nil)
+	return err
+}
+
+// do will send the request, retrying it per the RetryPolicy, and decode a successful response into out
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) (*http.Response, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.send(ctx, method, path, body)
+		if err == nil && res.StatusCode < 300 {
+			defer res.Body.Close()
+			if out != nil && res.StatusCode != http.StatusNoContent {
+				if err = json.NewDecoder(res.Body).Decode(out); err != nil {
+					return nil, err
+				}
+			}
+			return res, nil
+		}
+
+		var wait time.Duration
+		retry := attempt < c.retry.MaxAttempts && c.retry.retryable(method)
+		if err == nil {
+			retry = retry && retryableStatus(res.StatusCode)
+			wait = retryAfter(res)
+			if !retry {
+				defer res.Body.Close()
+				return nil, decodeError(res)
+			}
+			// drain the body so the connection is reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+		} else if !retry || ctx.Err() != nil {
+			return nil, err
+		}
+
+		if wait == 0 {
+			wait = c.retry.backoff(attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.auth != nil {
+		if err = c.auth.Authenticate(req); err != nil {
+			return nil, err
+		}
+	}
+	return c.httpClient.Do(req)
+}
+
+// retryAfter returns the wait the server asked for with Retry-After, in seconds, or 0
+func retryAfter(res *http.Response) time.Duration {
+	secs, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// RetryPolicy decides how failed requests are retried. Requests are retried on network errors and
+// 429, 502, 503 and 504 responses, honoring Retry-After. POST requests are not retried unless RetryPOST is set,
+// since the server may have processed them.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of a request including the first, 1 disables retries
+	MaxAttempts int
+	// Backoff is the wait after the first failed attempt, doubled on every following one up to MaxBackoff, with jitter
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	RetryPOST  bool
+}
+
+// DefaultRetryPolicy makes up to 3 attempts
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+func (p RetryPolicy) retryable(method string) bool {
+	return method != http.MethodPost || p.RetryPOST
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	b := p.Backoff << uint(attempt-1)
+	if b <= 0 || b > p.MaxBackoff {
+		b = p.MaxBackoff
+	}
+	if b <= 0 {
+		return 0
+	}
+	// full jitter spreads the retries of concurrent clients
+	return time.Duration(rand.Int63n(int64(b))) + 1
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Error is returned for error responses that do not match a domain error
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("user service: %d %s", e.StatusCode, e.Message)
+}
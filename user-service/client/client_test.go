@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/client"
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userHttp "github.com/diantanjung/blogo/user-service/user/delivery/http"
+)
+
+var fastRetry = client.WithRetry(client.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+// newServer serves the real user handlers on top of us
+func newServer(t *testing.T, us domain.UserUsecase) *httptest.Server {
+	e := echo.New()
+	userHttp.NewUsersHandler(e, us)
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetAndErrors(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).Return(domain.User{ID: 1, Username: "dias"}, nil)
+	mockUCase.On("GetByID", mock.Anything, int64(9)).Return(domain.User{}, domain.ErrNotFound)
+	mockUCase.On("Delete", mock.Anything, int64(2)).Return(domain.ErrForbidden)
+	c := client.NewClient(newServer(t, mockUCase).URL)
+
+	u, err := c.Get(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "dias", u.Username)
+
+	_, err = c.Get(context.TODO(), 9)
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	err = c.Delete(context.TODO(), 2)
+	assert.Equal(t, domain.ErrForbidden, err)
+}
+
+func TestCreateAndUpdate(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Store", mock.Anything, mock.MatchedBy(func(u *domain.User) bool { return u.Password == "ASDF1234" })).
+		Run(func(args mock.Arguments) {
+			u := args.Get(1).(*domain.User)
+			u.ID, u.Password = 3, ""
+		}).Return(nil)
+	mockUCase.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool { return u.ID == 3 })).Return(domain.ErrConflict)
+	c := client.NewClient(newServer(t, mockUCase).URL)
+
+	u, err := c.Create(context.TODO(), domain.User{Username: "new", Name: "New", Email: "new@gmail.com", Password: "ASDF1234"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), u.ID)
+	assert.Empty(t, u.Password)
+
+	u.Email = "taken@gmail.com"
+	_, err = c.Update(context.TODO(), u)
+	assert.Equal(t, domain.ErrConflict, err)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestUsersIterator(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Fetch", mock.Anything, "", int64(2)).Return([]domain.User{{ID: 1}, {ID: 2}}, "c1", nil)
+	mockUCase.On("Fetch", mock.Anything, "c1", int64(2)).Return([]domain.User{{ID: 3}, {ID: 4}}, "c2", nil)
+	mockUCase.On("Fetch", mock.Anything, "c2", int64(2)).Return([]domain.User{}, "", nil)
+	c := client.NewClient(newServer(t, mockUCase).URL)
+
+	var ids []int64
+	it := c.Users(context.TODO(), 2)
+	for it.Next() {
+		ids = append(ids, it.User().ID)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []int64{1, 2, 3, 4}, ids)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestUsersIteratorError(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Fetch", mock.Anything, "", int64(2)).Return([]domain.User{{ID: 1}}, "c1", nil)
+	mockUCase.On("Fetch", mock.Anything, "c1", int64(2)).Return(nil, "", domain.ErrBadParamInput)
+	c := client.NewClient(newServer(t, mockUCase).URL)
+
+	it := c.Users(context.TODO(), 2)
+	assert.True(t, it.Next())
+	assert.False(t, it.Next())
+	assert.Equal(t, domain.ErrBadParamInput, it.Err())
+}
+
+func TestRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1,"username":"dias"}`))
+	}))
+	defer srv.Close()
+
+	c := client.NewClient(srv.URL, client.WithAuth(client.BearerToken("secret")), fastRetry)
+	u, err := c.Get(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "dias", u.Username)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestNoRetryOfPOST(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"message":"upstream unavailable"}`))
+	}))
+	defer srv.Close()
+
+	c := client.NewClient(srv.URL, fastRetry)
+	_, err := c.Create(context.TODO(), domain.User{Username: "new"})
+	assert.Equal(t, &client.Error{StatusCode: http.StatusServiceUnavailable, Message: "upstream unavailable"}, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// GET gives up after MaxAttempts
+	_, err = c.Get(context.TODO(), 1)
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
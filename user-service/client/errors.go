@@ -0,0 +1,50 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// domainErrors are the errors the server reports by message in a ResponseError
+var domainErrors = []error{
+	domain.ErrInternalServerError,
+	domain.ErrNotFound,
+	domain.ErrConflict,
+	domain.ErrBadParamInput,
+	domain.ErrUnauthorized,
+	domain.ErrInvalidCredentials,
+	domain.ErrInvalidToken,
+	domain.ErrEmailNotVerified,
+	domain.ErrWeakPassword,
+	domain.ErrPasswordMismatch,
+	domain.ErrPasswordReused,
+	domain.ErrTooManyAttempts,
+	domain.ErrTooManyRequests,
+	domain.ErrForbidden,
+}
+
+// decodeError will turn an error response into the domain error of its message, or an *Error.
+// The body is a ResponseError, or a bare JSON string for bind errors and malformed ids.
+func decodeError(res *http.Response) error {
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+
+	var message string
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(b, &body); err == nil && body.Message != "" {
+		message = body.Message
+	} else if err := json.Unmarshal(b, &message); err != nil {
+		message = http.StatusText(res.StatusCode)
+	}
+
+	for _, err := range domainErrors {
+		if err.Error() == message {
+			return err
+		}
+	}
+	return &Error{StatusCode: res.StatusCode, Message: message}
+}
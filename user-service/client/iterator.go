@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// UserIterator walks all the users page by page, following X-Cursor. Use it like a bufio.Scanner:
+//
+//	it := c.Users(ctx, 100)
+//	for it.Next() {
+//		u := it.User()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type UserIterator struct {
+	ctx    context.Context
+	client *Client
+	num    int64
+	cursor string
+	page   []domain.User
+	i      int
+	done   bool
+	err    error
+}
+
+// Users will return an iterator over all the users, fetching num users per request
+func (c *Client) Users(ctx context.Context, num int64) *UserIterator {
+	return &UserIterator{ctx: ctx, client: c, num: num, i: -1}
+}
+
+// Next will advance to the next user, fetching the next page when needed.
+// It returns false at the end of the users or on error, see Err.
+func (it *UserIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.i++
+	for it.i >= len(it.page) {
+		if it.done {
+			return false
+		}
+		it.page, it.cursor, it.err = it.client.List(it.ctx, ListOptions{Num: it.num, Cursor: it.cursor})
+		if it.err != nil {
+			return false
+		}
+		it.i = 0
+		it.done = it.cursor == ""
+	}
+	return true
+}
+
+// User returns the current user
+func (it *UserIterator) User() domain.User {
+	return it.page[it.i]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *UserIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,141 @@
+// Command blogoctl administers the users of the user service. It talks to the usecase layer
+// directly, with the database and settings of the server, and acts as the admin given with -as.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/diantanjung/blogo/user-service/config"
+	"github.com/diantanjung/blogo/user-service/domain"
+	_jobRepo "github.com/diantanjung/blogo/user-service/job/repository/psql"
+	_jobUcase "github.com/diantanjung/blogo/user-service/job/usecase"
+	"github.com/diantanjung/blogo/user-service/logging"
+	_userRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+	_userUcase "github.com/diantanjung/blogo/user-service/user/usecase"
+)
+
+const usage = `Usage: blogoctl [-env file] [-o table|json] -as <admin id> <command> [args]
+
+Commands:
+  users list [-num n] [-all]                        list the users
+  users get <id>                                    show a user
+  users create -username u -name n -email e -password p [-roles r1,r2]
+  users update <id> [-username u] [-name n] [-email e]
+  users delete <id>                                 delete a user, it can be restored
+  users restore <id>                                restore a deleted user
+  users roles <id> <r1,r2>                          set the roles of a user
+  users reset-link <id>                             create a password reset link
+  users import [-format csv|json] [-dry-run] <file|-> create the users of a file
+  users export [-format csv|json] [file]            write every user to a file
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("blogoctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	envFile := fs.String("env", ".env", "file to read the settings from")
+	output := fs.String("o", "table", "output format, table or json")
+	as := fs.Int64("as", 0, "id of the admin the commands run as, it is recorded in the audit log")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 2 || fs.Arg(0) != "users" || *as <= 0 {
+		fs.Usage()
+		return 2
+	}
+	p, err := newPrinter(stdout, *output)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	if err = config.Load(*envFile); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if err = logging.Configure(config.String("LOG_LEVEL", "warn"), os.Getenv("LOG_FORMAT")); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	db, err := sql.Open("postgres", config.PostgresDSN())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx, err := operatorContext(context.Background(), _userRepo.NewPsqlUserRepository(db), _userRepo.NewPsqlRoleRepository(db), *as)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	users, auth := newUsecases(db)
+	cmd := &command{
+		users: users,
+		auth:  auth,
+		in:    stdin,
+		out:   p,
+	}
+	err = cmd.run(ctx, fs.Arg(1), fs.Args()[2:])
+	if err == errUsage {
+		fs.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// operatorContext returns the context the commands run in, as the admin of id, so the audit entries
+// of the run name the operator. The request id tags the audit entries of the run.
+func operatorContext(ctx context.Context, userRepo domain.UserRepository, roleRepo domain.RoleRepository, id int64) (context.Context, error) {
+	u, err := userRepo.GetByID(ctx, id)
+	if err == domain.ErrNotFound {
+		return nil, fmt.Errorf("-as: user %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	actor := domain.Actor{UserID: u.ID}
+	if actor.Roles, err = roleRepo.GetByUserID(ctx, u.ID); err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() {
+		return nil, fmt.Errorf("-as: user %d is not an admin", id)
+	}
+
+	ctx = domain.NewContextWithActor(ctx, actor)
+	return logging.WithRequestID(ctx, "blogoctl-"+logging.NewRequestID()), nil
+}
+
+// newUsecases will wire the usecases the way the server does, without the decorators
+func newUsecases(db *sql.DB) (domain.UserUsecase, domain.AuthUsecase) {
+	timeout := config.Duration("CONTEXT_TIMEOUT", 5*time.Second)
+	repo := _userRepo.NewPsqlUserRepository(db)
+	roleRepo := _userRepo.NewPsqlRoleRepository(db)
+	sessionRepo := _userRepo.NewPsqlSessionRepository(db)
+	historyRepo := _userRepo.NewPsqlPasswordHistoryRepository(db)
+	txManager := _userRepo.NewPsqlTxManager(db, sql.LevelSerializable, int(config.Int("TX_RETRIES", 3)))
+	// emails are enqueued, the job worker of the server delivers them so no mailer is needed
+	jobs := _jobUcase.NewJobUsecase(_jobRepo.NewPsqlJobRepository(db), timeout)
+
+	us := _userUcase.NewUserUsecase(repo, roleRepo, _userRepo.NewPsqlProfileRepository(db), sessionRepo, historyRepo, _userRepo.NewPsqlAuditRepository(db), _userRepo.NewPsqlOutboxRepository(db), txManager, config.UserConfig(jobs, nil, config.BlobStore()), timeout)
+	au := _userUcase.NewAuthUsecase(repo, roleRepo, sessionRepo, _userRepo.NewPsqlPasswordResetRepository(db), historyRepo, _userRepo.NewPsqlLoginAttemptStore(db), txManager, config.AuthConfig(jobs, nil), timeout)
+	return us, au
+}
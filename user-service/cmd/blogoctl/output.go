@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// printer writes the results of the commands as a table or as json
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case "table":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{w: w, json: true}, nil
+	}
+	return nil, fmt.Errorf("invalid output %q, it must be table or json", format)
+}
+
+func (p *printer) encode(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p *printer) users(list []domain.User) error {
+	if p.json {
+		if list == nil {
+			list = []domain.User{}
+		}
+		return p.encode(list)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSERNAME\tNAME\tEMAIL\tROLES\tVERIFIED\tCREATED")
+	for _, u := range list {
+		verified := "no"
+		if u.EmailVerifiedAt != nil {
+			verified = "yes"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", u.ID, u.Username, u.Name, u.Email, joinRoles(u.Roles), verified, u.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+// value will print a single result, as an object with the given key in json
+func (p *printer) value(key, v string) error {
+	if p.json {
+		return p.encode(map[string]string{key: v})
+	}
+	_, err := fmt.Fprintln(p.w, v)
+	return err
+}
+
+func (p *printer) importReport(r domain.ImportReport) error {
+	if p.json {
+		return p.encode(r)
+	}
+
+	verb := "imported"
+	if r.DryRun {
+		verb = "would be imported"
+	}
+	fmt.Fprintf(p.w, "%d of %d %s, %d failed\n", r.Imported, r.Total, verb, len(r.Failed))
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROW\tUSERNAME\tERROR")
+	for _, f := range r.Failed {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", f.Row, f.Username, f.Error)
+	}
+	return tw.Flush()
+}
+
+// note will print a hint about the result in table output, json output stays parseable
+func (p *printer) note(s string) {
+	if !p.json {
+		fmt.Fprintln(p.w, s)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// csvHeader lists the columns of an export. Import reads the columns by their header and
+// also accepts a password column, the other columns are ignored.
+var csvHeader = []string{"id", "username", "name", "email", "roles", "email_verified_at", "created_at", "updated_at"}
+
+// decodeUsers will read the users of a csv file with a header row, or of a json array
+func decodeUsers(r io.Reader, format string) ([]domain.User, error) {
+	switch format {
+	case "json":
+		var list []domain.User
+		if err := json.NewDecoder(r).Decode(&list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	case "csv":
+		return decodeCSV(r)
+	}
+	return nil, fmt.Errorf("invalid format %q", format)
+}
+
+func decodeCSV(r io.Reader) ([]domain.User, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	field := func(record []string, name string) string {
+		if i, ok := col[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+
+	var list []domain.User
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, domain.User{
+			Username: field(record, "username"),
+			Name:     field(record, "name"),
+			Email:    field(record, "email"),
+			Password: field(record, "password"),
+			Roles:    parseRoles(field(record, "roles")),
+		})
+	}
+}
+
+// encodeUsers will write the users as csv with a header row, or as a json array
+func encodeUsers(w io.Writer, format string, list []domain.User) error {
+	switch format {
+	case "json":
+		if list == nil {
+			list = []domain.User{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, u := range list {
+			verifiedAt := ""
+			if u.EmailVerifiedAt != nil {
+				verifiedAt = u.EmailVerifiedAt.Format(time.RFC3339)
+			}
+			err := cw.Write([]string{
+				strconv.FormatInt(u.ID, 10),
+				u.Username,
+				u.Name,
+				u.Email,
+				joinRoles(u.Roles),
+				verifiedAt,
+				u.CreatedAt.Format(time.RFC3339),
+				u.UpdatedAt.Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("invalid format %q", format)
+}
+
+func joinRoles(roles []domain.Role) string {
+	s := make([]string, len(roles))
+	for i, r := range roles {
+		s[i] = string(r)
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// errUsage is returned when the arguments of a command are wrong
+var errUsage = errors.New("usage")
+
+type command struct {
+	users domain.UserUsecase
+	auth  domain.AuthUsecase
+	in    io.Reader
+	out   *printer
+}
+
+func (c *command) run(ctx context.Context, name string, args []string) error {
+	switch name {
+	case "list":
+		return c.list(ctx, args)
+	case "get":
+		return c.get(ctx, args)
+	case "create":
+		return c.create(ctx, args)
+	case "update":
+		return c.update(ctx, args)
+	case "delete":
+		return c.delete(ctx, args)
+	case "restore":
+		return c.restore(ctx, args)
+	case "roles":
+		return c.roles(ctx, args)
+	case "reset-link":
+		return c.resetLink(ctx, args)
+	case "import":
+		return c.importUsers(ctx, args)
+	case "export":
+		return c.exportUsers(ctx, args)
+	}
+	return errUsage
+}
+
+// newFlagSet returns the flag set of a command, whose errors are reported as errUsage
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs
+}
+
+// parseID will read the user id, the first argument of args
+func parseID(args []string) (int64, error) {
+	if len(args) == 0 {
+		return 0, errUsage
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, errUsage
+	}
+	return id, nil
+}
+
+// parseRoles will read a comma separated list of roles
+func parseRoles(s string) []domain.Role {
+	roles := []domain.Role{}
+	for _, r := range strings.Split(s, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, domain.Role(r))
+		}
+	}
+	return roles
+}
+
+func (c *command) list(ctx context.Context, args []string) error {
+	fs := newFlagSet("list")
+	num := fs.Int64("num", 10, "number of users")
+	all := fs.Bool("all", false, "list every user")
+	cursor := fs.String("cursor", "", "cursor of the page")
+	if fs.Parse(args) != nil {
+		return errUsage
+	}
+
+	var list []domain.User
+	next := *cursor
+	for {
+		page, nextCursor, err := c.users.Fetch(ctx, next, *num)
+		if err != nil {
+			return err
+		}
+		list = append(list, page...)
+		next = nextCursor
+		if !*all || next == "" {
+			break
+		}
+	}
+	if !*all && next != "" {
+		c.out.note("next cursor: " + next)
+	}
+	return c.out.users(list)
+}
+
+func (c *command) get(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	u, err := c.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.out.users([]domain.User{u})
+}
+
+func (c *command) create(ctx context.Context, args []string) error {
+	fs := newFlagSet("create")
+	var u domain.User
+	fs.StringVar(&u.Username, "username", "", "username")
+	fs.StringVar(&u.Name, "name", "", "name")
+	fs.StringVar(&u.Email, "email", "", "email")
+	fs.StringVar(&u.Password, "password", "", "password")
+	roles := fs.String("roles", "", "comma separated roles")
+	if fs.Parse(args) != nil {
+		return errUsage
+	}
+	u.Roles = parseRoles(*roles)
+
+	if err := c.users.Store(ctx, &u); err != nil {
+		return err
+	}
+	return c.out.users([]domain.User{u})
+}
+
+func (c *command) update(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	u, err := c.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("update")
+	fs.StringVar(&u.Username, "username", u.Username, "username")
+	fs.StringVar(&u.Name, "name", u.Name, "name")
+	fs.StringVar(&u.Email, "email", u.Email, "email")
+	if fs.Parse(args[1:]) != nil {
+		return errUsage
+	}
+
+	if err = c.users.Update(ctx, &u); err != nil {
+		return err
+	}
+	return c.out.users([]domain.User{u})
+}
+
+func (c *command) delete(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	return c.users.Delete(ctx, id)
+}
+
+func (c *command) restore(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	u, err := c.users.Restore(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.out.users([]domain.User{u})
+}
+
+func (c *command) roles(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil || len(args) != 2 {
+		return errUsage
+	}
+	u, err := c.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.Roles = parseRoles(args[1])
+
+	if err = c.users.Update(ctx, &u); err != nil {
+		return err
+	}
+	return c.out.users([]domain.User{u})
+}
+
+func (c *command) resetLink(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	link, err := c.auth.ResetLink(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.out.value("url", link)
+}
+
+func (c *command) importUsers(ctx context.Context, args []string) error {
+	fs := newFlagSet("import")
+	format := fs.String("format", "csv", "csv or json")
+	dryRun := fs.Bool("dry-run", false, "only check the users")
+	if fs.Parse(args) != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+
+	in := c.in
+	if name := fs.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	list, err := decodeUsers(in, *format)
+	if err != nil {
+		return err
+	}
+
+	report, err := c.users.Import(ctx, &sliceReader{list: list}, *dryRun)
+	if err != nil {
+		return err
+	}
+	return c.out.importReport(report)
+}
+
+// sliceReader is a domain.UserReader over decoded users
+type sliceReader struct {
+	list []domain.User
+}
+
+func (r *sliceReader) Read() (domain.User, error) {
+	if len(r.list) == 0 {
+		return domain.User{}, io.EOF
+	}
+	u := r.list[0]
+	r.list = r.list[1:]
+	return u, nil
+}
+
+func (c *command) exportUsers(ctx context.Context, args []string) (err error) {
+	fs := newFlagSet("export")
+	format := fs.String("format", "csv", "csv or json")
+	if fs.Parse(args) != nil || fs.NArg() > 1 || (*format != "csv" && *format != "json") {
+		return errUsage
+	}
+
+	var list []domain.User
+	err = c.users.Export(ctx, func(u domain.User) error {
+		list = append(list, u)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	out := c.out.w
+	if fs.NArg() == 1 {
+		f, errCreate := os.Create(fs.Arg(0))
+		if errCreate != nil {
+			return errCreate
+		}
+		defer func() {
+			if errClose := f.Close(); err == nil {
+				err = errClose
+			}
+		}()
+		out = f
+	}
+	return encodeUsers(out, *format, list)
+}
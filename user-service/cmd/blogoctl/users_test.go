@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+)
+
+func newTestCommand(format string, in string) (*command, *mocks.UserUsecase, *mocks.AuthUsecase, *bytes.Buffer) {
+	var out bytes.Buffer
+	p, _ := newPrinter(&out, format)
+	users := new(mocks.UserUsecase)
+	auth := new(mocks.AuthUsecase)
+	return &command{users: users, auth: auth, in: strings.NewReader(in), out: p}, users, auth, &out
+}
+
+func TestListAll(t *testing.T) {
+	cmd, users, _, out := newTestCommand("json", "")
+	users.On("Fetch", mock.Anything, "", int64(2)).Return([]domain.User{{ID: 1}, {ID: 2}}, "next", nil)
+	users.On("Fetch", mock.Anything, "next", int64(2)).Return([]domain.User{{ID: 3}}, "", nil)
+
+	err := cmd.run(context.TODO(), "list", []string{"-num", "2", "-all"})
+	require.NoError(t, err)
+
+	var list []domain.User
+	require.NoError(t, json.Unmarshal(out.Bytes(), &list))
+	assert.Len(t, list, 3)
+	users.AssertExpectations(t)
+}
+
+func TestGetTable(t *testing.T) {
+	cmd, users, _, out := newTestCommand("table", "")
+	users.On("GetByID", mock.Anything, int64(7)).Return(domain.User{
+		ID:       7,
+		Username: "jdoe",
+		Name:     "John",
+		Email:    "jdoe@example.com",
+		Roles:    []domain.Role{domain.RoleReader, domain.RoleAuthor},
+	}, nil)
+
+	err := cmd.run(context.TODO(), "get", []string{"7"})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"ID", "USERNAME", "NAME", "EMAIL", "ROLES", "VERIFIED", "CREATED"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"7", "jdoe", "John", "jdoe@example.com", "reader,author", "no"}, strings.Fields(lines[1])[:6])
+}
+
+func TestRoles(t *testing.T) {
+	cmd, users, _, _ := newTestCommand("table", "")
+	users.On("GetByID", mock.Anything, int64(7)).Return(domain.User{ID: 7, Username: "jdoe", Roles: []domain.Role{domain.RoleReader}}, nil)
+	users.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.ID == 7 && u.Username == "jdoe" && len(u.Roles) == 2 && u.Roles[1] == domain.RoleAuthor
+	})).Return(nil)
+
+	err := cmd.run(context.TODO(), "roles", []string{"7", "reader, author"})
+	require.NoError(t, err)
+	users.AssertExpectations(t)
+
+	err = cmd.run(context.TODO(), "roles", []string{"7"})
+	assert.Equal(t, errUsage, err)
+}
+
+func TestResetLink(t *testing.T) {
+	cmd, _, auth, out := newTestCommand("json", "")
+	auth.On("ResetLink", mock.Anything, int64(7)).Return("https://blogo.test/reset?token=abc", nil)
+
+	err := cmd.run(context.TODO(), "reset-link", []string{"7"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"url":"https://blogo.test/reset?token=abc"}`, out.String())
+}
+
+func TestImportCSV(t *testing.T) {
+	csv := "username,name,email,password,roles\n" +
+		"jdoe,John,jdoe@example.com,ASDF1234,\"reader,author\"\n" +
+		"jdoe,John,jdoe@example.com,ASDF1234,\n"
+	cmd, users, _, out := newTestCommand("json", csv)
+	var read []domain.User
+	report := domain.ImportReport{Total: 2, Imported: 1, Failed: []domain.ImportFailure{{Row: 2, Username: "jdoe", Error: domain.ErrConflict.Error()}}}
+	users.On("Import", mock.Anything, mock.Anything, false).Run(func(args mock.Arguments) {
+		r := args.Get(1).(domain.UserReader)
+		for u, err := r.Read(); err == nil; u, err = r.Read() {
+			read = append(read, u)
+		}
+	}).Return(report, nil)
+
+	err := cmd.run(context.TODO(), "import", []string{"-"})
+	require.NoError(t, err)
+
+	require.Len(t, read, 2)
+	assert.Equal(t, "jdoe@example.com", read[0].Email)
+	assert.Equal(t, "ASDF1234", read[0].Password)
+	assert.Equal(t, []domain.Role{domain.RoleReader, domain.RoleAuthor}, read[0].Roles)
+
+	var printed domain.ImportReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &printed))
+	assert.Equal(t, report, printed)
+}
+
+func TestImportJSON(t *testing.T) {
+	cmd, users, _, out := newTestCommand("table", `[{"username":"jdoe","name":"John","email":"jdoe@example.com","password":"ASDF1234"}]`)
+	users.On("Import", mock.Anything, mock.Anything, true).Return(domain.ImportReport{DryRun: true, Total: 1, Imported: 1}, nil)
+
+	err := cmd.run(context.TODO(), "import", []string{"-format", "json", "-dry-run", "-"})
+	require.NoError(t, err)
+	assert.Equal(t, "1 of 1 would be imported, 0 failed\n", out.String())
+	users.AssertExpectations(t)
+}
+
+func TestExportCSV(t *testing.T) {
+	cmd, users, _, out := newTestCommand("table", "")
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	users.On("Export", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(func(domain.User) error)(domain.User{ID: 1, Username: "jdoe", Name: "Doe, John", Email: "jdoe@example.com",
+			Roles: []domain.Role{domain.RoleReader}, CreatedAt: created, UpdatedAt: created})
+	}).Return(nil)
+
+	err := cmd.run(context.TODO(), "export", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "id,username,name,email,roles,email_verified_at,created_at,updated_at\n"+
+		"1,jdoe,\"Doe, John\",jdoe@example.com,reader,,2020-01-02T03:04:05Z,2020-01-02T03:04:05Z\n", out.String())
+
+	// an export can be read back by import
+	list, err := decodeUsers(strings.NewReader(out.String()), "csv")
+	require.NoError(t, err)
+	assert.Equal(t, "Doe, John", list[0].Name)
+}
+
+func TestUsage(t *testing.T) {
+	cmd, _, _, _ := newTestCommand("table", "")
+
+	assert.Equal(t, errUsage, cmd.run(context.TODO(), "frobnicate", nil))
+	assert.Equal(t, errUsage, cmd.run(context.TODO(), "get", []string{"abc"}))
+	assert.Equal(t, errUsage, cmd.run(context.TODO(), "export", []string{"-format", "xml"}))
+	_, err := newPrinter(nil, "yaml")
+	assert.Error(t, err)
+}
+
+func TestOperatorContext(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByID", mock.Anything, int64(1)).Return(domain.User{ID: 1}, nil)
+	mockUserRepo.On("GetByID", mock.Anything, int64(7)).Return(domain.User{ID: 7}, nil)
+	mockUserRepo.On("GetByID", mock.Anything, int64(9)).Return(domain.User{}, domain.ErrNotFound)
+	mockRoleRepo := new(mocks.RoleRepository)
+	mockRoleRepo.On("GetByUserID", mock.Anything, int64(1)).Return([]domain.Role{domain.RoleAdmin}, nil)
+	mockRoleRepo.On("GetByUserID", mock.Anything, int64(7)).Return([]domain.Role{domain.RoleReader}, nil)
+
+	ctx, err := operatorContext(context.TODO(), mockUserRepo, mockRoleRepo, 1)
+	require.NoError(t, err)
+	actor, ok := domain.ActorFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), actor.UserID, "the audit entries name the operator")
+	assert.True(t, actor.IsAdmin())
+
+	_, err = operatorContext(context.TODO(), mockUserRepo, mockRoleRepo, 7)
+	assert.EqualError(t, err, "-as: user 7 is not an admin")
+	_, err = operatorContext(context.TODO(), mockUserRepo, mockRoleRepo, 9)
+	assert.EqualError(t, err, "-as: user 9 not found")
+}
@@ -0,0 +1,224 @@
+// Package config loads the settings of the user service and blogoctl from the environment
+// and an optional .env file, so both read the same variables the same way
+package config
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/diantanjung/blogo/user-service/blob"
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/mailer"
+	"github.com/diantanjung/blogo/user-service/user/usecase"
+)
+
+// Load will read the variables of the given .env files, or of .env when none is given,
+// without overriding the variables already set in the environment
+func Load(files ...string) error {
+	return godotenv.Load(files...)
+}
+
+// PostgresDSN returns the connection string of the DB_* variables
+func PostgresDSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s "+
+		"password=%s dbname=%s sslmode=disable",
+		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"))
+}
+
+// String will read a string from the environment, or return def when it is unset
+func String(key string, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
+
+// List will read a comma separated list from the environment, or return def when it is unset
+func List(key string, def []string) []string {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Bool will read a boolean from the environment, or return def when it is unset
+func Bool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return b
+}
+
+// Int will read an integer from the environment, or return def when it is unset
+func Int(key string, def int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return i
+}
+
+// Duration will read a duration such as "5s" from the environment, or return def when it is unset
+func Duration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return d
+}
+
+// Mailer will create the domain.Mailer selected by MAILER, smtp or log
+func Mailer() domain.Mailer {
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		return mailer.NewSMTPMailer(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("MAIL_FROM"))
+	case "", "log":
+		file := os.Getenv("MAIL_FILE")
+		if file == "" {
+			return mailer.NewLogMailer(os.Stdout)
+		}
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return mailer.NewLogMailer(f)
+	default:
+		log.Fatalf("invalid MAILER %q", os.Getenv("MAILER"))
+		return nil
+	}
+}
+
+// VerificationPolicy will read EMAIL_VERIFICATION_REQUIRED, optional when it is unset
+func VerificationPolicy() domain.VerificationPolicy {
+	p := domain.VerificationPolicy(os.Getenv("EMAIL_VERIFICATION_REQUIRED"))
+	switch p {
+	case domain.VerificationOptional, domain.VerificationForAuthoring, domain.VerificationForLogin:
+	default:
+		log.Fatalf("invalid EMAIL_VERIFICATION_REQUIRED %q", p)
+	}
+	return p
+}
+
+// minSecretLength is the shortest API_SECRET accepted, HS256 keys should be as long as the hash
+const minSecretLength = 32
+
+// Secret will read API_SECRET, the key signing the access and email verification tokens.
+// The process exits when it is missing or too short to resist guessing.
+func Secret() []byte {
+	secret := os.Getenv("API_SECRET")
+	if len(secret) < minSecretLength {
+		log.Fatalf("API_SECRET must be at least %d bytes, it is %d", minSecretLength, len(secret))
+	}
+	return []byte(secret)
+}
+
+// AuthConfig will read the usecase.AuthConfig, reset emails are enqueued with jobs and sent by the job worker with mail
+func AuthConfig(jobs domain.JobUsecase, mail domain.Mailer) usecase.AuthConfig {
+	return usecase.AuthConfig{
+		Secret:       Secret(),
+		TokenTTL:     Duration("TOKEN_TTL", 24*time.Hour),
+		Verification: VerificationPolicy(),
+		Jobs:         jobs,
+		ResetMailer:  mail,
+		ResetTTL:     Duration("RESET_TTL", time.Hour),
+		ResetURL:     os.Getenv("RESET_URL"),
+		Lockout: usecase.LockoutPolicy{
+			AccountThreshold: Int("LOCKOUT_ACCOUNT_THRESHOLD", 5),
+			IPThreshold:      Int("LOCKOUT_IP_THRESHOLD", 20),
+			Backoff:          Duration("LOCKOUT_BACKOFF", time.Second),
+			Lockout:          Duration("LOCKOUT_DURATION", 15*time.Minute),
+			MaxLockout:       Duration("LOCKOUT_MAX_DURATION", 24*time.Hour),
+			Window:           Duration("LOCKOUT_WINDOW", 24*time.Hour),
+		},
+	}
+}
+
+// BlobStore will create the domain.BlobStore selected by BLOB_STORE, local or s3
+func BlobStore() domain.BlobStore {
+	switch os.Getenv("BLOB_STORE") {
+	case "", "local":
+		return blob.NewLocalStore(String("BLOB_DIR", "data/blobs"), String("BLOB_BASE_URL", "http://localhost:9090/blobs"))
+	case "s3":
+		s, err := blob.NewS3Store(blob.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    String("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			BaseURL:   os.Getenv("S3_BASE_URL"),
+		}, &http.Client{Timeout: Duration("S3_TIMEOUT", 30*time.Second)})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return s
+	default:
+		log.Fatalf("invalid BLOB_STORE %q", os.Getenv("BLOB_STORE"))
+		return nil
+	}
+}
+
+// UserConfig will read the usecase.UserConfig, verification emails are enqueued with jobs and sent by the job worker
+// with mail, avatars are stored in blobs
+func UserConfig(jobs domain.JobUsecase, mail domain.Mailer, blobs domain.BlobStore) usecase.UserConfig {
+	return usecase.UserConfig{
+		Verification: usecase.EmailVerification{
+			Jobs:   jobs,
+			Mailer: mail,
+			Secret: Secret(),
+			TTL:    Duration("VERIFY_TTL", 48*time.Hour),
+			URL:    os.Getenv("VERIFY_URL"),
+		},
+		PasswordHistory: Int("PASSWORD_HISTORY", 5),
+		ImportBatch:     Int("IMPORT_BATCH", 500),
+		Avatar: usecase.AvatarConfig{
+			Store:     blobs,
+			Sizes:     avatarSizes(),
+			MaxBytes:  Int("AVATAR_MAX_BYTES", 5<<20),
+			MaxPixels: Int("AVATAR_MAX_PIXELS", 40_000_000),
+			Quality:   int(Int("AVATAR_QUALITY", 85)),
+		},
+	}
+}
+
+// avatarSizes will read AVATAR_SIZES, a comma separated list of thumbnail sides in pixels
+func avatarSizes() []int {
+	var sizes []int
+	for _, v := range List("AVATAR_SIZES", []string{"64", "256", "512"}) {
+		size, err := strconv.Atoi(v)
+		if err != nil || size <= 0 || size > 4096 {
+			log.Fatalf("invalid AVATAR_SIZES %q", v)
+		}
+		sizes = append(sizes, size)
+	}
+	if len(sizes) == 0 {
+		log.Fatal("AVATAR_SIZES is empty")
+	}
+	return sizes
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Article is a published post of the article service, the user service only reads it to resolve GraphQL queries
+type Article struct {
+	ID          int64     `json:"id"`
+	AuthorID    int64     `json:"author_id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// ArticleRepository reads the published articles from the article service
+type ArticleRepository interface {
+	// GetByIDs returns the articles with the given ids, the missing ones are left out
+	GetByIDs(ctx context.Context, ids []int64) ([]Article, error)
+	// FetchByAuthorIDs returns the num latest articles of every author, newest first
+	FetchByAuthorIDs(ctx context.Context, authorIDs []int64, num int64) ([]Article, error)
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Audit actions recorded on user accounts
+const (
+	AuditUserCreated  = "user.created"
+	AuditUserUpdated  = "user.updated"
+	AuditUserDeleted  = "user.deleted"
+	AuditUserRestored = "user.restored"
+	// AuditProfileUpdated records the changes to the profile of the user
+	AuditProfileUpdated = "user.profile_updated"
+)
+
+// AuditChange is the value of a field before and after a change, sensitive values are redacted
+type AuditChange struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// AuditEntry records who changed what on a user account
+type AuditEntry struct {
+	ID int64 `json:"id"`
+	// ActorID is 0 when the change was anonymous, e.g. a signup
+	ActorID      int64                  `json:"actor_id,omitempty"`
+	Action       string                 `json:"action"`
+	TargetUserID int64                  `json:"target_user_id"`
+	Diff         map[string]AuditChange `json:"diff"`
+	IP           string                 `json:"ip,omitempty"`
+	RequestID    string                 `json:"request_id,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// AuditRepository persists the audit log, entries are never updated or deleted
+type AuditRepository interface {
+	Store(ctx context.Context, e *AuditEntry) error
+	FetchByTargetUserID(ctx context.Context, userID int64, cursor string, num int64) ([]AuditEntry, string, error)
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Actor is the authenticated user performing a request
+type Actor struct {
+	UserID    int64
+	SessionID string
+	Roles     []Role
+}
+
+// HasRole reports whether the actor was granted r
+func (a Actor) HasRole(r Role) bool {
+	for _, role := range a.Roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin reports whether the actor is an administrator
+func (a Actor) IsAdmin() bool {
+	return a.HasRole(RoleAdmin)
+}
+
+type actorKey struct{}
+
+// NewContextWithActor will return a copy of ctx carrying the authenticated actor
+func NewContextWithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFromContext will return the authenticated actor stored in ctx, if any
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	a, ok := ctx.Value(actorKey{}).(Actor)
+	return a, ok
+}
+
+// RequireAdmin will return ErrUnauthorized when there is no actor in ctx and ErrForbidden when the
+// actor is not an admin, it guards the resources spanning every user such as webhooks and jobs
+func RequireAdmin(ctx context.Context) error {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	if !actor.IsAdmin() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+type clientIPKey struct{}
+
+// NewContextWithClientIP will return a copy of ctx carrying the IP of the client performing the request
+func NewContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext will return the client IP stored in ctx, if any
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// Session is a login of a user, referenced by the access token
+type Session struct {
+	ID        string     `json:"id"`
+	UserID    int64      `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Credentials is the login request body
+type Credentials struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Token is the access token issued on login
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type AuthUsecase interface {
+	Login(ctx context.Context, cred Credentials) (Token, error)
+	Logout(ctx context.Context) error
+	Authenticate(ctx context.Context, token string) (Actor, error)
+	ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req ResetPasswordRequest) error
+	Unlock(ctx context.Context, userID int64) error
+	// ResetLink creates a password reset link for the user without emailing it, it is restricted to admins
+	ResetLink(ctx context.Context, userID int64) (string, error)
+}
+
+type SessionRepository interface {
+	Store(ctx context.Context, s *Session) error
+	GetByID(ctx context.Context, id string) (Session, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeByUserID(ctx context.Context, userID int64, exceptID string) error
+}
@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore stores the files uploaded by users, they are served publicly from the URL it returns
+type BlobStore interface {
+	// Put will store the content read from r under key, replacing the blob stored there, and return its URL
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error)
+}
@@ -11,4 +11,28 @@ var (
 	ErrConflict = errors.New("Your Item already exist")
 	// ErrBadParamInput will throw if the given request-body or params is not valid
 	ErrBadParamInput = errors.New("Given Param is not valid")
+	// ErrUnauthorized will throw if the request is not authenticated
+	ErrUnauthorized = errors.New("You are not authenticated")
+	// ErrInvalidCredentials will throw if the given email or password is wrong
+	ErrInvalidCredentials = errors.New("Email or password is incorrect")
+	// ErrInvalidToken will throw if the given verification or reset token is not valid or expired
+	ErrInvalidToken = errors.New("Given token is invalid or expired")
+	// ErrEmailNotVerified will throw if the action requires a verified email
+	ErrEmailNotVerified = errors.New("Your email is not verified")
+	// ErrWeakPassword will throw if the given password does not follow the strength policy
+	ErrWeakPassword = errors.New("Password must be at least 8 characters and contain a letter and a digit")
+	// ErrPasswordMismatch will throw if the given current password is wrong
+	ErrPasswordMismatch = errors.New("Current password is incorrect")
+	// ErrPasswordReused will throw if the new password was used recently
+	ErrPasswordReused = errors.New("Password was used recently, choose another one")
+	// ErrTooManyAttempts will throw if the login is temporarily locked after repeated failures
+	ErrTooManyAttempts = errors.New("Too many failed login attempts, try again later")
+	// ErrTooManyRequests will throw if the client exceeded its rate limit
+	ErrTooManyRequests = errors.New("Too many requests, slow down")
+	// ErrForbidden will throw if the authenticated user is not allowed to perform the action
+	ErrForbidden = errors.New("You are not allowed to perform this action")
+	// ErrTooLarge will throw if the given file exceeds the size limit
+	ErrTooLarge = errors.New("Given file is too large")
+	// ErrUnsupportedMediaType will throw if the given file is not of an accepted type
+	ErrUnsupportedMediaType = errors.New("Given file type is not supported")
 )
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Event types emitted on user changes
+const (
+	EventUserCreated  = "UserCreated"
+	EventUserUpdated  = "UserUpdated"
+	EventUserDeleted  = "UserDeleted"
+	EventUserRestored = "UserRestored"
+)
+
+// Event is a domain event. Events are delivered at least once, consumers dedup them on ID.
+type Event struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID int64           `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	// Attempts is the number of times the outbox relay claimed the event, it is not published
+	Attempts int64 `json:"-"`
+}
+
+// UserEvent is the payload of the user events, the state of the user after the change,
+// or before it for UserDeleted
+type UserEvent struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Email    string `json:"email,omitempty"`
+	// Changed lists the fields changed by a UserUpdated event
+	Changed []string `json:"changed,omitempty"`
+}
+
+// EventPublisher delivers events to their consumers
+type EventPublisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// OutboxRepository keeps the events until they are published. Events are stored within the
+// transaction of the change that caused them, see TxManager.
+type OutboxRepository interface {
+	Store(ctx context.Context, events ...Event) error
+	// Claim will lock up to num unpublished events due for an attempt, oldest first, so other relays skip them for lease
+	Claim(ctx context.Context, num int64, lease time.Duration) ([]Event, error)
+	MarkPublished(ctx context.Context, id string, at time.Time) error
+	// MarkFailed will record why publishing failed, the event is claimed again from retryAt
+	MarkFailed(ctx context.Context, id string, reason string, retryAt time.Time) error
+	// MarkDead will record why publishing failed for the last time, the event is never claimed again
+	MarkDead(ctx context.Context, id string, reason string, at time.Time) error
+}
@@ -0,0 +1,24 @@
+package domain
+
+// UserReader streams the users of a bulk import. Read returns io.EOF after the last user, and an
+// error wrapping ErrBadParamInput for a row that cannot be parsed, after which reading goes on.
+type UserReader interface {
+	Read() (User, error)
+}
+
+// ImportFailure is a row of a bulk import that was not imported, rows are numbered from 1
+// without the header
+type ImportFailure struct {
+	Row      int    `json:"row"`
+	Username string `json:"username,omitempty"`
+	Error    string `json:"error"`
+}
+
+// ImportReport is the result of a bulk import. In a dry run, Imported is the number of users
+// that would have been imported.
+type ImportReport struct {
+	DryRun   bool            `json:"dry_run"`
+	Total    int             `json:"total"`
+	Imported int             `json:"imported"`
+	Failed   []ImportFailure `json:"failed"`
+}
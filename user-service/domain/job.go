@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Job states
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	// JobDead is the state of jobs that failed every attempt, they are only retried by hand
+	JobDead = "dead"
+)
+
+// Job types
+const (
+	// JobPurgeUsers hard deletes the users deleted for longer than the retention of its PurgeUsersJob payload
+	JobPurgeUsers = "users.purge"
+	// JobSendMail delivers the Message of its payload. Job payloads are kept and shown to admins,
+	// emails carrying a secret link are enqueued as the jobs below and written by the worker instead.
+	JobSendMail = "mail.send"
+	// JobSendPasswordReset emails a new password reset link to the user of its PasswordResetJob payload
+	JobSendPasswordReset = "password_reset.send"
+	// JobSendVerification emails a verification link to the user of its VerificationJob payload
+	JobSendVerification = "verification.send"
+	// JobPruneRateLimits deletes the rate limit buckets idle for longer than the IdleFor of its PruneRateLimitsJob payload
+	JobPruneRateLimits = "rate_limits.prune"
+)
+
+// PurgeUsersJob is the payload of the JobPurgeUsers jobs
+type PurgeUsersJob struct {
+	// Retention is how long deleted users can be restored, as a duration like "720h"
+	Retention string `json:"retention"`
+}
+
+// PruneRateLimitsJob is the payload of the JobPruneRateLimits jobs
+type PruneRateLimitsJob struct {
+	// IdleFor is how long a bucket is kept after its last request, as a duration like "1h"
+	IdleFor string `json:"idle_for"`
+}
+
+// PasswordResetJob is the payload of the JobSendPasswordReset jobs
+type PasswordResetJob struct {
+	UserID int64 `json:"user_id"`
+}
+
+// VerificationJob is the payload of the JobSendVerification jobs, the link is only sent while
+// Email is still the unverified email of the user
+type VerificationJob struct {
+	UserID int64  `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// Job is a unit of background work, run by the handler registered for its type
+type Job struct {
+	ID      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	// Key makes the job unique, a job is not enqueued when another one has the same key
+	Key         string    `json:"key,omitempty"`
+	Status      string    `json:"status"`
+	Attempts    int64     `json:"attempts"`
+	MaxAttempts int64     `json:"max_attempts"`
+	RunAt       time.Time `json:"run_at"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// LockedBy is the token of the claim running the job, empty when the job is not running
+	LockedBy string `json:"-"`
+}
+
+// NewJob will create a job of the given type with payload encoded as json
+func NewJob(jobType string, payload interface{}) (*Job, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Job{Type: jobType, Payload: b}, nil
+}
+
+// JobHandler runs a job. A returned error wrapping ErrBadParamInput is not retried, the job can never succeed.
+type JobHandler func(ctx context.Context, j Job) error
+
+// JobUsecase lets admins inspect the jobs, except Enqueue which is used by the features scheduling work
+type JobUsecase interface {
+	Fetch(ctx context.Context, status string, cursor string, num int64) ([]Job, string, error)
+	GetByID(ctx context.Context, id int64) (Job, error)
+	// Retry will schedule a dead job again
+	Retry(ctx context.Context, id int64) error
+	// Enqueue will schedule a job, at j.RunAt when it is set
+	Enqueue(ctx context.Context, j *Job) error
+}
+
+type JobRepository interface {
+	// Enqueue will create the job, unless it has a key already taken by another job
+	Enqueue(ctx context.Context, j *Job) error
+	GetByID(ctx context.Context, id int64) (Job, error)
+	Fetch(ctx context.Context, status string, cursor string, num int64) ([]Job, string, error)
+	// Claim will mark running up to num jobs of the type due to run, so other workers skip them for lease.
+	// Running jobs whose lease expired are claimed again, their worker is presumed dead.
+	Claim(ctx context.Context, jobType string, num int64, lease time.Duration) ([]Job, error)
+	// Update will save the outcome of a run and release the job.
+	// It returns ErrConflict when j no longer holds the claim, the lease expired and another worker claimed the job again.
+	Update(ctx context.Context, j *Job) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// LoginAttempt counts the consecutive failed logins of a key, an account or a client IP
+type LoginAttempt struct {
+	Key           string
+	Failures      int64
+	LastFailureAt time.Time
+}
+
+// LoginAttemptStore keeps the failed login counters, implementations shared by every replica
+// must update them atomically
+type LoginAttemptStore interface {
+	// Get returns the counter of key, or a zero LoginAttempt when there is none
+	Get(ctx context.Context, key string) (LoginAttempt, error)
+	// RegisterFailure increments the counter of key. Failures older than window are forgotten first.
+	RegisterFailure(ctx context.Context, key string, at time.Time, window time.Duration) (LoginAttempt, error)
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "context"
+
+// Message is an email sent by the service
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Mailer delivers emails, implementations live in the mailer package
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// VerificationPolicy tells what users who have not verified their email are restricted from
+type VerificationPolicy string
+
+const (
+	// VerificationOptional does not restrict unverified users
+	VerificationOptional VerificationPolicy = ""
+	// VerificationForAuthoring withholds the author role until the email is verified
+	VerificationForAuthoring VerificationPolicy = "authoring"
+	// VerificationForLogin rejects the login of unverified users
+	VerificationForLogin VerificationPolicy = "login"
+)
@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
 	domain "github.com/diantanjung/blogo/user-service/domain"
 	mock "github.com/stretchr/testify/mock"
@@ -58,6 +59,50 @@ func (_m *UserRepository) Fetch(ctx context.Context, cursor string, num int64) (
 	return r0, r1, r2
 }
 
+// FetchAfter provides a mock function with given fields: ctx, afterID, num
+func (_m *UserRepository) FetchAfter(ctx context.Context, afterID int64, num int64) ([]domain.User, error) {
+	ret := _m.Called(ctx, afterID, num)
+
+	var r0 []domain.User
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []domain.User); ok {
+		r0 = rf(ctx, afterID, num)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, afterID, num)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByEmail provides a mock function with given fields: ctx, email
+func (_m *UserRepository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	ret := _m.Called(ctx, email)
+
+	var r0 domain.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *UserRepository) GetByID(ctx context.Context, id int64) (domain.User, error) {
 	ret := _m.Called(ctx, id)
@@ -79,6 +124,120 @@ func (_m *UserRepository) GetByID(ctx context.Context, id int64) (domain.User, e
 	return r0, r1
 }
 
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.User, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []domain.User
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []domain.User); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUsername provides a mock function with given fields: ctx, username
+func (_m *UserRepository) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 domain.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPasswordHash provides a mock function with given fields: ctx, id
+func (_m *UserRepository) GetPasswordHash(ctx context.Context, id int64) (string, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, int64) string); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkEmailVerified provides a mock function with given fields: ctx, id, email, at
+func (_m *UserRepository) MarkEmailVerified(ctx context.Context, id int64, email string, at time.Time) error {
+	ret := _m.Called(ctx, id, email, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, id, email, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Purge provides a mock function with given fields: ctx, deletedBefore, num
+func (_m *UserRepository) Purge(ctx context.Context, deletedBefore time.Time, num int64) (int64, error) {
+	ret := _m.Called(ctx, deletedBefore, num)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) int64); ok {
+		r0 = rf(ctx, deletedBefore, num)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) error); ok {
+		r1 = rf(ctx, deletedBefore, num)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id, at
+func (_m *UserRepository) Restore(ctx context.Context, id int64, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Store provides a mock function with given fields: ctx, u
 func (_m *UserRepository) Store(ctx context.Context, u *domain.User) error {
 	ret := _m.Called(ctx, u)
@@ -93,6 +252,66 @@ func (_m *UserRepository) Store(ctx context.Context, u *domain.User) error {
 	return r0
 }
 
+// StoreBatch provides a mock function with given fields: ctx, users
+func (_m *UserRepository) StoreBatch(ctx context.Context, users []domain.User) error {
+	ret := _m.Called(ctx, users)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.User) error); ok {
+		r0 = rf(ctx, users)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreFormerUsername provides a mock function with given fields: ctx, id, username, at
+func (_m *UserRepository) StoreFormerUsername(ctx context.Context, id int64, username string, at time.Time) error {
+	ret := _m.Called(ctx, id, username, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, id, username, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Taken provides a mock function with given fields: ctx, usernames, emails
+func (_m *UserRepository) Taken(ctx context.Context, usernames []string, emails []string) (map[string]bool, map[string]bool, error) {
+	ret := _m.Called(ctx, usernames, emails)
+
+	var r0 map[string]bool
+	if rf, ok := ret.Get(0).(func(context.Context, []string, []string) map[string]bool); ok {
+		r0 = rf(ctx, usernames, emails)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]bool)
+		}
+	}
+
+	var r1 map[string]bool
+	if rf, ok := ret.Get(1).(func(context.Context, []string, []string) map[string]bool); ok {
+		r1 = rf(ctx, usernames, emails)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(map[string]bool)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, []string, []string) error); ok {
+		r2 = rf(ctx, usernames, emails)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Update provides a mock function with given fields: ctx, u
 func (_m *UserRepository) Update(ctx context.Context, u *domain.User) error {
 	ret := _m.Called(ctx, u)
@@ -106,3 +325,17 @@ func (_m *UserRepository) Update(ctx context.Context, u *domain.User) error {
 
 	return r0
 }
+
+// UpdatePassword provides a mock function with given fields: ctx, id, hash, at
+func (_m *UserRepository) UpdatePassword(ctx context.Context, id int64, hash string, at time.Time) error {
+	ret := _m.Called(ctx, id, hash, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, id, hash, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 
 	domain "github.com/diantanjung/blogo/user-service/domain"
 	mock "github.com/stretchr/testify/mock"
@@ -14,6 +15,20 @@ type UserUsecase struct {
 	mock.Mock
 }
 
+// ChangePassword provides a mock function with given fields: ctx, id, req
+func (_m *UserUsecase) ChangePassword(ctx context.Context, id int64, req domain.ChangePasswordRequest) error {
+	ret := _m.Called(ctx, id, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, domain.ChangePasswordRequest) error); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: ctx, id
 func (_m *UserUsecase) Delete(ctx context.Context, id int64) error {
 	ret := _m.Called(ctx, id)
@@ -28,6 +43,20 @@ func (_m *UserUsecase) Delete(ctx context.Context, id int64) error {
 	return r0
 }
 
+// Export provides a mock function with given fields: ctx, fn
+func (_m *UserUsecase) Export(ctx context.Context, fn func(domain.User) error) error {
+	ret := _m.Called(ctx, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(domain.User) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Fetch provides a mock function with given fields: ctx, cursor, num
 func (_m *UserUsecase) Fetch(ctx context.Context, cursor string, num int64) ([]domain.User, string, error) {
 	ret := _m.Called(ctx, cursor, num)
@@ -58,6 +87,36 @@ func (_m *UserUsecase) Fetch(ctx context.Context, cursor string, num int64) ([]d
 	return r0, r1, r2
 }
 
+// FetchAudit provides a mock function with given fields: ctx, id, cursor, num
+func (_m *UserUsecase) FetchAudit(ctx context.Context, id int64, cursor string, num int64) ([]domain.AuditEntry, string, error) {
+	ret := _m.Called(ctx, id, cursor, num)
+
+	var r0 []domain.AuditEntry
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64) []domain.AuditEntry); ok {
+		r0 = rf(ctx, id, cursor, num)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditEntry)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, int64) string); ok {
+		r1 = rf(ctx, id, cursor, num)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, string, int64) error); ok {
+		r2 = rf(ctx, id, cursor, num)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *UserUsecase) GetByID(ctx context.Context, id int64) (domain.User, error) {
 	ret := _m.Called(ctx, id)
@@ -79,6 +138,127 @@ func (_m *UserUsecase) GetByID(ctx context.Context, id int64) (domain.User, erro
 	return r0, r1
 }
 
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *UserUsecase) GetByIDs(ctx context.Context, ids []int64) ([]domain.User, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []domain.User
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []domain.User); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUsername provides a mock function with given fields: ctx, username
+func (_m *UserUsecase) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 domain.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProfile provides a mock function with given fields: ctx, userID
+func (_m *UserUsecase) GetProfile(ctx context.Context, userID int64) (domain.Profile, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 domain.Profile
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Profile); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(domain.Profile)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Import provides a mock function with given fields: ctx, r, dryRun
+func (_m *UserUsecase) Import(ctx context.Context, r domain.UserReader, dryRun bool) (domain.ImportReport, error) {
+	ret := _m.Called(ctx, r, dryRun)
+
+	var r0 domain.ImportReport
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserReader, bool) domain.ImportReport); ok {
+		r0 = rf(ctx, r, dryRun)
+	} else {
+		r0 = ret.Get(0).(domain.ImportReport)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.UserReader, bool) error); ok {
+		r1 = rf(ctx, r, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResendVerification provides a mock function with given fields: ctx, req
+func (_m *UserUsecase) ResendVerification(ctx context.Context, req domain.ResendVerificationRequest) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ResendVerificationRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *UserUsecase) Restore(ctx context.Context, id int64) (domain.User, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.User
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Store provides a mock function with given fields: ctx, u
 func (_m *UserUsecase) Store(ctx context.Context, u *domain.User) error {
 	ret := _m.Called(ctx, u)
@@ -106,3 +286,52 @@ func (_m *UserUsecase) Update(ctx context.Context, u *domain.User) error {
 
 	return r0
 }
+
+// UpdateAvatar provides a mock function with given fields: ctx, userID, r
+func (_m *UserUsecase) UpdateAvatar(ctx context.Context, userID int64, r io.Reader) (domain.Profile, error) {
+	ret := _m.Called(ctx, userID, r)
+
+	var r0 domain.Profile
+	if rf, ok := ret.Get(0).(func(context.Context, int64, io.Reader) domain.Profile); ok {
+		r0 = rf(ctx, userID, r)
+	} else {
+		r0 = ret.Get(0).(domain.Profile)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, io.Reader) error); ok {
+		r1 = rf(ctx, userID, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProfile provides a mock function with given fields: ctx, p
+func (_m *UserUsecase) UpdateProfile(ctx context.Context, p *domain.Profile) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Profile) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VerifyEmail provides a mock function with given fields: ctx, token
+func (_m *UserUsecase) VerifyEmail(ctx context.Context, token string) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
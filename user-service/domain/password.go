@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PasswordReset is a single-use password reset request, only the hash of its token is stored
+type PasswordReset struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// ForgotPasswordRequest is the body of a password reset request
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest is the body completing a password reset
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// ChangePasswordRequest is the body of a password change by the user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type PasswordResetRepository interface {
+	Store(ctx context.Context, pr *PasswordReset) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (PasswordReset, error)
+	MarkUsed(ctx context.Context, id int64, at time.Time) error
+}
+
+// PasswordHistoryRepository keeps the previous password hashes of users to prevent their reuse
+type PasswordHistoryRepository interface {
+	Store(ctx context.Context, userID int64, hash string, at time.Time) error
+	GetRecent(ctx context.Context, userID int64, num int64) ([]string, error)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Profile is the public author profile of a user, anyone can read it. The user's email is never part of it.
+type Profile struct {
+	UserID int64 `json:"user_id"`
+	// Username and Name come from the user, they are changed through UserUsecase.Update
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Bio      string `json:"bio" validate:"max=500"`
+	// AvatarURL and Website must be http or https URLs, they are shown as links on the author pages
+	AvatarURL string `json:"avatar_url" validate:"omitempty,max=2048,url,startswith=https://|startswith=http://"`
+	// Avatars are the thumbnails of the uploaded avatar, AvatarURL being the largest. They are only set by
+	// UserUsecase.UpdateAvatar, and dropped when AvatarURL is changed.
+	Avatars     []ProfileAvatar    `json:"avatars" validate:"-"`
+	Website     string             `json:"website" validate:"omitempty,max=2048,url,startswith=https://|startswith=http://"`
+	Links       []ProfileLink      `json:"links" validate:"max=10,dive"`
+	Preferences ProfilePreferences `json:"preferences"`
+	// JoinedAt is the signup time of the user, only set when Preferences.ShowJoinDate is
+	JoinedAt  *time.Time `json:"joined_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ProfileLink is a link of the profile to the author elsewhere, e.g. a social network
+type ProfileLink struct {
+	Label string `json:"label" validate:"required,max=32"`
+	URL   string `json:"url" validate:"required,max=2048,url,startswith=https://|startswith=http://"`
+}
+
+// ProfileAvatar is a thumbnail of the avatar, a square of Size pixels
+type ProfileAvatar struct {
+	Size int    `json:"size"`
+	URL  string `json:"url"`
+}
+
+// ProfilePreferences are the choices of the author on how their pages are displayed
+type ProfilePreferences struct {
+	Theme        string `json:"theme,omitempty" validate:"omitempty,oneof=light dark"`
+	ShowJoinDate bool   `json:"show_join_date"`
+}
+
+type ProfileRepository interface {
+	// GetByUserID returns ErrNotFound when the user never saved a profile
+	GetByUserID(ctx context.Context, userID int64) (Profile, error)
+	// Upsert will create the profile of the user or replace it
+	Upsert(ctx context.Context, p *Profile) error
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit is a token bucket allowing Burst requests at once, refilled at Burst tokens per Period
+type RateLimit struct {
+	Burst  int64
+	Period time.Duration
+}
+
+// Rate returns the refill rate of the bucket in tokens per second
+func (l RateLimit) Rate() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+// RateLimitStore keeps the token buckets of the rate limiter, implementations shared by every replica
+// must update them atomically
+type RateLimitStore interface {
+	// Take refills the bucket of key up to now and removes one token when there is one.
+	// It returns the tokens left in the bucket and whether a token was taken.
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (tokens float64, allowed bool, err error)
+	// Prune will delete up to num buckets last taken from before idleSince, returning how many were deleted.
+	// A bucket idle for longer than its period is full again, deleting it changes nothing.
+	Prune(ctx context.Context, idleSince time.Time, num int64) (int64, error)
+}
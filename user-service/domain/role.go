@@ -0,0 +1,23 @@
+package domain
+
+import "context"
+
+// Role is the authorization role granted to a user
+type Role string
+
+const (
+	// RoleAdmin may manage every user
+	RoleAdmin Role = "admin"
+	// RoleAuthor may write articles
+	RoleAuthor Role = "author"
+	// RoleReader may read articles, it is granted to every new user
+	RoleReader Role = "reader"
+)
+
+type RoleRepository interface {
+	GetByUserID(ctx context.Context, userID int64) ([]Role, error)
+	GetByUserIDs(ctx context.Context, userIDs []int64) (map[int64][]Role, error)
+	Store(ctx context.Context, userID int64, roles []Role) error
+	// StoreBatch adds the roles of several users at once, keyed by user id
+	StoreBatch(ctx context.Context, roles map[int64][]Role) error
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// TxManager runs a unit of work in a transaction. Repositories called with the ctx given to fn join it.
+type TxManager interface {
+	// WithinTx calls fn in a transaction, committed when fn returns nil and rolled back otherwise.
+	// Calls nested in fn join the outer transaction. Transactions failing on a serialization
+	// conflict are retried, so fn must be safe to run more than once.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
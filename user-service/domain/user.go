@@ -2,31 +2,84 @@ package domain
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
 type User struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username" validate:"required"`
-	Name      string    `json:"name" validate:"required"`
-	Email     string    `json:"email" validate:"required"`
-	Password  string    `json:"password" validate:"required"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              int64      `json:"id"`
+	Username        string     `json:"username" validate:"required"`
+	Name            string     `json:"name" validate:"required"`
+	Email           string     `json:"email,omitempty" validate:"required,email"`
+	Password        string     `json:"password,omitempty" validate:"required"`
+	Roles           []Role     `json:"roles" validate:"dive,oneof=admin author reader" faker:"-"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// ResendVerificationRequest is the body asking for another verification link
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
 type UserUsecase interface {
 	Fetch(ctx context.Context, cursor string, num int64) ([]User, string, error)
 	GetByID(ctx context.Context, id int64) (User, error)
+	// GetByUsername returns the user with the username, or who had it before a rename, ignoring case.
+	// The returned user has its current username.
+	GetByUsername(ctx context.Context, username string) (User, error)
+	// GetByIDs returns the users found among ids, ordered by id
+	GetByIDs(ctx context.Context, ids []int64) ([]User, error)
 	Update(ctx context.Context, u *User) error
 	Store(ctx context.Context, u *User) error
 	Delete(ctx context.Context, id int64) error
+	// Restore brings back a deleted user, it is restricted to admins
+	Restore(ctx context.Context, id int64) (User, error)
+	VerifyEmail(ctx context.Context, token string) error
+	// ResendVerification will send another verification link to the unverified user of the email.
+	// It succeeds whether the email is registered or not.
+	ResendVerification(ctx context.Context, req ResendVerificationRequest) error
+	ChangePassword(ctx context.Context, id int64, req ChangePasswordRequest) error
+	FetchAudit(ctx context.Context, id int64, cursor string, num int64) ([]AuditEntry, string, error)
+	// Import creates the users read from r in batches, the rows that fail are reported and skipped.
+	// It is restricted to admins.
+	Import(ctx context.Context, r UserReader, dryRun bool) (ImportReport, error)
+	// Export calls fn for every user in id order, it is restricted to admins
+	Export(ctx context.Context, fn func(User) error) error
+	// GetProfile returns the public profile of the user, an empty one when it was never saved
+	GetProfile(ctx context.Context, userID int64) (Profile, error)
+	// UpdateProfile will replace the profile of the user, it is restricted to the user and admins
+	UpdateProfile(ctx context.Context, p *Profile) error
+	// UpdateAvatar will store the thumbnails of the image read from r as the avatar of the user,
+	// it is restricted to the user and admins
+	UpdateAvatar(ctx context.Context, userID int64, r io.Reader) (Profile, error)
 }
 
 type UserRepository interface {
 	Fetch(ctx context.Context, cursor string, num int64) ([]User, string, error)
 	GetByID(ctx context.Context, id int64) (User, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]User, error)
+	// GetByUsername returns the user with the username, or with the username among its former ones,
+	// ignoring case. Deleted users are not found.
+	GetByUsername(ctx context.Context, username string) (User, error)
+	GetByEmail(ctx context.Context, email string) (User, error)
 	Update(ctx context.Context, u *User) error
 	Store(ctx context.Context, u *User) error
+	// StoreBatch inserts the users at once and sets their ids
+	StoreBatch(ctx context.Context, users []User) error
+	// FetchAfter returns up to num users with an id greater than afterID, ordered by id
+	FetchAfter(ctx context.Context, afterID int64, num int64) ([]User, error)
+	// Taken returns the usernames and emails among the given ones that belong to a user, deleted
+	// users included. Usernames are compared ignoring case, and former usernames are taken too.
+	Taken(ctx context.Context, usernames, emails []string) (map[string]bool, map[string]bool, error)
+	// StoreFormerUsername will keep the previous username of a renamed user, it keeps resolving to the user
+	StoreFormerUsername(ctx context.Context, id int64, username string, at time.Time) error
 	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64, at time.Time) error
+	// Purge will hard delete up to num users deleted before the given time, returning how many were deleted
+	Purge(ctx context.Context, deletedBefore time.Time, num int64) (int64, error)
+	MarkEmailVerified(ctx context.Context, id int64, email string, at time.Time) error
+	UpdatePassword(ctx context.Context, id int64, hash string, at time.Time) error
+	GetPasswordHash(ctx context.Context, id int64) (string, error)
 }
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Webhook events partners can subscribe to
+const (
+	WebhookUserCreated      = "user.created"
+	WebhookUserUpdated      = "user.updated"
+	WebhookUserEmailChanged = "user.email_changed"
+	WebhookUserDeleted      = "user.deleted"
+)
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead is the dead-letter state of deliveries that failed every attempt, they are only replayed by hand
+	DeliveryDead = "dead"
+)
+
+// Webhook is the subscription of a partner endpoint to webhook events
+type Webhook struct {
+	ID     int64    `json:"id"`
+	URL    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=user.created user.updated user.email_changed user.deleted"`
+	// Secret signs the deliveries, it is only returned when the webhook is created
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookUpdate is the body of a webhook update, the webhook stays as active as it was when Active is left out
+type WebhookUpdate struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=user.created user.updated user.email_changed user.deleted"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookDelivery is the delivery of an event to a webhook, and the log of its attempts
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int64           `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int64           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// WebhookUsecase manages the webhooks, it is restricted to admins except Enqueue
+type WebhookUsecase interface {
+	Fetch(ctx context.Context, cursor string, num int64) ([]Webhook, string, error)
+	GetByID(ctx context.Context, id int64) (Webhook, error)
+	Store(ctx context.Context, w *Webhook) error
+	Update(ctx context.Context, id int64, u WebhookUpdate) (Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	FetchDeliveries(ctx context.Context, webhookID int64, cursor string, num int64) ([]WebhookDelivery, string, error)
+	Replay(ctx context.Context, webhookID, deliveryID int64) error
+	// Enqueue will create the deliveries of a domain event to the webhooks subscribed to it
+	Enqueue(ctx context.Context, e Event) error
+}
+
+type WebhookRepository interface {
+	Fetch(ctx context.Context, cursor string, num int64) ([]Webhook, string, error)
+	GetByID(ctx context.Context, id int64) (Webhook, error)
+	// GetByEvent returns the active webhooks subscribed to the event
+	GetByEvent(ctx context.Context, event string) ([]Webhook, error)
+	Store(ctx context.Context, w *Webhook) error
+	Update(ctx context.Context, w *Webhook) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type WebhookDeliveryRepository interface {
+	// Store will create the delivery, unless the event was already delivered to the webhook
+	Store(ctx context.Context, d *WebhookDelivery) error
+	GetByID(ctx context.Context, id int64) (WebhookDelivery, error)
+	Fetch(ctx context.Context, webhookID int64, cursor string, num int64) ([]WebhookDelivery, string, error)
+	// ClaimDue will lock up to num pending deliveries due for an attempt, so other dispatchers skip them for lease
+	ClaimDue(ctx context.Context, num int64, lease time.Duration) ([]WebhookDelivery, error)
+	// Update will save the outcome of an attempt
+	Update(ctx context.Context, d *WebhookDelivery) error
+}
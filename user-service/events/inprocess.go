@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// Handler consumes an event, a returned error makes the relay publish it again later
+type Handler func(ctx context.Context, e domain.Event) error
+
+// InProcessPublisher delivers events to handlers subscribed in the same process
+type InProcessPublisher struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewInProcessPublisher will create a domain.EventPublisher calling the subscribed handlers synchronously
+func NewInProcessPublisher() *InProcessPublisher {
+	return &InProcessPublisher{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe will call h for every event of eventType, or of any type when eventType is "*"
+func (p *InProcessPublisher) Subscribe(eventType string, h Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.handlers[eventType] = append(p.handlers[eventType], h)
+}
+
+func (p *InProcessPublisher) Publish(ctx context.Context, e domain.Event) error {
+	p.mu.RLock()
+	handlers := append(append([]Handler{}, p.handlers[e.Type]...), p.handlers["*"]...)
+	p.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type kafkaPublisher struct {
+	w *kafka.Writer
+}
+
+// NewKafkaPublisher will create a domain.EventPublisher writing every event to topic, on any Kafka
+// compatible broker. Events are keyed by aggregate so the events of a user stay ordered,
+// and carry their ID in the X-Event-ID header for dedup. The publisher is an io.Closer,
+// it must be closed once the relay stopped to release its broker connections.
+func NewKafkaPublisher(brokers []string, topic string) domain.EventPublisher {
+	return &kafkaPublisher{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, e domain.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return p.w.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strconv.FormatInt(e.AggregateID, 10)),
+		Value: body,
+		Headers: []kafka.Header{
+			{Key: HeaderEventID, Value: []byte(e.ID)},
+			{Key: HeaderEventType, Value: []byte(e.Type)},
+		},
+	})
+}
+
+// Close will flush the pending messages and close the connections to the brokers
+func (p *kafkaPublisher) Close() error {
+	return p.w.Close()
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+	"errors"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type multiPublisher struct {
+	publishers []domain.EventPublisher
+}
+
+// NewMultiPublisher will create a domain.EventPublisher publishing every event to all the publishers.
+// A failure of any of them fails the event, which the relay publishes again to all of them.
+func NewMultiPublisher(publishers ...domain.EventPublisher) domain.EventPublisher {
+	return &multiPublisher{publishers: publishers}
+}
+
+func (p *multiPublisher) Publish(ctx context.Context, e domain.Event) error {
+	var errs []error
+	for _, pub := range p.publishers {
+		if err := pub.Publish(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
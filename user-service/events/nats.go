@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type natsPublisher struct {
+	js     nats.JetStreamContext
+	prefix string
+}
+
+// NewNATSPublisher will create a domain.EventPublisher publishing every event to the JetStream subject
+// prefix + "." + type. The event ID is sent as Nats-Msg-Id so the stream drops redeliveries.
+func NewNATSPublisher(nc *nats.Conn, prefix string) (domain.EventPublisher, error) {
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, err
+	}
+	return &natsPublisher{js: js, prefix: prefix}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, e domain.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.prefix + "." + e.Type)
+	msg.Data = body
+	msg.Header.Set(nats.MsgIdHdr, e.ID)
+	msg.Header.Set(HeaderEventType, e.Type)
+	_, err = p.js.PublishMsg(msg, nats.Context(ctx))
+	return err
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// RelayConfig configures the outbox relay
+type RelayConfig struct {
+	// Batch is the number of events claimed at once
+	Batch int64
+	// Interval is the wait between polls when the outbox is drained
+	Interval time.Duration
+	// Lease is how long claimed events are hidden from other relays, it must outlast publishing a batch
+	Lease time.Duration
+	// Backoff is the wait after the first failed attempt, doubled on every following one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxAttempts is the number of attempts before an event is dead-lettered
+	MaxAttempts int64
+}
+
+// Relay publishes the events of the outbox. An event is marked published only after the publisher
+// accepted it, so it is delivered at least once.
+type Relay struct {
+	outbox    domain.OutboxRepository
+	publisher domain.EventPublisher
+	cfg       RelayConfig
+}
+
+// NewRelay will create a Relay publishing the events of outbox to publisher
+func NewRelay(outbox domain.OutboxRepository, publisher domain.EventPublisher, cfg RelayConfig) *Relay {
+	return &Relay{outbox: outbox, publisher: publisher, cfg: cfg}
+}
+
+// Run will relay events until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	log := logging.FromContext(ctx).WithField("worker", "outbox_relay")
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			log.Error(err)
+		}
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.cfg.Interval):
+		}
+	}
+}
+
+// RelayOnce will publish one batch of events, returning how many were published
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.outbox.Claim(ctx, r.cfg.Batch, r.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, e := range events {
+		if err = r.publisher.Publish(ctx, e); err != nil {
+			if err = r.fail(ctx, e, err); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err = r.outbox.MarkPublished(ctx, e.ID, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// fail will schedule another attempt of the event, or dead-letter it once it is out of attempts
+func (r *Relay) fail(ctx context.Context, e domain.Event, cause error) error {
+	log := logging.FromContext(ctx).WithFields(logrus.Fields{"event_id": e.ID, "event_type": e.Type, "attempts": e.Attempts})
+	now := time.Now()
+	if e.Attempts >= r.cfg.MaxAttempts {
+		log.WithError(cause).Error("event dead-lettered")
+		return r.outbox.MarkDead(ctx, e.ID, cause.Error(), now)
+	}
+	log.WithError(cause).Warn("event publishing failed, retrying")
+	return r.outbox.MarkFailed(ctx, e.ID, cause.Error(), now.Add(r.backoff(e.Attempts)))
+}
+
+func (r *Relay) backoff(attempts int64) time.Duration {
+	shift := attempts - 1
+	if shift > 30 {
+		shift = 30
+	}
+	b := r.cfg.Backoff << uint(shift)
+	if b <= 0 || b > r.cfg.MaxBackoff {
+		return r.cfg.MaxBackoff
+	}
+	return b
+}
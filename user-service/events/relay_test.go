@@ -0,0 +1,52 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/events"
+)
+
+func TestRelayOnce(t *testing.T) {
+	created := domain.Event{ID: "1", Type: domain.EventUserCreated, AggregateID: 7}
+	deleted := domain.Event{ID: "2", Type: domain.EventUserDeleted, AggregateID: 7, Attempts: 3}
+	dead := domain.Event{ID: "3", Type: domain.EventUserDeleted, AggregateID: 8, Attempts: 5}
+
+	outbox := new(mocks.OutboxRepository)
+	outbox.On("Claim", mock.Anything, int64(10), time.Minute).Return([]domain.Event{created, deleted, dead}, nil)
+	outbox.On("MarkPublished", mock.Anything, "1", mock.AnythingOfType("time.Time")).Return(nil)
+	// the third attempt waits four times the backoff
+	outbox.On("MarkFailed", mock.Anything, "2", "consumer down", mock.MatchedBy(func(retryAt time.Time) bool {
+		wait := time.Until(retryAt)
+		return wait > 3*time.Minute && wait <= 4*time.Minute
+	})).Return(nil)
+	// the last attempt dead-letters the event
+	outbox.On("MarkDead", mock.Anything, "3", "consumer down", mock.AnythingOfType("time.Time")).Return(nil)
+
+	var got []domain.Event
+	publisher := events.NewInProcessPublisher()
+	publisher.Subscribe(domain.EventUserDeleted, func(ctx context.Context, e domain.Event) error {
+		return errors.New("consumer down")
+	})
+	publisher.Subscribe("*", func(ctx context.Context, e domain.Event) error {
+		got = append(got, e)
+		return nil
+	})
+
+	r := events.NewRelay(outbox, publisher, events.RelayConfig{
+		Batch: 10, Lease: time.Minute, Backoff: time.Minute, MaxBackoff: time.Hour, MaxAttempts: 5,
+	})
+	n, err := r.RelayOnce(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []domain.Event{created}, got)
+	outbox.AssertExpectations(t)
+}
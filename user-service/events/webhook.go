@@ -0,0 +1,55 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// Headers set on webhook and broker messages so consumers can route and dedup them
+const (
+	HeaderEventID   = "X-Event-ID"
+	HeaderEventType = "X-Event-Type"
+)
+
+type webhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPublisher will create a domain.EventPublisher posting every event as JSON to url.
+// Any status other than 2xx is a failure.
+func NewWebhookPublisher(url string, client *http.Client) domain.EventPublisher {
+	return &webhookPublisher{url: url, client: client}
+}
+
+func (p *webhookPublisher) Publish(ctx context.Context, e domain.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, e.ID)
+	req.Header.Set(HeaderEventType, e.Type)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook %s answered %s", p.url, res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/events"
+)
+
+func TestWebhookPublisher(t *testing.T) {
+	var got domain.Event
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.Header.Get(events.HeaderEventID))
+		assert.Equal(t, domain.EventUserCreated, r.Header.Get(events.HeaderEventType))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	e := domain.Event{ID: "1", Type: domain.EventUserCreated, AggregateID: 7, Payload: json.RawMessage(`{"username":"dias"}`)}
+	p := events.NewWebhookPublisher(srv.URL, srv.Client())
+	require.NoError(t, p.Publish(context.TODO(), e))
+	assert.Equal(t, e.ID, got.ID)
+	assert.JSONEq(t, `{"username":"dias"}`, string(got.Payload))
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, p.Publish(context.TODO(), e))
+}
@@ -0,0 +1,89 @@
+// Package imaging decodes the images uploaded by users and renders them as square JPEG thumbnails
+// with pure Go libraries. The thumbnails are encoded from the pixels only, so the metadata of the
+// upload, EXIF and GPS coordinates included, is never served.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+
+	// the decoders of the accepted formats
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// ContentTypes are the accepted image types, recognized from the content rather than the name of the file
+var ContentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// Decode will decode the image in data after checking its content type and that it has at most maxPixels
+// pixels, before allocating them. JPEG images are rotated according to their EXIF orientation.
+// A content that is not an accepted image is a domain.ErrUnsupportedMediaType.
+func Decode(data []byte, maxPixels int64) (image.Image, error) {
+	if !accepted(http.DetectContentType(data)) {
+		return nil, domain.ErrUnsupportedMediaType
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, domain.ErrUnsupportedMediaType
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, domain.ErrUnsupportedMediaType
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, domain.ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, domain.ErrUnsupportedMediaType
+	}
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	return img, nil
+}
+
+// Thumbnail will crop the center square of img and scale it to size pixels, transparent pixels become white
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	src := image.Rect(x0, y0, x0+side, y0+side)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Over, nil)
+	return dst
+}
+
+// EncodeJPEG will encode img as a JPEG of the given quality, from 1 to 100
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode thumbnail: %v", err)
+	}
+	return b.Bytes(), nil
+}
+
+func accepted(contentType string) bool {
+	for _, t := range ContentTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
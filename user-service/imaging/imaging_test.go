@@ -0,0 +1,102 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/imaging"
+)
+
+// halves is a w by h image, red on its left half and blue on its right one
+func halves(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF segment with the given orientation after the SOI marker of a JPEG
+func withOrientation(t *testing.T, jpg []byte, orientation byte) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, 0, 0, 0, 0}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+	require.Equal(t, []byte{0xFF, 0xD8}, jpg[:2])
+	return append(append(append([]byte(nil), jpg[:2]...), segment...), jpg[2:]...)
+}
+
+func isRed(c color.Color) bool {
+	r, _, b, _ := c.RGBA()
+	return r > 0xC000 && b < 0x4000
+}
+
+func TestDecode(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, jpeg.Encode(&b, halves(40, 20), &jpeg.Options{Quality: 95}))
+
+	img, err := imaging.Decode(b.Bytes(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+	assert.True(t, isRed(img.At(5, 10)))
+
+	// orientation 6 is a quarter turn clockwise, the left half ends up on top
+	img, err = imaging.Decode(withOrientation(t, b.Bytes(), 6), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 20, img.Bounds().Dx())
+	assert.Equal(t, 40, img.Bounds().Dy())
+	assert.True(t, isRed(img.At(10, 5)))
+	assert.False(t, isRed(img.At(10, 35)))
+
+	_, err = imaging.Decode(b.Bytes(), 799)
+	assert.Equal(t, domain.ErrTooLarge, err)
+
+	_, err = imaging.Decode([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), 1000)
+	assert.Equal(t, domain.ErrUnsupportedMediaType, err)
+	_, err = imaging.Decode(b.Bytes()[:20], 1000)
+	assert.Equal(t, domain.ErrUnsupportedMediaType, err)
+}
+
+func TestThumbnail(t *testing.T) {
+	// a transparent png wider than high
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 100))
+	var b bytes.Buffer
+	require.NoError(t, png.Encode(&b, img))
+	decoded, err := imaging.Decode(b.Bytes(), 1<<20)
+	require.NoError(t, err)
+
+	thumb := imaging.Thumbnail(decoded, 64)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), thumb.Bounds())
+	r, g, bl, a := thumb.At(32, 32).RGBA()
+	assert.Equal(t, []uint32{0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF}, []uint32{r, g, bl, a}, "transparent pixels become white")
+
+	// the center square of the halves keeps both colors
+	thumb = imaging.Thumbnail(halves(300, 100), 64)
+	assert.True(t, isRed(thumb.At(5, 32)))
+	assert.False(t, isRed(thumb.At(58, 32)))
+
+	var src bytes.Buffer
+	require.NoError(t, jpeg.Encode(&src, halves(40, 20), nil))
+	decoded, err = imaging.Decode(withOrientation(t, src.Bytes(), 3), 1000)
+	require.NoError(t, err)
+	jpg, err := imaging.EncodeJPEG(imaging.Thumbnail(decoded, 16), 85)
+	require.NoError(t, err)
+	assert.NotContains(t, string(jpg), "Exif", "the metadata of the upload is not kept")
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(jpg))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 16, cfg.Width)
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// ResponseError represent the reseponse error struct
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+// JobHandler represent the httphandler for jobs
+type JobHandler struct {
+	JobUsecase domain.JobUsecase
+}
+
+// NewJobsHandler will initialize the jobs/ resources endpoint, they are restricted to admins
+func NewJobsHandler(e *echo.Echo, ju domain.JobUsecase) {
+	handler := &JobHandler{
+		JobUsecase: ju,
+	}
+	e.GET("/jobs", handler.Fetch)
+	e.GET("/jobs/:id", handler.GetByID)
+	e.POST("/jobs/:id/retry", handler.Retry)
+}
+
+// Fetch will list the jobs, of one status with status=pending|running|succeeded|dead
+func (a *JobHandler) Fetch(c echo.Context) error {
+	num, _ := strconv.Atoi(c.QueryParam("num"))
+	cursor := c.QueryParam("cursor")
+	ctx := c.Request().Context()
+	jobs, nextCursor, err := a.JobUsecase.Fetch(ctx, c.QueryParam("status"), cursor, int64(num))
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	c.Response().Header().Set(`X-Cursor`, nextCursor)
+	return c.JSON(http.StatusOK, jobs)
+}
+
+func (a *JobHandler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	ctx := c.Request().Context()
+	j, err := a.JobUsecase.GetByID(ctx, id)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, j)
+}
+
+// Retry will schedule a dead job again, a worker runs it asynchronously
+func (a *JobHandler) Retry(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err = a.JobUsecase.Retry(ctx, id); err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func getStatusCode(ctx context.Context, err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	logging.FromContext(ctx).Error(err)
+	switch err {
+	case domain.ErrNotFound:
+		return http.StatusNotFound
+	case domain.ErrBadParamInput:
+		return http.StatusBadRequest
+	case domain.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case domain.ErrForbidden:
+		return http.StatusForbidden
+	case domain.ErrConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,65 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	jobHttp "github.com/diantanjung/blogo/user-service/job/delivery/http"
+)
+
+func TestFetch(t *testing.T) {
+	jobs := []domain.Job{{ID: 1, Type: domain.JobPurgeUsers, Payload: []byte(`{"retention":"720h0m0s"}`), Status: domain.JobDead, LastError: "boom"}}
+	mockUCase := new(mocks.JobUsecase)
+	mockUCase.On("Fetch", mock.Anything, domain.JobDead, "abc", int64(5)).Return(jobs, "def", nil)
+
+	e := echo.New()
+	jobHttp.NewJobsHandler(e, mockUCase)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/jobs?status=dead&num=5&cursor=abc", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "def", rec.Header().Get("X-Cursor"))
+	var list []domain.Job
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.JSONEq(t, `{"retention":"720h0m0s"}`, string(list[0].Payload))
+	mockUCase.AssertExpectations(t)
+}
+
+func TestGetByID(t *testing.T) {
+	mockUCase := new(mocks.JobUsecase)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).Return(domain.Job{ID: 1, Type: domain.JobPurgeUsers}, nil)
+	mockUCase.On("GetByID", mock.Anything, int64(2)).Return(domain.Job{}, domain.ErrForbidden)
+
+	e := echo.New()
+	jobHttp.NewJobsHandler(e, mockUCase)
+	for path, code := range map[string]int{"/jobs/1": http.StatusOK, "/jobs/2": http.StatusForbidden, "/jobs/abc": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, path, nil))
+		assert.Equal(t, code, rec.Code, path)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	mockUCase := new(mocks.JobUsecase)
+	mockUCase.On("Retry", mock.Anything, int64(1)).Return(nil)
+	mockUCase.On("Retry", mock.Anything, int64(2)).Return(domain.ErrConflict)
+
+	e := echo.New()
+	jobHttp.NewJobsHandler(e, mockUCase)
+	for path, code := range map[string]int{"/jobs/1/retry": http.StatusAccepted, "/jobs/2/retry": http.StatusConflict} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.POST, path, nil))
+		assert.Equal(t, code, rec.Code, path)
+	}
+	mockUCase.AssertExpectations(t)
+}
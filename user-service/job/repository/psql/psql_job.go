@@ -0,0 +1,157 @@
+package psql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/sqltx"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+)
+
+const jobColumns = `id, type, payload, COALESCE(key, ''), status, attempts, max_attempts, run_at, last_error, created_at, updated_at, COALESCE(locked_by, '')`
+
+type psqlJobRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlJobRepository will create an object that represent the domain.JobRepository interface.
+// Jobs are enqueued in the transaction of ctx, so they are only run when the work scheduling them is committed.
+func NewPsqlJobRepository(Conn *sql.DB) domain.JobRepository {
+	return &psqlJobRepository{Conn}
+}
+
+func (m *psqlJobRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.Job, err error) {
+	log := logging.FromContext(ctx).WithField("repository", "psql_job")
+	rows, err := sqltx.Conn(ctx, m.Conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			log.Error(errRow)
+		}
+	}()
+
+	result = make([]domain.Job, 0)
+	for rows.Next() {
+		j := domain.Job{}
+		var payload []byte
+		err = rows.Scan(&j.ID, &j.Type, &payload, &j.Key, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+			&j.LastError, &j.CreatedAt, &j.UpdatedAt, &j.LockedBy)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		j.Payload = payload
+		result = append(result, j)
+	}
+
+	return result, nil
+}
+
+func (m *psqlJobRepository) Enqueue(ctx context.Context, j *domain.Job) (err error) {
+	query := `INSERT INTO jobs (type, payload, key, status, max_attempts, run_at, created_at, updated_at)
+  						VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8)
+  						ON CONFLICT (key) DO NOTHING RETURNING id`
+
+	err = sqltx.Conn(ctx, m.Conn).QueryRowContext(ctx, query, j.Type, []byte(j.Payload), j.Key, j.Status, j.MaxAttempts, j.RunAt, j.CreatedAt, j.UpdatedAt).Scan(&j.ID)
+	if err == sql.ErrNoRows {
+		// a job with the same key exists, typically a schedule already enqueued by another instance
+		return nil
+	}
+	return
+}
+
+func (m *psqlJobRepository) GetByID(ctx context.Context, id int64) (domain.Job, error) {
+	list, err := m.fetch(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id)
+	if err != nil {
+		return domain.Job{}, err
+	}
+	if len(list) == 0 {
+		return domain.Job{}, domain.ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (m *psqlJobRepository) Fetch(ctx context.Context, status string, cursor string, num int64) (res []domain.Job, nextCursor string, err error) {
+	query := `SELECT ` + jobColumns + `
+  						FROM jobs WHERE ($1 = '' OR status = $1) AND id > $2 ORDER BY id LIMIT $3`
+
+	// jobs enqueued in one transaction share their created_at, the ids tell them apart
+	decodedCursor, err := repository.DecodeIDCursor(cursor)
+	if err != nil && cursor != "" {
+		return nil, "", domain.ErrBadParamInput
+	}
+
+	res, err = m.fetch(ctx, query, status, decodedCursor, num)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(res) == int(num) {
+		nextCursor = repository.EncodeIDCursor(res[len(res)-1].ID)
+	}
+	return
+}
+
+func (m *psqlJobRepository) Claim(ctx context.Context, jobType string, num int64, lease time.Duration) ([]domain.Job, error) {
+	// every claim gets its own token, so a worker whose lease expired can not save over the run of the next one
+	token, err := claimToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// SKIP LOCKED lets concurrent workers claim different jobs instead of waiting on each other
+	query := `UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_until = now() + make_interval(secs => $1), locked_by = $4
+  						WHERE id IN (
+  							SELECT id FROM jobs
+  							WHERE type = $2 AND run_at <= now()
+  								AND (status = 'pending' OR (status = 'running' AND locked_until < now()))
+  							ORDER BY run_at LIMIT $3 FOR UPDATE SKIP LOCKED)
+  						RETURNING ` + jobColumns
+
+	res, err := m.fetch(ctx, query, lease.Seconds(), jobType, num, token)
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the order of the subquery
+	sort.Slice(res, func(i, j int) bool { return res[i].RunAt.Before(res[j].RunAt) })
+	return res, nil
+}
+
+func (m *psqlJobRepository) Update(ctx context.Context, j *domain.Job) (err error) {
+	// a job that is not running has no token, so an admin retry of a dead job matches on NULL
+	query := `UPDATE jobs SET status=$1, attempts=$2, run_at=$3, last_error=$4, updated_at=$5, locked_until=NULL, locked_by=NULL
+  						WHERE id=$6 AND locked_by IS NOT DISTINCT FROM NULLIF($7, '')`
+
+	res, err := sqltx.Conn(ctx, m.Conn).ExecContext(ctx, query, j.Status, j.Attempts, j.RunAt, j.LastError, j.UpdatedAt, j.ID, j.LockedBy)
+	if err != nil {
+		return
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affect != 1 {
+		return domain.ErrConflict
+	}
+	j.LockedBy = ""
+	return
+}
+
+func claimToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
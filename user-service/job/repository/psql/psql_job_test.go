@@ -0,0 +1,121 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	jobPsqlRepo "github.com/diantanjung/blogo/user-service/job/repository/psql"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+)
+
+var jobColumns = []string{"id", "type", "payload", "key", "status", "attempts", "max_attempts", "run_at", "last_error", "created_at", "updated_at", "locked_by"}
+
+func TestEnqueueJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	j := &domain.Job{Type: domain.JobPurgeUsers, Payload: []byte(`{"retention":"720h0m0s"}`), Key: "users.purge@2020-01-01T00:00:00Z",
+		Status: domain.JobPending, MaxAttempts: 10, RunAt: now, CreatedAt: now, UpdatedAt: now}
+
+	query := "INSERT INTO jobs \\(type, payload, key, status, max_attempts, run_at, created_at, updated_at\\)"
+	mock.ExpectQuery(query).
+		WithArgs(j.Type, []byte(j.Payload), j.Key, j.Status, j.MaxAttempts, j.RunAt, j.CreatedAt, j.UpdatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	r := jobPsqlRepo.NewPsqlJobRepository(db)
+
+	err = r.Enqueue(context.TODO(), j)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), j.ID)
+
+	// the key is taken, the job is not enqueued twice
+	again := *j
+	again.ID = 0
+	err = r.Enqueue(context.TODO(), &again)
+	require.NoError(t, err)
+	assert.Zero(t, again.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows(jobColumns).
+		AddRow(2, domain.JobPurgeUsers, []byte(`{}`), "", domain.JobRunning, 1, 10, now, "", now, now, "c1").
+		AddRow(1, domain.JobPurgeUsers, []byte(`{}`), "", domain.JobRunning, 2, 10, now.Add(-time.Minute), "boom", now, now, "c1")
+
+	query := "UPDATE jobs SET status = 'running', attempts = attempts \\+ 1, locked_until = .*, locked_by = \\$4 .* FOR UPDATE SKIP LOCKED\\)"
+	mock.ExpectQuery(query).WithArgs(float64(600), domain.JobPurgeUsers, int64(2), sqlmock.AnyArg()).WillReturnRows(rows)
+
+	r := jobPsqlRepo.NewPsqlJobRepository(db)
+
+	list, err := r.Claim(context.TODO(), domain.JobPurgeUsers, 2, 10*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(1), list[0].ID, "jobs are ordered by run_at")
+	assert.Equal(t, "boom", list[0].LastError)
+	assert.JSONEq(t, `{}`, string(list[1].Payload))
+	assert.Equal(t, "c1", list[0].LockedBy)
+}
+
+func TestFetchJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows(jobColumns).
+		AddRow(1, domain.JobPurgeUsers, []byte(`{}`), "", domain.JobDead, 10, 10, now, "boom", now, now, "")
+
+	query := "SELECT id, type, payload, COALESCE\\(key, ''\\), .* FROM jobs WHERE \\(\\$1 = '' OR status = \\$1\\) AND id > \\$2 ORDER BY id"
+	mock.ExpectQuery(query).WithArgs(domain.JobDead, int64(0), int64(1)).WillReturnRows(rows)
+
+	r := jobPsqlRepo.NewPsqlJobRepository(db)
+
+	list, nextCursor, err := r.Fetch(context.TODO(), domain.JobDead, "", 1)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, repository.EncodeIDCursor(1), nextCursor)
+
+	_, _, err = r.Fetch(context.TODO(), "", "not a cursor", 1)
+	assert.Equal(t, domain.ErrBadParamInput, err)
+}
+
+func TestUpdateJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	j := &domain.Job{ID: 1, Status: domain.JobPending, Attempts: 2, RunAt: now, LastError: "boom", UpdatedAt: now, LockedBy: "c1"}
+
+	query := "UPDATE jobs SET .*, locked_until=NULL, locked_by=NULL\\s+WHERE id=\\$6 AND locked_by IS NOT DISTINCT FROM NULLIF\\(\\$7, ''\\)"
+	mock.ExpectExec(query).WithArgs(j.Status, j.Attempts, j.RunAt, j.LastError, j.UpdatedAt, j.ID, "c1").WillReturnResult(sqlmock.NewResult(0, 1))
+	// the lease expired and the job was claimed again
+	mock.ExpectExec(query).WithArgs(j.Status, j.Attempts, j.RunAt, j.LastError, j.UpdatedAt, j.ID, "c2").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := jobPsqlRepo.NewPsqlJobRepository(db)
+
+	assert.NoError(t, r.Update(context.TODO(), j))
+	assert.Empty(t, j.LockedBy, "the job is released")
+
+	j.LockedBy = "c2"
+	assert.Equal(t, domain.ErrConflict, r.Update(context.TODO(), j))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
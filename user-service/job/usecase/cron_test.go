@@ -0,0 +1,46 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/job/usecase"
+)
+
+func TestCronNext(t *testing.T) {
+	// a wednesday
+	from := time.Date(2020, 1, 1, 10, 7, 30, 0, time.UTC)
+	cases := map[string]time.Time{
+		"*/15 * * * *":  time.Date(2020, 1, 1, 10, 15, 0, 0, time.UTC),
+		"@hourly":       time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC),
+		"@daily":        time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		"30 3 * * 1-5":  time.Date(2020, 1, 2, 3, 30, 0, 0, time.UTC),
+		"0 0 * * 7":     time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC),
+		"0 12 15 * 5":   time.Date(2020, 1, 3, 12, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":    time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		"5,10 9-10 * *": {},
+	}
+	for spec, want := range cases {
+		c, err := usecase.ParseCron(spec)
+		if want.IsZero() {
+			assert.Error(t, err, spec)
+			continue
+		}
+		require.NoError(t, err, spec)
+		assert.Equal(t, want, c.Next(from), spec)
+	}
+
+	c, err := usecase.ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, c.Next(from).IsZero(), "february 31 never comes")
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := usecase.ParseCron(spec)
+		assert.Error(t, err, spec)
+	}
+}
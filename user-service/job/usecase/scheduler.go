@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// Schedule enqueues a job of Type with Payload at every time matching Cron
+type Schedule struct {
+	Type    string
+	Payload interface{}
+	Cron    *Cron
+}
+
+// Scheduler enqueues the jobs of its schedules. Every instance of the service runs one, the jobs
+// are keyed by type and time so that a schedule is enqueued once whichever instance is first.
+type Scheduler struct {
+	jobs      domain.JobUsecase
+	schedules []Schedule
+}
+
+// NewScheduler will create a Scheduler enqueuing the jobs of schedules with jobs
+func NewScheduler(jobs domain.JobUsecase, schedules ...Schedule) *Scheduler {
+	return &Scheduler{jobs: jobs, schedules: schedules}
+}
+
+// Run will enqueue the scheduled jobs until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	log := logging.FromContext(ctx).WithField("worker", "job_scheduler")
+	next := make([]time.Time, len(s.schedules))
+	now := time.Now().UTC()
+	for i, sch := range s.schedules {
+		next[i] = sch.Cron.Next(now)
+	}
+
+	for {
+		var due time.Time
+		for _, at := range next {
+			if !at.IsZero() && (due.IsZero() || at.Before(due)) {
+				due = at
+			}
+		}
+		if due.IsZero() {
+			// no schedule ever matches
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(due)):
+		}
+
+		for i, sch := range s.schedules {
+			if next[i].IsZero() || next[i].After(due) {
+				continue
+			}
+			if err := s.enqueue(ctx, sch, next[i]); err != nil {
+				log.WithFields(logrus.Fields{"job_type": sch.Type, "scheduled_at": next[i]}).Error(err)
+			}
+			next[i] = sch.Cron.Next(next[i])
+		}
+	}
+}
+
+func (s *Scheduler) enqueue(ctx context.Context, sch Schedule, at time.Time) error {
+	j, err := domain.NewJob(sch.Type, sch.Payload)
+	if err != nil {
+		return err
+	}
+	j.Key = sch.Type + "@" + at.Format(time.RFC3339)
+	j.RunAt = at
+	return s.jobs.Enqueue(ctx, j)
+}
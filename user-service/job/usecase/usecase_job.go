@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// defaultMaxAttempts is the number of attempts of the jobs enqueued without MaxAttempts
+const defaultMaxAttempts = 10
+
+type jobUsecase struct {
+	jobRepo        domain.JobRepository
+	contextTimeout time.Duration
+}
+
+// NewJobUsecase will create new a jobUsecase object representation of domain.JobUsecase interface
+func NewJobUsecase(j domain.JobRepository, timeout time.Duration) domain.JobUsecase {
+	return &jobUsecase{
+		jobRepo:        j,
+		contextTimeout: timeout,
+	}
+}
+
+func (a *jobUsecase) Fetch(c context.Context, status string, cursor string, num int64) (res []domain.Job, nextCursor string, err error) {
+	if num == 0 {
+		num = 10
+	}
+
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	switch status {
+	case "", domain.JobPending, domain.JobRunning, domain.JobSucceeded, domain.JobDead:
+	default:
+		return nil, "", domain.ErrBadParamInput
+	}
+	return a.jobRepo.Fetch(ctx, status, cursor, num)
+}
+
+func (a *jobUsecase) GetByID(c context.Context, id int64) (res domain.Job, err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	return a.jobRepo.GetByID(ctx, id)
+}
+
+// Retry will run a dead job again right away, with all its attempts
+func (a *jobUsecase) Retry(c context.Context, id int64) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	j, err := a.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return
+	}
+	if j.Status != domain.JobDead {
+		return domain.ErrConflict
+	}
+
+	now := time.Now()
+	j.Status = domain.JobPending
+	j.Attempts = 0
+	j.RunAt = now
+	j.UpdatedAt = now
+	return a.jobRepo.Update(ctx, &j)
+}
+
+func (a *jobUsecase) Enqueue(c context.Context, j *domain.Job) error {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if j.Type == "" || !json.Valid(j.Payload) {
+		return domain.ErrBadParamInput
+	}
+
+	now := time.Now()
+	j.Status = domain.JobPending
+	j.Attempts = 0
+	if j.MaxAttempts <= 0 {
+		j.MaxAttempts = defaultMaxAttempts
+	}
+	if j.RunAt.IsZero() {
+		j.RunAt = now
+	}
+	j.CreatedAt = now
+	j.UpdatedAt = now
+	return a.jobRepo.Enqueue(ctx, j)
+}
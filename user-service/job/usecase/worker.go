@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// errOutOfAttempts is the error of the jobs claimed again after their worker died on the last attempt
+var errOutOfAttempts = errors.New("job ran out of attempts")
+
+// Handle adapts fn to a domain.JobHandler decoding the payload of the job into a T.
+// A payload that does not decode is not retried.
+func Handle[T any](fn func(ctx context.Context, payload T) error) domain.JobHandler {
+	return func(ctx context.Context, j domain.Job) error {
+		var payload T
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+		}
+		return fn(ctx, payload)
+	}
+}
+
+// WorkerConfig configures the job worker
+type WorkerConfig struct {
+	// Interval is the wait between polls when no job is due
+	Interval time.Duration
+	// Lease is how long claimed jobs are hidden from other workers, it must outlast the timeout of every handler
+	Lease time.Duration
+	// Backoff is the wait after the first failed attempt, doubled on every following one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// HandlerConfig configures how a worker runs the jobs of a type
+type HandlerConfig struct {
+	// Concurrency is the number of jobs of the type run at once by the worker, 1 when unset
+	Concurrency int
+	// Timeout bounds a run, the Lease of the worker when unset
+	Timeout time.Duration
+}
+
+type handler struct {
+	fn      domain.JobHandler
+	timeout time.Duration
+	// slots holds a token per running job of the type
+	slots chan struct{}
+}
+
+// Worker runs the due jobs of the types it has a handler for
+type Worker struct {
+	jobRepo  domain.JobRepository
+	cfg      WorkerConfig
+	types    []string
+	handlers map[string]*handler
+	running  sync.WaitGroup
+}
+
+// NewWorker will create a Worker claiming jobs from jobRepo
+func NewWorker(jobRepo domain.JobRepository, cfg WorkerConfig) *Worker {
+	return &Worker{jobRepo: jobRepo, cfg: cfg, handlers: make(map[string]*handler)}
+}
+
+// Register will run the jobs of jobType with fn, it must be called before Run
+func (w *Worker) Register(jobType string, fn domain.JobHandler, cfg HandlerConfig) {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Timeout <= 0 || cfg.Timeout > w.cfg.Lease {
+		cfg.Timeout = w.cfg.Lease
+	}
+	if _, ok := w.handlers[jobType]; !ok {
+		w.types = append(w.types, jobType)
+		sort.Strings(w.types)
+	}
+	w.handlers[jobType] = &handler{fn: fn, timeout: cfg.Timeout, slots: make(chan struct{}, cfg.Concurrency)}
+}
+
+// Run will run jobs until ctx is done, then wait for the running ones. Running jobs are not
+// cancelled with ctx, they finish within the timeout of their handler.
+func (w *Worker) Run(ctx context.Context) {
+	log := logging.FromContext(ctx).WithField("worker", "jobs")
+	for {
+		n, err := w.RunOnce(ctx)
+		if err != nil {
+			log.Error(err)
+		}
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			w.running.Wait()
+			return
+		case <-time.After(w.cfg.Interval):
+		}
+	}
+}
+
+// RunOnce will start the due jobs of every type with a free slot, returning how many were started
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	started := 0
+	for _, jobType := range w.types {
+		h := w.handlers[jobType]
+		free := cap(h.slots) - len(h.slots)
+		if free == 0 {
+			continue
+		}
+
+		jobs, err := w.jobRepo.Claim(ctx, jobType, int64(free), w.cfg.Lease)
+		if err != nil {
+			return started, err
+		}
+		for _, j := range jobs {
+			h.slots <- struct{}{}
+			w.running.Add(1)
+			go func(j domain.Job) {
+				defer w.running.Done()
+				defer func() { <-h.slots }()
+				w.run(context.WithoutCancel(ctx), h, j)
+			}(j)
+			started++
+		}
+	}
+	return started, nil
+}
+
+// Wait will block until the running jobs are done
+func (w *Worker) Wait() {
+	w.running.Wait()
+}
+
+// run will run the job once and save the outcome
+func (w *Worker) run(ctx context.Context, h *handler, j domain.Job) {
+	log := logging.FromContext(ctx).WithFields(logrus.Fields{"job_id": j.ID, "job_type": j.Type, "attempts": j.Attempts})
+
+	err := errOutOfAttempts
+	if j.Attempts <= j.MaxAttempts {
+		err = w.call(ctx, h, j)
+	}
+
+	now := time.Now()
+	j.UpdatedAt = now
+	switch {
+	case err == nil:
+		j.Status = domain.JobSucceeded
+		j.LastError = ""
+	case errors.Is(err, domain.ErrBadParamInput) || j.Attempts >= j.MaxAttempts:
+		j.Status = domain.JobDead
+		j.LastError = err.Error()
+		log.WithError(err).Warn("job dead-lettered")
+	default:
+		j.Status = domain.JobPending
+		j.LastError = err.Error()
+		j.RunAt = now.Add(w.backoff(j.Attempts))
+		log.WithError(err).Info("job failed, retrying")
+	}
+
+	err = w.jobRepo.Update(ctx, &j)
+	switch {
+	case errors.Is(err, domain.ErrConflict):
+		log.Warn("job lease lost, the outcome of the run is dropped")
+	case err != nil:
+		log.Error(err)
+	}
+}
+
+// call will run the handler within its timeout, a panic fails the attempt instead of the worker
+func (w *Worker) call(c context.Context, h *handler, j domain.Job) (err error) {
+	ctx, cancel := context.WithTimeout(c, h.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return h.fn(ctx, j)
+}
+
+func (w *Worker) backoff(attempts int64) time.Duration {
+	shift := attempts - 1
+	if shift > 30 {
+		shift = 30
+	}
+	b := w.cfg.Backoff << uint(shift)
+	if b <= 0 || b > w.cfg.MaxBackoff {
+		return w.cfg.MaxBackoff
+	}
+	return b
+}
@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/job/usecase"
+)
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func TestRunOnce(t *testing.T) {
+	cfg := usecase.WorkerConfig{Lease: time.Minute, Backoff: time.Minute, MaxBackoff: time.Hour}
+	newJob := func(payload string, attempts int64) domain.Job {
+		return domain.Job{ID: 1, Type: "greet", Payload: []byte(payload), Status: domain.JobRunning, Attempts: attempts, MaxAttempts: 3}
+	}
+	cases := []struct {
+		name    string
+		job     domain.Job
+		err     error
+		status  string
+		message string
+	}{
+		{name: "succeeded", job: newJob(`{"name":"dias"}`, 1), status: domain.JobSucceeded},
+		{name: "retry", job: newJob(`{"name":"dias"}`, 2), err: errors.New("smtp is down"), status: domain.JobPending, message: "smtp is down"},
+		{name: "last attempt", job: newJob(`{"name":"dias"}`, 3), err: errors.New("smtp is down"), status: domain.JobDead, message: "smtp is down"},
+		{name: "bad payload", job: newJob(`{"name":1}`, 1), status: domain.JobDead, message: domain.ErrBadParamInput.Error()},
+		{name: "out of attempts", job: newJob(`{"name":"dias"}`, 4), status: domain.JobDead, message: "job ran out of attempts"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			var updated domain.Job
+			mockJobRepo := new(mocks.JobRepository)
+			mockJobRepo.On("Claim", mock.Anything, "greet", int64(1), time.Minute).Return([]domain.Job{tc.job}, nil).Once()
+			mockJobRepo.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				updated = *args.Get(1).(*domain.Job)
+			}).Return(nil).Once()
+
+			w := usecase.NewWorker(mockJobRepo, cfg)
+			w.Register("greet", usecase.Handle(func(ctx context.Context, g greeting) error {
+				got = append(got, g.Name)
+				return tc.err
+			}), usecase.HandlerConfig{})
+
+			n, err := w.RunOnce(context.TODO())
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			w.Wait()
+
+			assert.Equal(t, tc.status, updated.Status)
+			assert.Contains(t, updated.LastError, tc.message)
+			if tc.status == domain.JobPending {
+				assert.WithinDuration(t, time.Now().Add(2*time.Minute), updated.RunAt, 5*time.Second, "the backoff doubles")
+			}
+			if tc.status == domain.JobSucceeded {
+				assert.Equal(t, []string{"dias"}, got)
+			}
+			mockJobRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRunOncePanic(t *testing.T) {
+	var updated domain.Job
+	mockJobRepo := new(mocks.JobRepository)
+	mockJobRepo.On("Claim", mock.Anything, "greet", int64(1), time.Minute).
+		Return([]domain.Job{{ID: 1, Type: "greet", Payload: []byte(`{}`), Attempts: 1, MaxAttempts: 3}}, nil)
+	mockJobRepo.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		updated = *args.Get(1).(*domain.Job)
+	}).Return(nil)
+
+	w := usecase.NewWorker(mockJobRepo, usecase.WorkerConfig{Lease: time.Minute, Backoff: time.Minute, MaxBackoff: time.Hour})
+	w.Register("greet", func(ctx context.Context, j domain.Job) error {
+		panic("nil map")
+	}, usecase.HandlerConfig{})
+
+	_, err := w.RunOnce(context.TODO())
+	require.NoError(t, err)
+	w.Wait()
+	assert.Equal(t, domain.JobPending, updated.Status)
+	assert.Equal(t, "job panicked: nil map", updated.LastError)
+}
+
+func TestConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	running := 0
+
+	jobs := []domain.Job{{ID: 1, Type: "slow", Payload: []byte(`{}`), Attempts: 1, MaxAttempts: 3}, {ID: 2, Type: "slow", Payload: []byte(`{}`), Attempts: 1, MaxAttempts: 3}}
+	mockJobRepo := new(mocks.JobRepository)
+	mockJobRepo.On("Claim", mock.Anything, "slow", int64(2), time.Minute).Return(jobs, nil).Once()
+	mockJobRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	w := usecase.NewWorker(mockJobRepo, usecase.WorkerConfig{Lease: time.Minute})
+	w.Register("slow", func(ctx context.Context, j domain.Job) error {
+		mu.Lock()
+		running++
+		mu.Unlock()
+		<-release
+		return nil
+	}, usecase.HandlerConfig{Concurrency: 2})
+
+	n, err := w.RunOnce(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// both slots are taken, nothing is claimed until a job is done
+	n, err = w.RunOnce(context.TODO())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	close(release)
+	w.Wait()
+	assert.Equal(t, 2, running)
+	mockJobRepo.AssertExpectations(t)
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+type logMailer struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewLogMailer will create a domain.Mailer for local development, it writes every email to out
+// and logs its recipient and subject instead of delivering it
+func NewLogMailer(out io.Writer) domain.Mailer {
+	return &logMailer{out: out}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg domain.Message) error {
+	logging.FromContext(ctx).WithField("to", msg.To).Infof("mail sent: %s", msg.Subject)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.out, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+	return err
+}
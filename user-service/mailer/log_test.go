@@ -0,0 +1,23 @@
+package mailer_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/mailer"
+)
+
+func TestLogMailerSend(t *testing.T) {
+	var out bytes.Buffer
+	m := mailer.NewLogMailer(&out)
+
+	err := m.Send(context.TODO(), domain.Message{To: "dias@gmail.com", Subject: "Hello", Body: "World"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "To: dias@gmail.com")
+	assert.Contains(t, out.String(), "Subject: Hello")
+	assert.Contains(t, out.String(), "World")
+}
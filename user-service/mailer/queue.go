@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type queueMailer struct {
+	jobs domain.JobUsecase
+}
+
+// NewQueueMailer will create a domain.Mailer that enqueues every email as a domain.JobSendMail job
+// instead of delivering it. The job is enqueued in the transaction of ctx, and the worker delivers it
+// with the mailer registered for the type, so callers do not wait on the mail server. The job payloads
+// are kept and shown to admins, emails carrying a secret link must not be sent with it.
+func NewQueueMailer(jobs domain.JobUsecase) domain.Mailer {
+	return &queueMailer{jobs}
+}
+
+func (m *queueMailer) Send(ctx context.Context, msg domain.Message) error {
+	j, err := domain.NewJob(domain.JobSendMail, msg)
+	if err != nil {
+		return err
+	}
+	return m.jobs.Enqueue(ctx, j)
+}
@@ -0,0 +1,29 @@
+package mailer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/mailer"
+)
+
+func TestQueueMailerSend(t *testing.T) {
+	mockJobUCase := new(mocks.JobUsecase)
+	mockJobUCase.On("Enqueue", mock.Anything, mock.MatchedBy(func(j *domain.Job) bool {
+		return j.Type == domain.JobSendMail
+	})).Return(nil).Once()
+
+	m := mailer.NewQueueMailer(mockJobUCase)
+
+	err := m.Send(context.TODO(), domain.Message{To: "dias@gmail.com", Subject: "Hello", Body: "World"})
+	assert.NoError(t, err)
+
+	j := mockJobUCase.Calls[0].Arguments.Get(1).(*domain.Job)
+	assert.JSONEq(t, `{"to":"dias@gmail.com","subject":"Hello","body":"World"}`, string(j.Payload))
+	mockJobUCase.AssertExpectations(t)
+}
@@ -1,56 +1,374 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/XSAM/otelsql"
+	_articleRepo "github.com/diantanjung/blogo/user-service/article/repository/http"
+	"github.com/diantanjung/blogo/user-service/blob"
+	"github.com/diantanjung/blogo/user-service/config"
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/events"
+	_jobHttpDelivery "github.com/diantanjung/blogo/user-service/job/delivery/http"
+	_jobRepo "github.com/diantanjung/blogo/user-service/job/repository/psql"
+	_jobUcase "github.com/diantanjung/blogo/user-service/job/usecase"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/tracing"
+	_userGraphqlDelivery "github.com/diantanjung/blogo/user-service/user/delivery/graphql"
+	_userGrpcDelivery "github.com/diantanjung/blogo/user-service/user/delivery/grpc"
 	_userHttpDelivery "github.com/diantanjung/blogo/user-service/user/delivery/http"
 	_userMiddleware "github.com/diantanjung/blogo/user-service/user/delivery/http/middleware"
+	_userRepository "github.com/diantanjung/blogo/user-service/user/repository"
+	_userMemoryRepo "github.com/diantanjung/blogo/user-service/user/repository/memory"
 	_userRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
 	_userUcase "github.com/diantanjung/blogo/user-service/user/usecase"
-	"github.com/joho/godotenv"
+	_webhookHttpDelivery "github.com/diantanjung/blogo/user-service/webhook/delivery/http"
+	_webhookRepo "github.com/diantanjung/blogo/user-service/webhook/repository/psql"
+	_webhookUcase "github.com/diantanjung/blogo/user-service/webhook/usecase"
 	"github.com/labstack/echo"
 	_ "github.com/lib/pq"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	err := godotenv.Load()
+	err := config.Load()
 	if err != nil {
 		log.Fatalf("Error getting env, %v", err)
 	} else {
 		fmt.Println("We are getting the env values")
 	}
 
-	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s "+
-		"password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_NAME"), os.Getenv("DB_PASSWORD"))
-	db, err := sql.Open("postgres", psqlInfo)
+	err = logging.Configure(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	shutdownTracing, err := tracing.Init(context.Background(), "user-service", os.Getenv("TRACE_EXPORTER"), os.Getenv("TRACE_FILE"))
 	if err != nil {
 		log.Fatal(err)
 	}
-	err = dbConn.Ping()
+	defer func() {
+		err := shutdownTracing(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}()
+	logrus.AddHook(tracing.NewLogrusHook())
+
+	db, err := otelsql.Open("postgres", config.PostgresDSN(), otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = db.Ping()
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	defer func() {
-		err := dbConn.Close()
+		err := db.Close()
 		if err != nil {
 			log.Fatal(err)
 		}
 	}()
 
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, os.Getenv("DB_NAME")))
+
 	e := echo.New()
-	middL := _userMiddleware.InitMiddleware()
+	trustedProxies, err := _userMiddleware.ParseTrustedProxies(config.List("TRUSTED_PROXIES", nil))
+	if err != nil {
+		log.Fatal(err)
+	}
+	middL := _userMiddleware.InitMiddleware(_userMiddleware.Config{
+		CORS: _userMiddleware.CORSConfig{
+			AllowOrigins:     config.List("CORS_ALLOW_ORIGINS", nil),
+			AllowMethods:     config.List("CORS_ALLOW_METHODS", nil),
+			AllowHeaders:     config.List("CORS_ALLOW_HEADERS", []string{echo.HeaderAuthorization, echo.HeaderContentType, _userMiddleware.HeaderXRequestID, _userMiddleware.HeaderXAPIKey}),
+			ExposeHeaders:    config.List("CORS_EXPOSE_HEADERS", []string{"X-Cursor", _userMiddleware.HeaderXRequestID, "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"}),
+			AllowCredentials: config.Bool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           config.Duration("CORS_MAX_AGE", 10*time.Minute),
+		},
+		Security: _userMiddleware.SecurityConfig{
+			HSTSMaxAge:            config.Duration("HSTS_MAX_AGE", 0),
+			ContentSecurityPolicy: os.Getenv("CONTENT_SECURITY_POLICY"),
+			BodyLimit:             config.Int("BODY_LIMIT", 1<<20),
+			RawBodyRoutes:         []string{_userHttpDelivery.CustomMethodsPath, _userHttpDelivery.AvatarPath},
+			RawBodyLimit:          config.Int("IMPORT_BODY_LIMIT", 64<<20),
+		},
+		TrustedProxies: trustedProxies,
+	})
+	e.Use(middL.RequestID)
+	e.Use(middL.Tracing)
+	e.Use(middL.AccessLog)
+	e.Use(middL.Metrics)
+	e.Use(middL.Recover)
+	e.Use(middL.SecureHeaders)
 	e.Use(middL.CORS)
+	e.Use(middL.BodyLimit)
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+
+	timeout := config.Duration("CONTEXT_TIMEOUT", 5*time.Second)
+
+	repo := _userRepository.NewTracingUserRepository(_userRepo.NewPsqlUserRepository(db))
+	roleRepo := _userRepo.NewPsqlRoleRepository(db)
+	sessionRepo := _userRepo.NewPsqlSessionRepository(db)
 
-	repo := _userRepo.NewPsqlUserRepository(db)
-	us := _userUcase.NewUserUsecase(repo)
+	resetRepo := _userRepo.NewPsqlPasswordResetRepository(db)
+	historyRepo := _userRepo.NewPsqlPasswordHistoryRepository(db)
+	txManager := _userRepo.NewPsqlTxManager(db, sql.LevelSerializable, int(config.Int("TX_RETRIES", 3)))
+	var attempts domain.LoginAttemptStore
+	switch os.Getenv("LOGIN_ATTEMPT_STORE") {
+	case "", "postgres":
+		attempts = _userRepo.NewPsqlLoginAttemptStore(db)
+	case "memory":
+		attempts = _userMemoryRepo.NewMemoryLoginAttemptStore()
+	default:
+		log.Fatalf("invalid LOGIN_ATTEMPT_STORE %q", os.Getenv("LOGIN_ATTEMPT_STORE"))
+	}
+	jobRepo := _jobRepo.NewPsqlJobRepository(db)
+	ju := _jobUcase.NewJobUsecase(jobRepo, timeout)
+	// emails are enqueued as jobs and delivered with mail by the job worker
+	mail := config.Mailer()
+	authConfig := config.AuthConfig(ju, mail)
+	userConfig := config.UserConfig(ju, mail, config.BlobStore())
+	if local, ok := userConfig.Avatar.Store.(*blob.LocalStore); ok {
+		// BLOB_BASE_URL must point at this route when the service serves the blobs itself
+		e.GET("/blobs/*", echo.WrapHandler(http.StripPrefix("/blobs", local)))
+	}
+
+	au := _userUcase.NewAuthUsecase(repo, roleRepo, sessionRepo, resetRepo, historyRepo, attempts, txManager, authConfig, timeout)
+
+	rateLimits, err := _userMiddleware.ParseRateLimitPolicies(config.String("RATE_LIMITS", "*=ip:300/1m;POST /users=ip:5/1m;POST /auth/login=ip:20/1m;POST /users/verify/resend=ip:5/1m"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// a bucket idle for longer than its period is full, it is evicted once idle for RATE_LIMIT_IDLE_TTL
+	rateLimitIdleTTL := config.Duration("RATE_LIMIT_IDLE_TTL", time.Hour)
+	for _, p := range rateLimits {
+		if p.Limit.Period > rateLimitIdleTTL {
+			log.Fatalf("RATE_LIMIT_IDLE_TTL %s is shorter than the %s period of the rate limit of %s %s", rateLimitIdleTTL, p.Limit.Period, p.Method, p.Route)
+		}
+	}
+	var rateLimitStore domain.RateLimitStore
+	switch os.Getenv("RATE_LIMIT_STORE") {
+	case "", "memory":
+		rateLimitStore = _userMemoryRepo.NewMemoryRateLimitStore(rateLimitIdleTTL)
+	case "postgres":
+		rateLimitStore = _userRepo.NewPsqlRateLimitStore(db)
+	default:
+		log.Fatalf("invalid RATE_LIMIT_STORE %q", os.Getenv("RATE_LIMIT_STORE"))
+	}
+	// the IP and API key limits turn floods away before their tokens are checked, the user ones need the user
+	beforeAuth, afterAuth := _userMiddleware.SplitRateLimitPolicies(rateLimits)
+	e.Use(middL.RateLimit(rateLimitStore, beforeAuth))
+	e.Use(middL.Authenticate(au))
+	e.Use(middL.RateLimit(rateLimitStore, afterAuth))
+	e.Use(middL.RequireJSON("/users", "/webhooks", "/jobs", "/graphql"))
+
+	auditRepo := _userRepo.NewPsqlAuditRepository(db)
+	outboxRepo := _userRepo.NewPsqlOutboxRepository(db)
+	us := _userUcase.NewUserUsecase(repo, roleRepo, _userRepo.NewPsqlProfileRepository(db), sessionRepo, historyRepo, auditRepo, outboxRepo, txManager, userConfig, timeout)
+	us = _userUcase.NewTracingUserUsecase(us)
+	us = _userUcase.NewMetricsUserUsecase(us)
+
+	webhookRepo := _webhookRepo.NewPsqlWebhookRepository(db)
+	deliveryRepo := _webhookRepo.NewPsqlWebhookDeliveryRepository(db)
+	wu := _webhookUcase.NewWebhookUsecase(webhookRepo, deliveryRepo, timeout)
+	webhooks := events.NewInProcessPublisher()
+	webhooks.Subscribe("*", wu.Enqueue)
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+	publisher := newEventPublisher()
+	relay := events.NewRelay(outboxRepo, events.NewMultiPublisher(publisher, webhooks), events.RelayConfig{
+		Batch:       config.Int("OUTBOX_BATCH", 100),
+		Interval:    config.Duration("OUTBOX_INTERVAL", time.Second),
+		Lease:       config.Duration("OUTBOX_LEASE", time.Minute),
+		Backoff:     config.Duration("OUTBOX_BACKOFF", 10*time.Second),
+		MaxBackoff:  config.Duration("OUTBOX_MAX_BACKOFF", time.Hour),
+		MaxAttempts: config.Int("OUTBOX_MAX_ATTEMPTS", 20),
+	})
+	runWorker(relay.Run)
+
+	dispatcher := _webhookUcase.NewDispatcher(webhookRepo, deliveryRepo, &http.Client{Timeout: config.Duration("WEBHOOK_TIMEOUT", 10*time.Second)}, _webhookUcase.DispatcherConfig{
+		Batch:       config.Int("WEBHOOK_BATCH", 50),
+		Interval:    config.Duration("WEBHOOK_INTERVAL", time.Second),
+		Lease:       config.Duration("WEBHOOK_LEASE", 5*time.Minute),
+		Backoff:     config.Duration("WEBHOOK_BACKOFF", 30*time.Second),
+		MaxBackoff:  config.Duration("WEBHOOK_MAX_BACKOFF", 6*time.Hour),
+		MaxAttempts: config.Int("WEBHOOK_MAX_ATTEMPTS", 10),
+	})
+	runWorker(dispatcher.Run)
+
+	worker := _jobUcase.NewWorker(jobRepo, _jobUcase.WorkerConfig{
+		Interval:   config.Duration("JOB_INTERVAL", time.Second),
+		Lease:      config.Duration("JOB_LEASE", 10*time.Minute),
+		Backoff:    config.Duration("JOB_BACKOFF", 30*time.Second),
+		MaxBackoff: config.Duration("JOB_MAX_BACKOFF", time.Hour),
+	})
+	worker.Register(domain.JobPurgeUsers, _jobUcase.Handle(_userUcase.NewPurgeUsersHandler(repo)), _jobUcase.HandlerConfig{})
+	mailHandlerConfig := _jobUcase.HandlerConfig{
+		Concurrency: int(config.Int("MAIL_CONCURRENCY", 4)),
+		Timeout:     config.Duration("MAIL_TIMEOUT", 30*time.Second),
+	}
+	worker.Register(domain.JobSendMail, _jobUcase.Handle(mail.Send), mailHandlerConfig)
+	worker.Register(domain.JobSendPasswordReset, _jobUcase.Handle(_userUcase.NewSendPasswordResetHandler(repo, resetRepo, authConfig)), mailHandlerConfig)
+	worker.Register(domain.JobSendVerification, _jobUcase.Handle(_userUcase.NewSendVerificationHandler(repo, userConfig.Verification)), mailHandlerConfig)
+	worker.Register(domain.JobPruneRateLimits, _jobUcase.Handle(_userUcase.NewPruneRateLimitsHandler(rateLimitStore)), _jobUcase.HandlerConfig{})
+	runWorker(worker.Run)
+
+	purgeCron, err := _jobUcase.ParseCron(config.String("USER_PURGE_SCHEDULE", "@daily"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pruneCron, err := _jobUcase.ParseCron(config.String("RATE_LIMIT_PRUNE_SCHEDULE", "@hourly"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	scheduler := _jobUcase.NewScheduler(ju, _jobUcase.Schedule{
+		Type:    domain.JobPurgeUsers,
+		Payload: domain.PurgeUsersJob{Retention: config.Duration("USER_PURGE_RETENTION", 30*24*time.Hour).String()},
+		Cron:    purgeCron,
+	}, _jobUcase.Schedule{
+		Type:    domain.JobPruneRateLimits,
+		Payload: domain.PruneRateLimitsJob{IdleFor: rateLimitIdleTTL.String()},
+		Cron:    pruneCron,
+	})
+	runWorker(scheduler.Run)
 
 	_userHttpDelivery.NewUsersHandler(e, us)
+	_userHttpDelivery.NewAuthHandler(e, au)
+	_userHttpDelivery.NewOpenAPIHandler(e)
+	_webhookHttpDelivery.NewWebhooksHandler(e, wu)
+	_jobHttpDelivery.NewJobsHandler(e, ju)
+	// the article fields of the GraphQL endpoint read the article service, they resolve empty without it
+	var articleRepo domain.ArticleRepository
+	if url := os.Getenv("ARTICLE_SERVICE_URL"); url != "" {
+		articleRepo = _articleRepo.NewHTTPArticleRepository(url, &http.Client{Timeout: config.Duration("ARTICLE_SERVICE_TIMEOUT", 5*time.Second)})
+	}
+	err = _userGraphqlDelivery.NewGraphQLHandler(e, us, articleRepo, _userGraphqlDelivery.Config{
+		MaxDepth:      int(config.Int("GRAPHQL_MAX_DEPTH", 8)),
+		MaxComplexity: int(config.Int("GRAPHQL_MAX_COMPLEXITY", 1000)),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		_userGrpcDelivery.Logging,
+		_userGrpcDelivery.Recover,
+		_userGrpcDelivery.Authenticate(au),
+	))
+	_userGrpcDelivery.NewUserServer(grpcServer, us)
+	lis, err := net.Listen("tcp", config.String("GRPC_PORT", ":9091"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	go func() {
+		if err := e.Start(os.Getenv("SERVER_PORT")); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-sigCtx.Done()
+	logrus.Info("shutting down")
+
+	// in-flight requests and running jobs get SHUTDOWN_TIMEOUT to finish, then the process exits anyway
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Duration("SHUTDOWN_TIMEOUT", 30*time.Second))
+	defer cancel()
+	if err = e.Shutdown(shutdownCtx); err != nil {
+		logrus.Error(err)
+	}
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
 
-	log.Fatal(e.Start(os.Getenv("SERVER_PORT")))
+	stopWorkers()
+	workersStopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersStopped)
+	}()
+	select {
+	case <-workersStopped:
+	case <-shutdownCtx.Done():
+		logrus.Warn("workers did not stop in time")
+	}
+	// the kafka publisher holds broker connections, closed only once the relay stopped using them
+	if c, ok := publisher.(io.Closer); ok {
+		if err = c.Close(); err != nil {
+			logrus.Error(err)
+		}
+	}
+}
+
+// newEventPublisher will create the domain.EventPublisher selected by EVENT_PUBLISHER,
+// inprocess, webhook, nats or kafka
+func newEventPublisher() domain.EventPublisher {
+	switch os.Getenv("EVENT_PUBLISHER") {
+	case "", "inprocess":
+		p := events.NewInProcessPublisher()
+		p.Subscribe("*", func(ctx context.Context, e domain.Event) error {
+			logging.FromContext(ctx).WithField("event_id", e.ID).Infof("event published: %s", e.Type)
+			return nil
+		})
+		return p
+	case "webhook":
+		return events.NewWebhookPublisher(os.Getenv("EVENT_WEBHOOK_URL"), &http.Client{Timeout: config.Duration("EVENT_WEBHOOK_TIMEOUT", 10*time.Second)})
+	case "nats":
+		nc, err := nats.Connect(os.Getenv("NATS_URL"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		p, err := events.NewNATSPublisher(nc, config.String("NATS_SUBJECT_PREFIX", "blogo.users"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return p
+	case "kafka":
+		return events.NewKafkaPublisher(config.List("KAFKA_BROKERS", nil), config.String("KAFKA_TOPIC", "blogo.users"))
+	default:
+		log.Fatalf("invalid EVENT_PUBLISHER %q", os.Getenv("EVENT_PUBLISHER"))
+		return nil
+	}
 }
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role    VARCHAR(32) NOT NULL CHECK (role IN ('admin', 'author', 'reader')),
+    PRIMARY KEY (user_id, role)
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id         VARCHAR(64) PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
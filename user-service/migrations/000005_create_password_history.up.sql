@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS password_history (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    hash       VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS password_history_user_id_created_at_idx ON password_history (user_id, created_at DESC);
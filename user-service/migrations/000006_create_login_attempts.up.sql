@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key             VARCHAR(320) PRIMARY KEY,
+    failures        BIGINT       NOT NULL,
+    last_failure_at TIMESTAMPTZ  NOT NULL
+);
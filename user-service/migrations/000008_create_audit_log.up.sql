@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id             BIGSERIAL    PRIMARY KEY,
+    actor_id       BIGINT,
+    action         VARCHAR(64)  NOT NULL,
+    target_user_id BIGINT       NOT NULL,
+    diff           JSONB        NOT NULL,
+    ip             VARCHAR(45)  NOT NULL DEFAULT '',
+    request_id     VARCHAR(128) NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_target_user_id_created_at_idx ON audit_log (target_user_id, created_at);
+
+-- the audit log is append-only
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
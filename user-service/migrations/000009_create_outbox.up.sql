@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id           VARCHAR(64)  PRIMARY KEY,
+    seq          BIGSERIAL    NOT NULL,
+    type         VARCHAR(64)  NOT NULL,
+    aggregate_id BIGINT       NOT NULL,
+    payload      JSONB        NOT NULL,
+    occurred_at  TIMESTAMPTZ  NOT NULL,
+    attempts     INT          NOT NULL DEFAULT 0,
+    last_error   TEXT         NOT NULL DEFAULT '',
+    locked_until TIMESTAMPTZ,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (seq) WHERE published_at IS NULL;
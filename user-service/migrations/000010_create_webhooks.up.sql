@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id         BIGSERIAL     PRIMARY KEY,
+    url        VARCHAR(2048) NOT NULL,
+    events     TEXT[]        NOT NULL,
+    secret     VARCHAR(128)  NOT NULL,
+    active     BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ   NOT NULL,
+    updated_at TIMESTAMPTZ   NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               BIGSERIAL    PRIMARY KEY,
+    webhook_id       BIGINT       NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id         VARCHAR(64)  NOT NULL,
+    event_type       VARCHAR(64)  NOT NULL,
+    payload          JSONB        NOT NULL,
+    status           VARCHAR(16)  NOT NULL,
+    attempts         BIGINT       NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ  NOT NULL,
+    locked_until     TIMESTAMPTZ,
+    last_status_code INT          NOT NULL DEFAULT 0,
+    last_error       TEXT         NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ  NOT NULL,
+    updated_at       TIMESTAMPTZ  NOT NULL,
+    UNIQUE (webhook_id, event_type, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_created_at_idx ON webhook_deliveries (webhook_id, created_at);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id           BIGSERIAL    PRIMARY KEY,
+    type         VARCHAR(64)  NOT NULL,
+    payload      JSONB        NOT NULL,
+    key          VARCHAR(255),
+    status       VARCHAR(16)  NOT NULL,
+    attempts     BIGINT       NOT NULL DEFAULT 0,
+    max_attempts BIGINT       NOT NULL,
+    run_at       TIMESTAMPTZ  NOT NULL,
+    locked_until TIMESTAMPTZ,
+    last_error   TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ  NOT NULL,
+    updated_at   TIMESTAMPTZ  NOT NULL,
+    UNIQUE (key)
+);
+
+CREATE INDEX IF NOT EXISTS jobs_due_idx ON jobs (type, run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS jobs_status_created_at_idx ON jobs (status, created_at);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS profiles (
+    user_id     BIGINT        PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    bio         TEXT          NOT NULL DEFAULT '',
+    avatar_url  VARCHAR(2048) NOT NULL DEFAULT '',
+    website     VARCHAR(2048) NOT NULL DEFAULT '',
+    links       JSONB         NOT NULL DEFAULT '[]',
+    preferences JSONB         NOT NULL DEFAULT '{}',
+    updated_at  TIMESTAMPTZ   NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS avatars JSONB NOT NULL DEFAULT '[]';
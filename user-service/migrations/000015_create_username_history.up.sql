@@ -0,0 +1,10 @@
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_lower_idx ON users (lower(username));
+
+CREATE TABLE IF NOT EXISTS username_history (
+    user_id    BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    username   VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMPTZ  NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS username_history_username_idx ON username_history (lower(username));
+CREATE INDEX IF NOT EXISTS username_history_user_id_idx ON username_history (user_id);
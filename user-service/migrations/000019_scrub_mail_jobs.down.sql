@@ -0,0 +1,2 @@
+-- the emptied payloads cannot be restored
+SELECT 1;
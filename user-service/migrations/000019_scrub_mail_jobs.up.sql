@@ -0,0 +1,2 @@
+-- the reset and verification emails used to be enqueued whole, with their link, the finished ones are emptied
+UPDATE jobs SET payload = '{}' WHERE type = 'mail.send' AND status IN ('succeeded', 'dead');
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS audit_log_target_user_id_id_idx;
+CREATE INDEX IF NOT EXISTS audit_log_target_user_id_created_at_idx ON audit_log (target_user_id, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS jobs_status_id_idx;
+CREATE INDEX IF NOT EXISTS jobs_status_created_at_idx ON jobs (status, created_at);
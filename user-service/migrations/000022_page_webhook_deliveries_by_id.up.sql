@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS webhook_deliveries_webhook_id_created_at_idx;
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_id_idx ON webhook_deliveries (webhook_id, id);
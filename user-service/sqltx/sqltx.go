@@ -0,0 +1,37 @@
+// Package sqltx carries the database transaction of a domain.TxManager in the context, so that the
+// repositories of every module take part in it
+package sqltx
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// Querier is implemented by both *sql.DB and *sql.Tx
+type Querier interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// NewContext returns a copy of ctx carrying tx
+func NewContext(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// FromContext returns the transaction carried by ctx, if any
+func FromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// Conn returns the transaction carried by ctx, or db outside of one
+func Conn(ctx context.Context, db *sql.DB) Querier {
+	if tx, ok := FromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogrusHook adds the trace and span ID of the entry context to every log line
+type LogrusHook struct{}
+
+// NewLogrusHook will create a logrus.Hook injecting trace IDs
+func NewLogrusHook() *LogrusHook {
+	return &LogrusHook{}
+}
+
+// Levels returns all the levels, trace IDs are useful on every line
+func (h *LogrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds trace_id and span_id fields when the entry carries a sampled span
+func (h *LogrusHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(entry.Context)
+	if !sc.IsValid() {
+		return nil
+	}
+	entry.Data["trace_id"] = sc.TraceID().String()
+	entry.Data["span_id"] = sc.SpanID().String()
+	return nil
+}
@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/user/delivery/http/middleware"
+)
+
+// Config bounds the queries the GraphQL endpoint executes, 0 disables a limit
+type Config struct {
+	MaxDepth      int
+	MaxComplexity int
+	// BatchSize is the number of users, articles or authors loaded by one call
+	BatchSize int
+}
+
+// GraphQLHandler represent the httphandler for the GraphQL endpoint
+type GraphQLHandler struct {
+	UserUsecase domain.UserUsecase
+	ArticleRepo domain.ArticleRepository
+	schema      graphql.Schema
+	cfg         Config
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewGraphQLHandler will initialize the /graphql endpoint. The articles are read from ar, they resolve
+// empty when ar is nil because no article service is deployed.
+func NewGraphQLHandler(e *echo.Echo, us domain.UserUsecase, ar domain.ArticleRepository, cfg Config) error {
+	schema, err := newSchema(us)
+	if err != nil {
+		return err
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 100
+	}
+	handler := &GraphQLHandler{
+		UserUsecase: us,
+		ArticleRepo: ar,
+		schema:      schema,
+		cfg:         cfg,
+	}
+	e.POST("/graphql", handler.Query)
+	return nil
+}
+
+// Query will execute the GraphQL operation of the request body, after checking its depth and complexity
+func (a *GraphQLHandler) Query(c echo.Context) error {
+	var req request
+	if err := c.Bind(&req); err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+	cost := measure(doc, req.OperationName, req.Variables)
+	if a.cfg.MaxDepth > 0 && cost.depth > a.cfg.MaxDepth {
+		return c.JSON(http.StatusBadRequest, limitExceeded("depth", cost.depth, a.cfg.MaxDepth))
+	}
+	if a.cfg.MaxComplexity > 0 && cost.complexity > a.cfg.MaxComplexity {
+		return c.JSON(http.StatusBadRequest, limitExceeded("complexity", cost.complexity, a.cfg.MaxComplexity))
+	}
+
+	ctx := withLoaders(c.Request().Context(), &loaders{
+		users:          newUserLoader(a.UserUsecase, a.cfg.BatchSize),
+		articles:       newArticleLoader(a.ArticleRepo, a.cfg.BatchSize),
+		authorArticles: newAuthorArticlesLoader(a.ArticleRepo, a.cfg.BatchSize),
+	})
+	res := graphql.Do(graphql.Params{
+		Schema:         a.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+	return c.JSON(http.StatusOK, res)
+}
+
+func limitExceeded(limit string, got, max int) graphql.Result {
+	return graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("query %s %d exceeds the maximum of %d", limit, got, max))}
+}
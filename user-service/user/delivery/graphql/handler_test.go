@@ -0,0 +1,166 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userGraphql "github.com/diantanjung/blogo/user-service/user/delivery/graphql"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+)
+
+func query(t *testing.T, us domain.UserUsecase, cfg userGraphql.Config, body string) (int, map[string]interface{}) {
+	return queryWithArticles(t, us, nil, cfg, body)
+}
+
+func queryWithArticles(t *testing.T, us domain.UserUsecase, ar domain.ArticleRepository, cfg userGraphql.Config, body string) (int, map[string]interface{}) {
+	e := echo.New()
+	require.NoError(t, userGraphql.NewGraphQLHandler(e, us, ar, cfg))
+
+	req, err := http.NewRequest(echo.POST, "/graphql", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	return rec.Code, res
+}
+
+func TestAuditActorsAreBatched(t *testing.T) {
+	now := time.Now()
+	entries := []domain.AuditEntry{
+		{ID: 1, ActorID: 1, Action: domain.AuditUserCreated, TargetUserID: 7, CreatedAt: now},
+		{ID: 2, ActorID: 2, Action: domain.AuditUserUpdated, TargetUserID: 7, CreatedAt: now.Add(time.Minute)},
+		{ID: 3, ActorID: 1, Action: domain.AuditUserUpdated, TargetUserID: 7, CreatedAt: now.Add(2 * time.Minute)},
+		{ID: 4, Action: domain.AuditUserUpdated, TargetUserID: 7, CreatedAt: now.Add(3 * time.Minute)},
+	}
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("GetByIDs", mock.Anything, []int64{7}).Return([]domain.User{{ID: 7, Username: "target"}}, nil).Once()
+	mockUCase.On("FetchAudit", mock.Anything, int64(7), "", int64(10)).Return(entries, "next", nil).Once()
+	mockUCase.On("GetByIDs", mock.Anything, []int64{1, 2}).Return([]domain.User{{ID: 1, Username: "admin"}}, nil).Once()
+
+	code, res := query(t, mockUCase, userGraphql.Config{}, `{"query":"{ user(id: \"7\") { username auditLog { edges { cursor node { id actor { username } } } pageInfo { hasNextPage endCursor } } } }"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, res["errors"])
+
+	auditLog := res["data"].(map[string]interface{})["user"].(map[string]interface{})["auditLog"].(map[string]interface{})
+	edges := auditLog["edges"].([]interface{})
+	require.Len(t, edges, 4)
+	actor := func(i int) interface{} {
+		return edges[i].(map[string]interface{})["node"].(map[string]interface{})["actor"]
+	}
+	assert.Equal(t, map[string]interface{}{"username": "admin"}, actor(0))
+	assert.Nil(t, actor(1), "deleted actor")
+	assert.Equal(t, map[string]interface{}{"username": "admin"}, actor(2))
+	assert.Nil(t, actor(3), "anonymous change")
+
+	pageInfo := auditLog["pageInfo"].(map[string]interface{})
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+	assert.Equal(t, repository.EncodeIDCursor(entries[3].ID), pageInfo["endCursor"])
+	mockUCase.AssertExpectations(t)
+}
+
+func TestArticleAuthorsAndTheirArticlesAreBatched(t *testing.T) {
+	now := time.Now()
+	// the fields resolve in any order, so do the ids of a batch
+	sameIDs := func(want ...int64) interface{} {
+		return mock.MatchedBy(func(ids []int64) bool {
+			got := append([]int64(nil), ids...)
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			return assert.ObjectsAreEqual(want, got)
+		})
+	}
+	mockArticleRepo := new(mocks.ArticleRepository)
+	mockArticleRepo.On("GetByIDs", mock.Anything, sameIDs(10, 11, 13)).Return([]domain.Article{
+		{ID: 10, AuthorID: 1, Title: "Clean architecture", PublishedAt: now},
+		{ID: 11, AuthorID: 2, Title: "Dataloaders", PublishedAt: now},
+	}, nil).Once()
+	mockArticleRepo.On("FetchByAuthorIDs", mock.Anything, sameIDs(1, 2), int64(2)).Return([]domain.Article{
+		{ID: 12, AuthorID: 1, Title: "Go modules", PublishedAt: now},
+		{ID: 10, AuthorID: 1, Title: "Clean architecture", PublishedAt: now},
+		{ID: 11, AuthorID: 2, Title: "Dataloaders", PublishedAt: now},
+	}, nil).Once()
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("GetByIDs", mock.Anything, sameIDs(1, 2)).Return([]domain.User{{ID: 1, Username: "dias"}, {ID: 2, Username: "tanjung"}}, nil).Once()
+
+	selection := `{ title author { username articles(first: 2) { title } } }`
+	code, res := queryWithArticles(t, mockUCase, mockArticleRepo, userGraphql.Config{},
+		`{"query":"{ a: article(id: \"10\") `+selection+` b: article(id: \"11\") `+selection+` missing: article(id: \"13\") { title } }"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, res["errors"])
+
+	data := res["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"title": "Clean architecture",
+		"author": map[string]interface{}{
+			"username": "dias",
+			"articles": []interface{}{
+				map[string]interface{}{"title": "Go modules"},
+				map[string]interface{}{"title": "Clean architecture"},
+			},
+		},
+	}, data["a"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"title": "Dataloaders"}},
+		data["b"].(map[string]interface{})["author"].(map[string]interface{})["articles"])
+	assert.Nil(t, data["missing"])
+	// one call per level, whatever the number of articles
+	mockArticleRepo.AssertExpectations(t)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestArticlesWithoutArticleService(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("GetByIDs", mock.Anything, []int64{1}).Return([]domain.User{{ID: 1, Username: "dias"}}, nil).Once()
+
+	code, res := query(t, mockUCase, userGraphql.Config{}, `{"query":"{ article(id: \"10\") { title } user(id: \"1\") { articles { title } } }"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, res["errors"])
+	data := res["data"].(map[string]interface{})
+	assert.Nil(t, data["article"])
+	assert.Equal(t, []interface{}{}, data["user"].(map[string]interface{})["articles"])
+}
+
+func TestUsersConnection(t *testing.T) {
+	now := time.Now()
+	users := []domain.User{{ID: 1, Username: "dias", Roles: []domain.Role{domain.RoleAuthor}, CreatedAt: now}}
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Fetch", mock.Anything, "abc", int64(1)).Return(users, "", nil)
+
+	code, res := query(t, mockUCase, userGraphql.Config{}, `{"query":"query($after: String) { users(first: 1, after: $after) { edges { cursor node { id roles email } } pageInfo { hasNextPage } } }","variables":{"after":"abc"}}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, res["errors"])
+
+	conn := res["data"].(map[string]interface{})["users"].(map[string]interface{})
+	edge := conn["edges"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, repository.EncodeIDCursor(1), edge["cursor"])
+	assert.Equal(t, map[string]interface{}{"id": "1", "roles": []interface{}{"author"}, "email": nil}, edge["node"])
+	assert.Equal(t, false, conn["pageInfo"].(map[string]interface{})["hasNextPage"])
+}
+
+func TestQueryLimits(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+
+	code, res := query(t, mockUCase, userGraphql.Config{MaxDepth: 3}, `{"query":"{ user(id: \"7\") { auditLog { edges { node { id } } } } }"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, res["errors"].([]interface{})[0].(map[string]interface{})["message"], "depth 5")
+
+	// 1 + 100 * (1 + 1 + 1)
+	code, res = query(t, mockUCase, userGraphql.Config{MaxComplexity: 200}, `{"query":"query($n: Int) { users(first: $n) { edges { node { id } } } }","variables":{"n":100}}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, res["errors"].([]interface{})[0].(map[string]interface{})["message"], "complexity 301")
+
+	mockUCase.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything, mock.Anything)
+}
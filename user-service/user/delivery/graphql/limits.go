@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// cost is the depth and complexity of an operation. Every field costs 1, and the cost of the selection
+// of a list field is multiplied by the number of items it asks for: first, or the number of ids.
+// Introspection fields are free, their depth is bounded by the schema.
+type cost struct {
+	depth      int
+	complexity int
+}
+
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// spreading holds the fragments being measured, to stop on cycles the validation rejects later
+	spreading map[string]bool
+}
+
+// measure returns the cost of the operation named operationName, or of the only operation of doc
+func measure(doc *ast.Document, operationName string, variables map[string]interface{}) cost {
+	m := measurer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		spreading: make(map[string]bool),
+	}
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			m.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if op == nil || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		return cost{}
+	}
+	return m.selectionSet(op.SelectionSet)
+}
+
+func (m *measurer) selectionSet(set *ast.SelectionSet) (c cost) {
+	if set == nil {
+		return
+	}
+	for _, sel := range set.Selections {
+		var sc cost
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			sc = m.selectionSet(sel.SelectionSet)
+			sc.depth++
+			sc.complexity = 1 + m.multiplier(sel)*sc.complexity
+		case *ast.InlineFragment:
+			sc = m.selectionSet(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			if m.spreading[name] || m.fragments[name] == nil {
+				continue
+			}
+			m.spreading[name] = true
+			sc = m.selectionSet(m.fragments[name].SelectionSet)
+			delete(m.spreading, name)
+		}
+		c.complexity += sc.complexity
+		if sc.depth > c.depth {
+			c.depth = sc.depth
+		}
+	}
+	return
+}
+
+// multiplier returns the number of items a field asks for, connections default to defaultFirst items
+func (m *measurer) multiplier(f *ast.Field) int {
+	for _, arg := range f.Arguments {
+		switch arg.Name.Value {
+		case "first":
+			if n, ok := m.intValue(arg.Value); ok && n > 0 {
+				return n
+			}
+		case "ids":
+			if n := m.listLen(arg.Value); n > 0 {
+				return n
+			}
+		}
+	}
+	if f.SelectionSet != nil {
+		for _, sel := range f.SelectionSet.Selections {
+			if child, ok := sel.(*ast.Field); ok && child.Name.Value == "edges" {
+				return defaultFirst
+			}
+		}
+	}
+	return 1
+}
+
+func (m *measurer) intValue(v ast.Value) (int, bool) {
+	switch v := v.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(v.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := m.variables[v.Name.Value].(type) {
+		case float64:
+			return int(n), true
+		case int:
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func (m *measurer) listLen(v ast.Value) int {
+	switch v := v.(type) {
+	case *ast.ListValue:
+		return len(v.Values)
+	case *ast.Variable:
+		if list, ok := m.variables[v.Name.Value].([]interface{}); ok {
+			return len(list)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader/v7"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type loaderKey struct{}
+
+// userLoader batches the users resolved while executing one request into GetByIDs calls
+type userLoader = dataloader.Interface[int64, *domain.User]
+
+// articleLoader batches the articles resolved while executing one request into GetByIDs calls
+type articleLoader = dataloader.Interface[int64, *domain.Article]
+
+// authorArticles is the key of the latest first articles of an author
+type authorArticles struct {
+	authorID int64
+	first    int64
+}
+
+// authorArticlesLoader batches the articles of the authors resolved while executing one request
+// into a FetchByAuthorIDs call per page size
+type authorArticlesLoader = dataloader.Interface[authorArticles, []domain.Article]
+
+// loaders are the dataloaders of one request
+type loaders struct {
+	users          userLoader
+	articles       articleLoader
+	authorArticles authorArticlesLoader
+}
+
+func newUserLoader(us domain.UserUsecase, batch int) userLoader {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, ids []int64) []*dataloader.Result[*domain.User] {
+		res := make([]*dataloader.Result[*domain.User], len(ids))
+		users, err := us.GetByIDs(ctx, ids)
+		if err != nil {
+			for i := range res {
+				res[i] = &dataloader.Result[*domain.User]{Error: err}
+			}
+			return res
+		}
+
+		byID := make(map[int64]*domain.User, len(users))
+		for i := range users {
+			byID[users[i].ID] = &users[i]
+		}
+		// the results must follow the order of ids, a missing user resolves to null
+		for i, id := range ids {
+			res[i] = &dataloader.Result[*domain.User]{Data: byID[id]}
+		}
+		return res
+	}, dataloader.WithBatchCapacity[int64, *domain.User](batch))
+}
+
+// newArticleLoader will load nothing when ar is nil, no article service is deployed
+func newArticleLoader(ar domain.ArticleRepository, batch int) articleLoader {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, ids []int64) []*dataloader.Result[*domain.Article] {
+		res := make([]*dataloader.Result[*domain.Article], len(ids))
+		if ar == nil {
+			for i := range res {
+				res[i] = &dataloader.Result[*domain.Article]{}
+			}
+			return res
+		}
+		articles, err := ar.GetByIDs(ctx, ids)
+		if err != nil {
+			for i := range res {
+				res[i] = &dataloader.Result[*domain.Article]{Error: err}
+			}
+			return res
+		}
+
+		byID := make(map[int64]*domain.Article, len(articles))
+		for i := range articles {
+			byID[articles[i].ID] = &articles[i]
+		}
+		for i, id := range ids {
+			res[i] = &dataloader.Result[*domain.Article]{Data: byID[id]}
+		}
+		return res
+	}, dataloader.WithBatchCapacity[int64, *domain.Article](batch))
+}
+
+// newAuthorArticlesLoader will load nothing when ar is nil, no article service is deployed
+func newAuthorArticlesLoader(ar domain.ArticleRepository, batch int) authorArticlesLoader {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, keys []authorArticles) []*dataloader.Result[[]domain.Article] {
+		res := make([]*dataloader.Result[[]domain.Article], len(keys))
+		if ar == nil {
+			for i := range res {
+				res[i] = &dataloader.Result[[]domain.Article]{Data: []domain.Article{}}
+			}
+			return res
+		}
+		// the authors asked for the same number of articles are fetched together
+		authorIDs := make(map[int64][]int64)
+		for _, k := range keys {
+			authorIDs[k.first] = append(authorIDs[k.first], k.authorID)
+		}
+		byKey := make(map[authorArticles][]domain.Article, len(keys))
+		errs := make(map[int64]error)
+		for first, ids := range authorIDs {
+			articles, err := ar.FetchByAuthorIDs(ctx, ids, first)
+			if err != nil {
+				errs[first] = err
+				continue
+			}
+			for _, a := range articles {
+				k := authorArticles{authorID: a.AuthorID, first: first}
+				byKey[k] = append(byKey[k], a)
+			}
+		}
+		for i, k := range keys {
+			if errs[k.first] != nil {
+				res[i] = &dataloader.Result[[]domain.Article]{Error: errs[k.first]}
+				continue
+			}
+			articles := byKey[k]
+			if articles == nil {
+				articles = []domain.Article{}
+			}
+			res[i] = &dataloader.Result[[]domain.Article]{Data: articles}
+		}
+		return res
+	}, dataloader.WithBatchCapacity[authorArticles, []domain.Article](batch))
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loaderKey{}).(*loaders)
+}
+
+// loadUser returns a thunk resolving the user once the batch it joined is fetched
+func loadUser(ctx context.Context, id int64) func() (interface{}, error) {
+	thunk := loadersFrom(ctx).users.Load(ctx, id)
+	return func() (interface{}, error) {
+		u, err := thunk()
+		if err != nil || u == nil {
+			return nil, err
+		}
+		return *u, nil
+	}
+}
+
+// loadArticle returns a thunk resolving the article once the batch it joined is fetched
+func loadArticle(ctx context.Context, id int64) func() (interface{}, error) {
+	thunk := loadersFrom(ctx).articles.Load(ctx, id)
+	return func() (interface{}, error) {
+		a, err := thunk()
+		if err != nil || a == nil {
+			return nil, err
+		}
+		return *a, nil
+	}
+}
+
+// loadAuthorArticles returns a thunk resolving the latest first articles of the author once the batch
+// it joined is fetched
+func loadAuthorArticles(ctx context.Context, authorID, first int64) func() (interface{}, error) {
+	thunk := loadersFrom(ctx).authorArticles.Load(ctx, authorArticles{authorID: authorID, first: first})
+	return func() (interface{}, error) {
+		articles, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		nodes := make([]interface{}, len(articles))
+		for i := range articles {
+			nodes[i] = articles[i]
+		}
+		return nodes, nil
+	}
+}
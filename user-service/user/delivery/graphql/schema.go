@@ -0,0 +1,281 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+)
+
+// defaultFirst is the page size of a connection when first is not given
+const defaultFirst = 10
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"endCursor":   &graphql.Field{Type: graphql.String},
+	},
+})
+
+var connectionArgs = graphql.FieldConfigArgument{
+	"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultFirst},
+	"after": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+}
+
+// connection builds a Relay connection from a page of nodes, cursor returns the cursor of each node
+// and nextCursor is the cursor the usecase returned for the following page
+func connection(nodes []interface{}, cursor func(i int) string, nextCursor string) map[string]interface{} {
+	edges := make([]map[string]interface{}, len(nodes))
+	for i := range nodes {
+		edges[i] = map[string]interface{}{"cursor": cursor(i), "node": nodes[i]}
+	}
+	pageInfo := map[string]interface{}{"hasNextPage": nextCursor != ""}
+	if len(edges) > 0 {
+		pageInfo["endCursor"] = edges[len(edges)-1]["cursor"]
+	}
+	return map[string]interface{}{"edges": edges, "pageInfo": pageInfo}
+}
+
+func connectionType(name string, node graphql.Output) *graphql.Object {
+	edge := graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Edge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(node)},
+		},
+	})
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Connection",
+		Fields: graphql.Fields{
+			"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edge)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+}
+
+func parseID(v interface{}) (int64, error) {
+	s, _ := v.(string)
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, domain.ErrBadParamInput
+	}
+	return id, nil
+}
+
+func newSchema(us domain.UserUsecase) (graphql.Schema, error) {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"username": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			// email is null unless the caller may view it
+			"email": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if u := p.Source.(domain.User); u.Email != "" {
+						return u.Email, nil
+					}
+					return nil, nil
+				},
+			},
+			"roles": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					roles := []string{}
+					for _, r := range p.Source.(domain.User).Roles {
+						roles = append(roles, string(r))
+					}
+					return roles, nil
+				},
+			},
+			"emailVerifiedAt": &graphql.Field{
+				Type: graphql.DateTime,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if at := p.Source.(domain.User).EmailVerifiedAt; at != nil {
+						return *at, nil
+					}
+					return nil, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.DateTime),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.User).CreatedAt, nil
+				},
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.DateTime),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.User).UpdatedAt, nil
+				},
+			},
+		},
+	})
+
+	auditEntryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AuditEntry",
+		Fields: graphql.Fields{
+			"id":     &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"action": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			// actor is null for anonymous changes and deleted actors
+			"actor": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					e := p.Source.(domain.AuditEntry)
+					if e.ActorID == 0 {
+						return nil, nil
+					}
+					return loadUser(p.Context, e.ActorID), nil
+				},
+			},
+			// diff is the JSON encoded map of the changed fields
+			"diff": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					b, err := json.Marshal(p.Source.(domain.AuditEntry).Diff)
+					return string(b), err
+				},
+			},
+			"ip": &graphql.Field{Type: graphql.String},
+			"requestId": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.AuditEntry).RequestID, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.DateTime),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.AuditEntry).CreatedAt, nil
+				},
+			},
+		},
+	})
+
+	userType.AddFieldConfig("auditLog", &graphql.Field{
+		Type:        graphql.NewNonNull(connectionType("AuditEntry", auditEntryType)),
+		Description: "The changes made to the user, restricted to admins",
+		Args:        connectionArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			entries, nextCursor, err := us.FetchAudit(p.Context, p.Source.(domain.User).ID, p.Args["after"].(string), int64(p.Args["first"].(int)))
+			if err != nil {
+				return nil, err
+			}
+			nodes := make([]interface{}, len(entries))
+			for i := range entries {
+				nodes[i] = entries[i]
+			}
+			return connection(nodes, func(i int) string { return repository.EncodeIDCursor(entries[i].ID) }, nextCursor), nil
+		},
+	})
+
+	articleType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Article",
+		Fields: graphql.Fields{
+			"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"slug":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			// author is null when the user was deleted
+			"author": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadUser(p.Context, p.Source.(domain.Article).AuthorID), nil
+				},
+			},
+			"publishedAt": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.DateTime),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.Article).PublishedAt, nil
+				},
+			},
+		},
+	})
+
+	userType.AddFieldConfig("articles", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(articleType))),
+		Description: "The latest articles of the user, newest first",
+		Args: graphql.FieldConfigArgument{
+			"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultFirst},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			first := p.Args["first"].(int)
+			if first <= 0 {
+				return nil, domain.ErrBadParamInput
+			}
+			return loadAuthorArticles(p.Context, p.Source.(domain.User).ID, int64(first)), nil
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					return loadUser(p.Context, id), nil
+				},
+			},
+			"article": &graphql.Field{
+				Type: articleType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					return loadArticle(p.Context, id), nil
+				},
+			},
+			"usersByIds": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(userType)),
+				Description: "The users with the given ids, in the same order, null for the missing ones",
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ids := p.Args["ids"].([]interface{})
+					res := make([]interface{}, len(ids))
+					for i := range ids {
+						id, err := parseID(ids[i])
+						if err != nil {
+							return nil, err
+						}
+						res[i] = loadUser(p.Context, id)
+					}
+					return res, nil
+				},
+			},
+			"users": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType("User", userType)),
+				Args: connectionArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					users, nextCursor, err := us.Fetch(p.Context, p.Args["after"].(string), int64(p.Args["first"].(int)))
+					if err != nil {
+						return nil, err
+					}
+					nodes := make([]interface{}, len(users))
+					for i := range users {
+						nodes[i] = users[i]
+					}
+					return connection(nodes, func(i int) string { return repository.EncodeIDCursor(users[i].ID) }, nextCursor), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// MetadataRequestID is the metadata key used to propagate the request ID, the gRPC form of X-Request-ID
+const MetadataRequestID = "x-request-id"
+
+// Logging will propagate the incoming request ID, or assign a new one, store it with the client IP
+// and a request scoped logger in the context, and write one log line per call
+func Logging(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id := first(md, MetadataRequestID)
+	if id == "" || len(id) > 128 {
+		id = logging.NewRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataRequestID, id))
+
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip, _, _ = net.SplitHostPort(p.Addr.String())
+	}
+	ctx = logging.WithRequestID(ctx, id)
+	ctx = domain.NewContextWithClientIP(ctx, ip)
+	ctx = logging.NewContext(ctx, logrus.WithField("request_id", id))
+
+	res, err := handler(ctx, req)
+
+	code := status.Code(err)
+	fields := logrus.Fields{
+		"method":     info.FullMethod,
+		"code":       code.String(),
+		"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		"remote_ip":  ip,
+	}
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		fields["user_id"] = actor.UserID
+	}
+
+	entry := logging.FromContext(ctx).WithFields(fields)
+	switch code {
+	case codes.OK:
+		entry.Info("call completed")
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		entry.Error("call completed")
+	default:
+		entry.Warn("call completed")
+	}
+	return res, err
+}
+
+// Authenticate will resolve the bearer token in the authorization metadata into a domain.Actor stored in the context.
+// Calls without a token go through anonymously, the usecase decides whether that is allowed.
+func Authenticate(au domain.AuthUsecase) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		header := first(md, "authorization")
+		if header == "" {
+			return handler(ctx, req)
+		}
+
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == header {
+			return nil, status.Error(codes.Unauthenticated, domain.ErrUnauthorized.Error())
+		}
+
+		actor, err := au.Authenticate(ctx, token)
+		if err != nil {
+			if err == domain.ErrUnauthorized {
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return handler(domain.NewContextWithActor(ctx, actor), req)
+	}
+}
+
+// Recover will turn a panic of the handler into an Internal error, logging the panic with its stack trace
+func Recover(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		logging.FromContext(ctx).
+			WithField("panic", fmt.Sprint(r)).
+			WithField("stack", string(debug.Stack())).
+			Error("recovered from panic")
+		err = status.Error(codes.Internal, domain.ErrInternalServerError.Error())
+	}()
+	return handler(ctx, req)
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/user/delivery/grpc/userpb"
+)
+
+// UserServer represent the gRPC server for users
+type UserServer struct {
+	userpb.UnimplementedUserServiceServer
+	UserUsecase domain.UserUsecase
+}
+
+// NewUserServer will register the UserService on s
+func NewUserServer(s *grpc.Server, us domain.UserUsecase) {
+	userpb.RegisterUserServiceServer(s, &UserServer{
+		UserUsecase: us,
+	})
+}
+
+func (a *UserServer) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.User, error) {
+	user, err := a.UserUsecase.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toProto(user), nil
+}
+
+func (a *UserServer) BatchGetUsers(ctx context.Context, req *userpb.BatchGetUsersRequest) (*userpb.BatchGetUsersResponse, error) {
+	users, err := a.UserUsecase.GetByIDs(ctx, req.GetIds())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &userpb.BatchGetUsersResponse{Users: toProtoList(users)}, nil
+}
+
+func (a *UserServer) ListUsers(ctx context.Context, req *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	users, nextCursor, err := a.UserUsecase.Fetch(ctx, req.GetCursor(), req.GetNum())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &userpb.ListUsersResponse{Users: toProtoList(users), NextCursor: nextCursor}, nil
+}
+
+func (a *UserServer) CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*userpb.User, error) {
+	user := domain.User{
+		Username: req.GetUsername(),
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Roles:    toRoles(req.GetRoles()),
+	}
+	if err := a.UserUsecase.Store(ctx, &user); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toProto(user), nil
+}
+
+func (a *UserServer) UpdateUser(ctx context.Context, req *userpb.UpdateUserRequest) (*userpb.User, error) {
+	user := domain.User{
+		ID:       req.GetId(),
+		Username: req.GetUsername(),
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Roles:    toRoles(req.GetRoles()),
+	}
+	if err := a.UserUsecase.Update(ctx, &user); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toProto(user), nil
+}
+
+func (a *UserServer) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := a.UserUsecase.Delete(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toProto(u domain.User) *userpb.User {
+	res := &userpb.User{
+		Id:        u.ID,
+		Username:  u.Username,
+		Name:      u.Name,
+		Email:     u.Email,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+	for _, r := range u.Roles {
+		res.Roles = append(res.Roles, string(r))
+	}
+	if u.EmailVerifiedAt != nil {
+		res.EmailVerifiedAt = timestamppb.New(*u.EmailVerifiedAt)
+	}
+	return res
+}
+
+func toProtoList(users []domain.User) []*userpb.User {
+	res := make([]*userpb.User, len(users))
+	for i := range users {
+		res[i] = toProto(users[i])
+	}
+	return res
+}
+
+// toRoles returns nil for no roles, which the usecase reads as "unchanged" or "default"
+func toRoles(roles []string) []domain.Role {
+	if len(roles) == 0 {
+		return nil
+	}
+	res := make([]domain.Role, len(roles))
+	for i, r := range roles {
+		res[i] = domain.Role(r)
+	}
+	return res
+}
+
+// toStatus maps the domain errors to gRPC status codes, as getStatusCode does for HTTP
+func toStatus(ctx context.Context, err error) error {
+	logging.FromContext(ctx).Error(err)
+	var code codes.Code
+	switch err {
+	case domain.ErrInternalServerError:
+		code = codes.Internal
+	case domain.ErrNotFound:
+		code = codes.NotFound
+	case domain.ErrConflict:
+		code = codes.AlreadyExists
+	case domain.ErrBadParamInput, domain.ErrInvalidToken, domain.ErrWeakPassword, domain.ErrPasswordReused:
+		code = codes.InvalidArgument
+	case domain.ErrUnauthorized, domain.ErrInvalidCredentials:
+		code = codes.Unauthenticated
+	case domain.ErrForbidden, domain.ErrEmailNotVerified, domain.ErrPasswordMismatch:
+		code = codes.PermissionDenied
+	case domain.ErrTooManyAttempts, domain.ErrTooManyRequests:
+		code = codes.ResourceExhausted
+	default:
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
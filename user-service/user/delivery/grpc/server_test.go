@@ -0,0 +1,99 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userGrpc "github.com/diantanjung/blogo/user-service/user/delivery/grpc"
+	"github.com/diantanjung/blogo/user-service/user/delivery/grpc/userpb"
+)
+
+func newClient(t *testing.T, us domain.UserUsecase, au domain.AuthUsecase) userpb.UserServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(userGrpc.Logging, userGrpc.Recover, userGrpc.Authenticate(au)))
+	userGrpc.NewUserServer(s, us)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return userpb.NewUserServiceClient(conn)
+}
+
+func TestBatchGetUsers(t *testing.T) {
+	users := []domain.User{
+		{ID: 1, Username: "dias", Name: "Dias", Roles: []domain.Role{domain.RoleAuthor}},
+		{ID: 3, Username: "tanjung", Name: "Tanjung", Roles: []domain.Role{domain.RoleReader}},
+	}
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("GetByIDs", mock.Anything, []int64{1, 2, 3}).Return(users, nil)
+
+	client := newClient(t, mockUCase, new(mocks.AuthUsecase))
+
+	var header metadata.MD
+	res, err := client.BatchGetUsers(context.TODO(), &userpb.BatchGetUsersRequest{Ids: []int64{1, 2, 3}}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Len(t, res.Users, 2)
+	assert.Equal(t, "tanjung", res.Users[1].Username)
+	assert.Equal(t, []string{"author"}, res.Users[0].Roles)
+	assert.NotEmpty(t, header.Get(userGrpc.MetadataRequestID))
+	mockUCase.AssertExpectations(t)
+}
+
+func TestDeleteUserAuthenticated(t *testing.T) {
+	mockAuth := new(mocks.AuthUsecase)
+	mockAuth.On("Authenticate", mock.Anything, "good").Return(domain.Actor{UserID: 2}, nil)
+	mockAuth.On("Authenticate", mock.Anything, "expired").Return(domain.Actor{}, domain.ErrUnauthorized)
+
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Delete", mock.MatchedBy(func(ctx context.Context) bool {
+		actor, ok := domain.ActorFromContext(ctx)
+		return ok && actor.UserID == 2
+	}), int64(3)).Return(domain.ErrForbidden)
+
+	client := newClient(t, mockUCase, mockAuth)
+
+	for token, code := range map[string]codes.Code{"good": codes.PermissionDenied, "expired": codes.Unauthenticated} {
+		ctx := metadata.AppendToOutgoingContext(context.TODO(), "authorization", "Bearer "+token)
+		_, err := client.DeleteUser(ctx, &userpb.DeleteUserRequest{Id: 3})
+		assert.Equal(t, code, status.Code(err), token)
+	}
+	mockUCase.AssertExpectations(t)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestGetUserNotFound(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("GetByID", mock.Anything, int64(9)).Return(domain.User{}, domain.ErrNotFound)
+
+	client := newClient(t, mockUCase, new(mocks.AuthUsecase))
+
+	_, err := client.GetUser(context.TODO(), &userpb.GetUserRequest{Id: 9})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestRecover(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Fetch", mock.Anything, "", int64(0)).Run(func(mock.Arguments) { panic("boom") })
+
+	client := newClient(t, mockUCase, new(mocks.AuthUsecase))
+
+	_, err := client.ListUsers(context.TODO(), &userpb.ListUsersRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
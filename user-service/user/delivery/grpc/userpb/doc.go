@@ -0,0 +1,4 @@
+// Package userpb holds the protobuf messages and gRPC stubs of the UserService
+package userpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative user.proto
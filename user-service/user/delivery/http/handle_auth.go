@@ -0,0 +1,104 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/user/delivery/http/middleware"
+)
+
+type AuthHandler struct {
+	AuthUsecase domain.AuthUsecase
+}
+
+func NewAuthHandler(e *echo.Echo, au domain.AuthUsecase) {
+	handler := &AuthHandler{
+		AuthUsecase: au,
+	}
+	e.POST("/auth/login", handler.Login)
+	e.POST("/auth/logout", handler.Logout)
+	e.POST("/auth/password/forgot", handler.ForgotPassword)
+	e.POST("/auth/password/reset", handler.ResetPassword)
+	e.POST("/users/:id/unlock", handler.Unlock)
+}
+
+// Login will issue an access token for the given credentials
+func (a *AuthHandler) Login(c echo.Context) (err error) {
+	var cred domain.Credentials
+	err = c.Bind(&cred)
+	if err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	token, err := a.AuthUsecase.Login(ctx, cred)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, token)
+}
+
+// Logout will revoke the session of the current access token
+func (a *AuthHandler) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+	err := a.AuthUsecase.Logout(ctx)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ForgotPassword will send a password reset link, the response does not tell whether the email exists
+func (a *AuthHandler) ForgotPassword(c echo.Context) (err error) {
+	var req domain.ForgotPasswordRequest
+	err = c.Bind(&req)
+	if err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	err = a.AuthUsecase.ForgotPassword(ctx, req)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ResetPassword will set a new password using the token of a reset link
+func (a *AuthHandler) ResetPassword(c echo.Context) (err error) {
+	var req domain.ResetPasswordRequest
+	err = c.Bind(&req)
+	if err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	err = a.AuthUsecase.ResetPassword(ctx, req)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Unlock will clear the failed login counter of the user account, it is restricted to admins
+func (a *AuthHandler) Unlock(c echo.Context) error {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	ctx := c.Request().Context()
+	err = a.AuthUsecase.Unlock(ctx, int64(idP))
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
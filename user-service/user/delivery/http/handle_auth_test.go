@@ -0,0 +1,190 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userHttp "github.com/diantanjung/blogo/user-service/user/delivery/http"
+)
+
+func TestLogin(t *testing.T) {
+	cred := domain.Credentials{Email: "dias@gmail.com", Password: "secret"}
+	token := domain.Token{AccessToken: "token", TokenType: "Bearer", ExpiresAt: time.Now().Add(time.Hour)}
+
+	mockUCase := new(mocks.AuthUsecase)
+	mockUCase.On("Login", mock.Anything, cred).Return(token, nil)
+
+	j, err := json.Marshal(cred)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/auth/login", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handler := userHttp.AuthHandler{
+		AuthUsecase: mockUCase,
+	}
+	err = handler.Login(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"access_token":"token"`)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestLoginInvalidCredentials(t *testing.T) {
+	cred := domain.Credentials{Email: "dias@gmail.com", Password: "wrong"}
+
+	mockUCase := new(mocks.AuthUsecase)
+	mockUCase.On("Login", mock.Anything, cred).Return(domain.Token{}, domain.ErrInvalidCredentials)
+
+	j, err := json.Marshal(cred)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/auth/login", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handler := userHttp.AuthHandler{
+		AuthUsecase: mockUCase,
+	}
+	err = handler.Login(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestLogout(t *testing.T) {
+	mockUCase := new(mocks.AuthUsecase)
+	mockUCase.On("Logout", mock.Anything).Return(nil)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/auth/logout", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handler := userHttp.AuthHandler{
+		AuthUsecase: mockUCase,
+	}
+	err = handler.Logout(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestForgotPassword(t *testing.T) {
+	req := domain.ForgotPasswordRequest{Email: "unknown@gmail.com"}
+
+	mockUCase := new(mocks.AuthUsecase)
+	mockUCase.On("ForgotPassword", mock.Anything, req).Return(nil)
+
+	j, err := json.Marshal(req)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	r, err := http.NewRequest(echo.POST, "/auth/password/forgot", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+	r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(r, rec)
+	handler := userHttp.AuthHandler{
+		AuthUsecase: mockUCase,
+	}
+	err = handler.ForgotPassword(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestResetPassword(t *testing.T) {
+	mockUCase := new(mocks.AuthUsecase)
+	mockUCase.On("ResetPassword", mock.Anything, domain.ResetPasswordRequest{Token: "used", Password: "n3wPassword"}).Return(domain.ErrInvalidToken)
+	mockUCase.On("ResetPassword", mock.Anything, domain.ResetPasswordRequest{Token: "good", Password: "n3wPassword"}).Return(nil)
+
+	handler := userHttp.AuthHandler{
+		AuthUsecase: mockUCase,
+	}
+	e := echo.New()
+
+	for token, code := range map[string]int{"good": http.StatusNoContent, "used": http.StatusBadRequest} {
+		r, err := http.NewRequest(echo.POST, "/auth/password/reset", strings.NewReader(`{"token":"`+token+`","password":"n3wPassword"}`))
+		assert.NoError(t, err)
+		r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(r, rec)
+		err = handler.ResetPassword(c)
+		require.NoError(t, err)
+		assert.Equal(t, code, rec.Code)
+	}
+	mockUCase.AssertExpectations(t)
+}
+
+func TestLoginTooManyAttempts(t *testing.T) {
+	mockUCase := new(mocks.AuthUsecase)
+	mockUCase.On("Login", mock.Anything, domain.Credentials{Email: "a@b.c", Password: "guess"}).Return(domain.Token{}, domain.ErrTooManyAttempts)
+
+	e := echo.New()
+	r, err := http.NewRequest(echo.POST, "/auth/login", strings.NewReader(`{"email":"a@b.c","password":"guess"}`))
+	assert.NoError(t, err)
+	r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(r, rec)
+	handler := userHttp.AuthHandler{
+		AuthUsecase: mockUCase,
+	}
+	err = handler.Login(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestUnlock(t *testing.T) {
+	mockUCase := new(mocks.AuthUsecase)
+	mockUCase.On("Unlock", mock.Anything, int64(1)).Return(nil)
+	mockUCase.On("Unlock", mock.Anything, int64(2)).Return(domain.ErrForbidden)
+
+	handler := userHttp.AuthHandler{
+		AuthUsecase: mockUCase,
+	}
+	e := echo.New()
+
+	for id, code := range map[string]int{"1": http.StatusNoContent, "2": http.StatusForbidden, "x": http.StatusNotFound} {
+		r, err := http.NewRequest(echo.POST, "/users/"+id+"/unlock", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(r, rec)
+		c.SetPath("/users/:id/unlock")
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		err = handler.Unlock(c)
+		require.NoError(t, err)
+		assert.Equal(t, code, rec.Code)
+	}
+	mockUCase.AssertExpectations(t)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// Caching of the author pages. Profiles are public, so shared caches may keep them. The redirects
+// of former usernames are temporary and short lived, a user may take a former username back.
+const (
+	authorCacheControl   = "public, max-age=60"
+	redirectCacheControl = "public, max-age=60"
+)
+
+// GetAuthor will get the public profile of the author by username, ignoring case. A former username
+// or another case is redirected to the current username.
+func (a *UserHandler) GetAuthor(c echo.Context) error {
+	username := c.Param("username")
+	ctx := c.Request().Context()
+	u, err := a.UserUsecase.GetByUsername(ctx, username)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	if u.Username != username {
+		c.Response().Header().Set("Cache-Control", redirectCacheControl)
+		return c.Redirect(http.StatusFound, "/authors/"+url.PathEscape(u.Username))
+	}
+
+	profile, err := a.UserUsecase.GetProfile(ctx, u.ID)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	body, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	h := c.Response().Header()
+	h.Set("Cache-Control", authorCacheControl)
+	h.Set("ETag", etag)
+	if etagMatch(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSONBlob(http.StatusOK, body)
+}
+
+// etagMatch tells whether the If-None-Match header lists etag, weak validators included
+func etagMatch(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
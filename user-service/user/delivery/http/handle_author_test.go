@@ -0,0 +1,63 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userHttp "github.com/diantanjung/blogo/user-service/user/delivery/http"
+)
+
+func TestGetAuthor(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	dias := domain.User{ID: 1, Username: "dias", Name: "Dias"}
+	mockUCase.On("GetByUsername", mock.Anything, "dias").Return(dias, nil)
+	mockUCase.On("GetByUsername", mock.Anything, "Dias").Return(dias, nil)
+	mockUCase.On("GetByUsername", mock.Anything, "diantanjung").Return(dias, nil)
+	mockUCase.On("GetByUsername", mock.Anything, "nobody").Return(domain.User{}, domain.ErrNotFound)
+	mockUCase.On("GetProfile", mock.Anything, int64(1)).Return(domain.Profile{UserID: 1, Username: "dias", Name: "Dias", Bio: "Writes about Go",
+		Avatars: []domain.ProfileAvatar{}, Links: []domain.ProfileLink{}}, nil)
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/authors/dias", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fields))
+	assert.Equal(t, "Writes about Go", fields["bio"])
+	assert.NotContains(t, fields, "email")
+
+	req := httptest.NewRequest(echo.GET, "/authors/dias", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// a former username and another case redirect to the current username
+	for _, username := range []string{"diantanjung", "Dias"} {
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/authors/"+username, nil))
+		assert.Equal(t, http.StatusFound, rec.Code, username)
+		assert.Equal(t, "/authors/dias", rec.Header().Get(echo.HeaderLocation), username)
+		assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"), username)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/authors/nobody", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/user/delivery/http/middleware"
+)
+
+// AvatarPath is the route of the avatar uploads, its multipart body is not JSON
+const AvatarPath = "/users/:id/avatar"
+
+// avatarField is the name of the multipart part holding the image
+const avatarField = "avatar"
+
+// UpdateAvatar will store the image of the avatar part of a multipart/form-data body as the avatar
+// of the user. The part is streamed to the usecase, it is never buffered to disk.
+func (a *UserHandler) UpdateAvatar(c echo.Context) error {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	req := c.Request()
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType)); mediaType != echo.MIMEMultipartForm {
+		return c.JSON(http.StatusUnsupportedMediaType, ResponseError{Message: "Content-Type must be " + echo.MIMEMultipartForm})
+	}
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: err.Error()})
+	}
+	var part io.Reader
+	for part == nil {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return c.JSON(http.StatusBadRequest, ResponseError{Message: "Missing " + avatarField + " part"})
+		}
+		if err != nil {
+			return c.JSON(multipartStatusCode(err), ResponseError{Message: err.Error()})
+		}
+		if p.FormName() == avatarField {
+			part = p
+		}
+	}
+
+	ctx := req.Context()
+	profile, err := a.UserUsecase.UpdateAvatar(ctx, int64(idP), part)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// multipartStatusCode maps the errors reading a multipart body, bodies over the limit of the route are 413
+func multipartStatusCode(err error) int {
+	if middleware.BodyTooLarge(err) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,101 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userHttp "github.com/diantanjung/blogo/user-service/user/delivery/http"
+)
+
+func multipartBody(t *testing.T, field, content string) (*bytes.Buffer, string) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	require.NoError(t, w.WriteField("caption", "me"))
+	f, err := w.CreateFormFile(field, "me.png")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return &b, w.FormDataContentType()
+}
+
+func TestUpdateAvatar(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	profile := domain.Profile{UserID: 1, AvatarURL: "https://cdn.blogo.dev/avatars/1/512.jpg?v=1", Avatars: []domain.ProfileAvatar{{Size: 512, URL: "https://cdn.blogo.dev/avatars/1/512.jpg?v=1"}}}
+	mockUCase.On("UpdateAvatar", mock.Anything, int64(1), mock.MatchedBy(func(r io.Reader) bool {
+		b, _ := io.ReadAll(r)
+		return string(b) == "image"
+	})).Return(profile, nil).Once()
+	mockUCase.On("UpdateAvatar", mock.Anything, int64(2), mock.Anything).Return(domain.Profile{}, domain.ErrUnsupportedMediaType).Once()
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+
+	body, contentType := multipartBody(t, "avatar", "image")
+	req := httptest.NewRequest(echo.PUT, "/users/1/avatar", body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"avatars":[{"size":512`)
+
+	body, contentType = multipartBody(t, "avatar", "not an image")
+	req = httptest.NewRequest(echo.PUT, "/users/2/avatar", body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	body, contentType = multipartBody(t, "picture", "image")
+	req = httptest.NewRequest(echo.PUT, "/users/1/avatar", body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(echo.PUT, "/users/1/avatar", strings.NewReader("image"))
+	req.Header.Set(echo.HeaderContentType, "image/png")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	mockUCase.AssertExpectations(t)
+}
+
+func TestUpdateAvatarTooLarge(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("UpdateAvatar", mock.Anything, int64(1), mock.Anything).Return(domain.Profile{}, func(_ context.Context, _ int64, r io.Reader) error {
+		_, err := io.Copy(io.Discard, r)
+		return err
+	})
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+	e.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, 512)
+			return next(c)
+		}
+	})
+
+	body, contentType := multipartBody(t, "avatar", strings.Repeat("x", 1<<10))
+	req := httptest.NewRequest(echo.PUT, "/users/1/avatar", body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
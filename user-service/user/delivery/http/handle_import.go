@@ -0,0 +1,285 @@
+package http
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// CustomMethodsPath is the route of the custom methods on the users collection, /users:import and
+// /users:export. Echo cannot escape a colon in a path, so the method is routed as a parameter
+// holding ":import" or ":export".
+const CustomMethodsPath = "/users:method"
+
+const (
+	// MIMETextCSV is the media type of the csv imports and exports
+	MIMETextCSV = "text/csv"
+	// MIMEApplicationNDJSON is the media type of the newline delimited json imports and exports
+	MIMEApplicationNDJSON = "application/x-ndjson"
+)
+
+// maxImportLine is the longest line of a newline delimited json import
+const maxImportLine = 64 << 10
+
+// errBadImport is wrapped by the errors that make the whole import unreadable, such as a csv header
+// missing a column
+var errBadImport = errors.New("Import is not readable")
+
+// exportColumns are the columns of a csv export, in order
+var exportColumns = []string{"id", "username", "name", "email", "roles", "email_verified_at", "created_at", "updated_at"}
+
+// importColumns are the columns a csv import must have, roles is optional
+var importColumns = []string{"username", "name", "email", "password"}
+
+// CustomMethod will dispatch the requests on CustomMethodsPath to their handler
+func (a *UserHandler) CustomMethod(c echo.Context) error {
+	switch c.Request().Method + " " + c.Param("method") {
+	case echo.POST + " :import":
+		return a.Import(c)
+	case echo.GET + " :export":
+		return a.Export(c)
+	}
+	return c.JSON(http.StatusNotFound, ResponseError{Message: domain.ErrNotFound.Error()})
+}
+
+// Import will create the users of a csv or newline delimited json body, it is restricted to admins.
+// With dry_run=true the users are only checked. Every row that is not imported is listed in the report.
+func (a *UserHandler) Import(c echo.Context) error {
+	dryRun := false
+	if v := c.QueryParam("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseError{Message: domain.ErrBadParamInput.Error()})
+		}
+	}
+
+	req := c.Request()
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	var r domain.UserReader
+	switch mediaType {
+	case MIMETextCSV:
+		r = newCSVUserReader(req.Body)
+	case MIMEApplicationNDJSON:
+		r = newNDJSONUserReader(req.Body)
+	default:
+		return c.JSON(http.StatusUnsupportedMediaType, ResponseError{Message: "Content-Type must be " + MIMETextCSV + " or " + MIMEApplicationNDJSON})
+	}
+
+	ctx := req.Context()
+	report, err := a.UserUsecase.Import(ctx, r, dryRun)
+	switch {
+	case errors.Is(err, errBadImport):
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: err.Error()})
+	case err != nil:
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// Export will stream every user as newline delimited json, or as csv with format=csv. It is restricted to admins.
+// An error after the first user can only be logged, the client sees a truncated export.
+func (a *UserHandler) Export(c echo.Context) error {
+	var w userWriter
+	res := c.Response()
+	switch c.QueryParam("format") {
+	case "", "ndjson":
+		w = &ndjsonUserWriter{enc: json.NewEncoder(res)}
+		res.Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	case "csv":
+		w = &csvUserWriter{w: csv.NewWriter(res)}
+		res.Header().Set(echo.HeaderContentType, MIMETextCSV+"; charset=utf-8")
+	default:
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: domain.ErrBadParamInput.Error()})
+	}
+
+	ctx := c.Request().Context()
+	err := a.UserUsecase.Export(ctx, func(u domain.User) error {
+		if !res.Committed {
+			res.WriteHeader(http.StatusOK)
+			if err := w.Header(); err != nil {
+				return err
+			}
+		}
+		return w.Write(u)
+	})
+	if err != nil && !res.Committed {
+		res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithField("handler", "export").Error(err)
+		return nil
+	}
+
+	if !res.Committed {
+		res.WriteHeader(http.StatusOK)
+		if err = w.Header(); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+type csvUserReader struct {
+	r    *csv.Reader
+	cols map[string]int
+}
+
+func newCSVUserReader(r io.Reader) *csvUserReader {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	return &csvUserReader{r: cr}
+}
+
+func (r *csvUserReader) Read() (domain.User, error) {
+	if r.cols == nil {
+		if err := r.readHeader(); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	record, err := r.r.Read()
+	if err == io.EOF {
+		return domain.User{}, err
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return domain.User{}, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	u := domain.User{
+		Username: record[r.cols["username"]],
+		Name:     record[r.cols["name"]],
+		Email:    record[r.cols["email"]],
+		Password: record[r.cols["password"]],
+	}
+	if i, ok := r.cols["roles"]; ok {
+		for _, role := range strings.Split(record[i], ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				u.Roles = append(u.Roles, domain.Role(role))
+			}
+		}
+	}
+	return u, nil
+}
+
+func (r *csvUserReader) readHeader() error {
+	header, err := r.r.Read()
+	if err == io.EOF {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errBadImport, err)
+	}
+
+	r.cols = make(map[string]int, len(header))
+	for i, name := range header {
+		r.cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := r.cols[name]; !ok {
+			return fmt.Errorf("%w: the csv header must have the columns %s", errBadImport, strings.Join(importColumns, ", "))
+		}
+	}
+	return nil
+}
+
+type ndjsonUserReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newNDJSONUserReader(r io.Reader) *ndjsonUserReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), maxImportLine)
+	return &ndjsonUserReader{s: s}
+}
+
+func (r *ndjsonUserReader) Read() (domain.User, error) {
+	for r.s.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.s.Text())
+		if line == "" {
+			continue
+		}
+		var u domain.User
+		if err := json.Unmarshal([]byte(line), &u); err != nil {
+			return domain.User{}, fmt.Errorf("%w: line %d: %v", domain.ErrBadParamInput, r.line, err)
+		}
+		return u, nil
+	}
+
+	err := r.s.Err()
+	if err == bufio.ErrTooLong {
+		return domain.User{}, fmt.Errorf("%w: line %d is longer than %d bytes", errBadImport, r.line+1, maxImportLine)
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+	return domain.User{}, io.EOF
+}
+
+// userWriter writes the users of an export
+type userWriter interface {
+	Header() error
+	Write(u domain.User) error
+	Flush() error
+}
+
+type ndjsonUserWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonUserWriter) Header() error { return nil }
+
+func (w *ndjsonUserWriter) Write(u domain.User) error { return w.enc.Encode(u) }
+
+func (w *ndjsonUserWriter) Flush() error { return nil }
+
+type csvUserWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvUserWriter) Header() error { return w.w.Write(exportColumns) }
+
+func (w *csvUserWriter) Write(u domain.User) error {
+	roles := make([]string, len(u.Roles))
+	for i, r := range u.Roles {
+		roles[i] = string(r)
+	}
+	verifiedAt := ""
+	if u.EmailVerifiedAt != nil {
+		verifiedAt = u.EmailVerifiedAt.Format(time.RFC3339)
+	}
+	return w.w.Write([]string{
+		strconv.FormatInt(u.ID, 10),
+		u.Username,
+		u.Name,
+		u.Email,
+		strings.Join(roles, ","),
+		verifiedAt,
+		u.CreatedAt.Format(time.RFC3339),
+		u.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (w *csvUserWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
@@ -0,0 +1,191 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userHttp "github.com/diantanjung/blogo/user-service/user/delivery/http"
+)
+
+// readAll will drain the reader passed to Import, as the usecase does
+func readAll(r domain.UserReader) (users []domain.User, errs []error, err error) {
+	for {
+		u, err := r.Read()
+		if err == io.EOF {
+			return users, errs, nil
+		}
+		if errors.Is(err, domain.ErrBadParamInput) {
+			errs = append(errs, err)
+			continue
+		}
+		if err != nil {
+			return users, errs, err
+		}
+		users = append(users, u)
+	}
+}
+
+func serveImport(t *testing.T, us domain.UserUsecase, contentType, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	userHttp.NewUsersHandler(e, us)
+	req := httptest.NewRequest(echo.POST, "/users:import", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImportCSV(t *testing.T) {
+	var users []domain.User
+	var rowErrs []error
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Import", mock.Anything, mock.Anything, false).Return(domain.ImportReport{Total: 3, Imported: 2}, nil).Run(func(args mock.Arguments) {
+		var err error
+		users, rowErrs, err = readAll(args.Get(1).(domain.UserReader))
+		require.NoError(t, err)
+	})
+
+	body := "Email,Username,Name,Password,Roles\n" +
+		"dias@gmail.com,dias,Dias,ASDF1234,\"author, reader\"\n" +
+		"broken,row\n" +
+		"tanjung@gmail.com,tanjung,\"Tanjung, D\",ASDF1234,\n"
+	rec := serveImport(t, mockUCase, "text/csv; charset=utf-8", body)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.Len(t, users, 2)
+	assert.Equal(t, domain.User{Username: "dias", Name: "Dias", Email: "dias@gmail.com", Password: "ASDF1234",
+		Roles: []domain.Role{domain.RoleAuthor, domain.RoleReader}}, users[0])
+	assert.Equal(t, "Tanjung, D", users[1].Name)
+	assert.Nil(t, users[1].Roles)
+	require.Len(t, rowErrs, 1)
+	assert.Contains(t, rowErrs[0].Error(), "wrong number of fields")
+
+	var report domain.ImportReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.Imported)
+}
+
+func TestImportNDJSON(t *testing.T) {
+	var users []domain.User
+	var rowErrs []error
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Import", mock.Anything, mock.Anything, true).Return(domain.ImportReport{DryRun: true}, nil).Run(func(args mock.Arguments) {
+		var err error
+		users, rowErrs, err = readAll(args.Get(1).(domain.UserReader))
+		require.NoError(t, err)
+	})
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+	body := `{"username":"dias","name":"Dias","email":"dias@gmail.com","password":"ASDF1234","roles":["author"]}` + "\n\n" +
+		`{"username":` + "\n" +
+		`{"username":"tanjung","name":"Tanjung","email":"tanjung@gmail.com","password":"ASDF1234"}`
+	req := httptest.NewRequest(echo.POST, "/users:import?dry_run=1", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, userHttp.MIMEApplicationNDJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.Len(t, users, 2)
+	assert.Equal(t, []domain.Role{domain.RoleAuthor}, users[0].Roles)
+	assert.Equal(t, "tanjung", users[1].Username)
+	require.Len(t, rowErrs, 1)
+	assert.Contains(t, rowErrs[0].Error(), "line 3")
+}
+
+// readingUsecase drains the import like the usecase does, and fails with the error of the reader
+type readingUsecase struct {
+	mocks.UserUsecase
+}
+
+func (u *readingUsecase) Import(ctx context.Context, r domain.UserReader, dryRun bool) (domain.ImportReport, error) {
+	_, _, err := readAll(r)
+	return domain.ImportReport{}, err
+}
+
+func TestImportRejected(t *testing.T) {
+	rec := serveImport(t, new(mocks.UserUsecase), echo.MIMEApplicationJSON, `[]`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = serveImport(t, new(readingUsecase), userHttp.MIMETextCSV, "username,name,email\ndias,Dias,dias@gmail.com\n")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "password")
+
+	rec = serveImport(t, new(readingUsecase), userHttp.MIMEApplicationNDJSON, `{"name":"`+strings.Repeat("a", 70000)+`"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	forbidden := new(mocks.UserUsecase)
+	forbidden.On("Import", mock.Anything, mock.Anything, false).Return(domain.ImportReport{}, domain.ErrForbidden)
+	rec = serveImport(t, forbidden, userHttp.MIMETextCSV, "username,name,email,password\n")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestExport(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []domain.User{
+		{ID: 1, Username: "dias", Name: "Dias", Email: "dias@gmail.com", Roles: []domain.Role{domain.RoleAuthor, domain.RoleReader}, CreatedAt: now, UpdatedAt: now},
+		{ID: 2, Username: "tanjung", Name: "Tanjung, D", Email: "tanjung@gmail.com", EmailVerifiedAt: &now, CreatedAt: now, UpdatedAt: now},
+	}
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Export", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(domain.User) error)
+		for _, u := range users {
+			require.NoError(t, fn(u))
+		}
+	}).Return(nil)
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/users:export", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, userHttp.MIMEApplicationNDJSON, rec.Header().Get(echo.HeaderContentType))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	var u domain.User
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &u))
+	assert.Equal(t, "tanjung", u.Username)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/users:export?format=csv", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "id,username,name,email,roles,email_verified_at,created_at,updated_at\n"+
+		"1,dias,Dias,dias@gmail.com,\"author,reader\",,2020-01-02T03:04:05Z,2020-01-02T03:04:05Z\n"+
+		"2,tanjung,\"Tanjung, D\",tanjung@gmail.com,,2020-01-02T03:04:05Z,2020-01-02T03:04:05Z,2020-01-02T03:04:05Z\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/users:export?format=xml", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/users:frobnicate", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestExportForbidden(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Export", mock.Anything, mock.Anything).Return(domain.ErrForbidden)
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/users:export", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationJSONCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+	assert.JSONEq(t, `{"message":"`+domain.ErrForbidden.Error()+`"}`, rec.Body.String())
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// swaggerUIVersion is the swagger-ui-dist release the docs page loads from unpkg
+const swaggerUIVersion = "5.11.8"
+
+// docsContentSecurityPolicy lets the docs page load Swagger UI from unpkg
+const docsContentSecurityPolicy = "default-src 'self'; script-src 'self' https://unpkg.com; style-src 'self' https://unpkg.com; img-src 'self' data:; frame-ancestors 'none'; base-uri 'none'"
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Blogo User Service API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+  <script src="/docs/init.js"></script>
+</body>
+</html>
+`
+
+// the init script is served from the API origin so the page needs no inline script
+const docsInit = `window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+`
+
+// NewOpenAPIHandler will serve the OpenAPI document of the user API at /openapi.json and a Swagger UI page at /docs
+func NewOpenAPIHandler(e *echo.Echo) {
+	e.GET("/openapi.json", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, openAPISpec)
+	})
+	e.GET("/docs", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentSecurityPolicy, docsContentSecurityPolicy)
+		return c.HTML(http.StatusOK, docsPage)
+	})
+	e.GET("/docs/init.js", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "application/javascript; charset=utf-8", []byte(docsInit))
+	})
+}
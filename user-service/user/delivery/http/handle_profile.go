@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/user/delivery/http/middleware"
+)
+
+// GetProfile will get the public profile of the user, anyone can read it
+func (a *UserHandler) GetProfile(c echo.Context) error {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	ctx := c.Request().Context()
+	profile, err := a.UserUsecase.GetProfile(ctx, int64(idP))
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile will replace the profile of the user by given request body
+func (a *UserHandler) UpdateProfile(c echo.Context) (err error) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	var profile domain.Profile
+	err = c.Bind(&profile)
+	if err != nil {
+		return middleware.BindError(c, err)
+	}
+	profile.UserID = int64(idP)
+
+	ctx := c.Request().Context()
+	err = a.UserUsecase.UpdateProfile(ctx, &profile)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
@@ -0,0 +1,64 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userHttp "github.com/diantanjung/blogo/user-service/user/delivery/http"
+)
+
+func TestGetProfile(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("GetProfile", mock.Anything, int64(1)).Return(domain.Profile{UserID: 1, Username: "dias", Links: []domain.ProfileLink{}}, nil)
+	mockUCase.On("GetProfile", mock.Anything, int64(2)).Return(domain.Profile{}, domain.ErrNotFound)
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/users/1/profile", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "email")
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fields))
+	assert.Equal(t, "dias", fields["username"])
+	assert.Equal(t, []interface{}{}, fields["links"])
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/users/2/profile", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("UpdateProfile", mock.Anything, mock.MatchedBy(func(p *domain.Profile) bool {
+		return p.UserID == 1 && p.Bio == "Writes about Go" && len(p.Links) == 1 && p.Preferences.Theme == "dark"
+	})).Return(nil)
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+
+	body := `{"user_id":7,"bio":"Writes about Go","links":[{"label":"GitHub","url":"https://github.com/diantanjung"}],"preferences":{"theme":"dark"}}`
+	req := httptest.NewRequest(echo.PUT, "/users/1/profile", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUCase.AssertExpectations(t)
+
+	req = httptest.NewRequest(echo.PUT, "/users/1/profile", strings.NewReader(`{"bio":`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
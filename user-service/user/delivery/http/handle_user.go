@@ -1,12 +1,15 @@
 package http
 
 import (
-	"github.com/diantanjung/blogo/user-service/domain"
-	"github.com/labstack/echo"
-	"github.com/sirupsen/logrus"
+	"context"
 	"net/http"
 	"strconv"
-	
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/user/delivery/http/middleware"
 )
 
 // ResponseError represent the reseponse error struct
@@ -24,9 +27,19 @@ func NewUsersHandler(e *echo.Echo, us domain.UserUsecase) {
 	}
 	e.GET("/users", handler.Fetch)
 	e.POST("/users", handler.Store)
+	e.POST(CustomMethodsPath, handler.CustomMethod)
+	e.GET(CustomMethodsPath, handler.CustomMethod)
+	e.POST("/users/verify", handler.VerifyEmail)
+	e.POST("/users/verify/resend", handler.ResendVerification)
 	e.GET("/users/:id", handler.GetByID)
 	e.PATCH("/users/:id", handler.Update)
 	e.DELETE("/users/:id", handler.Delete)
+	e.PUT("/users/:id/password", handler.ChangePassword)
+	e.GET("/users/:id/audit", handler.FetchAudit)
+	e.GET("/users/:id/profile", handler.GetProfile)
+	e.PUT("/users/:id/profile", handler.UpdateProfile)
+	e.PUT(AvatarPath, handler.UpdateAvatar)
+	e.GET("/authors/:username", handler.GetAuthor)
 }
 
 func (a *UserHandler) Fetch(c echo.Context) error {
@@ -36,12 +49,30 @@ func (a *UserHandler) Fetch(c echo.Context) error {
 	ctx := c.Request().Context()
 	users, nextCursor, err := a.UserUsecase.Fetch(ctx, cursor, int64(num))
 	if err != nil {
-		return c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
 	}
 	c.Response().Header().Set(`X-Cursor`, nextCursor)
 	return c.JSON(http.StatusOK, users)
 }
 
+// FetchAudit will list the audit log of the user, it is restricted to admins
+func (a *UserHandler) FetchAudit(c echo.Context) error {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	num, _ := strconv.Atoi(c.QueryParam("num"))
+	cursor := c.QueryParam("cursor")
+	ctx := c.Request().Context()
+	entries, nextCursor, err := a.UserUsecase.FetchAudit(ctx, int64(idP), cursor, int64(num))
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	c.Response().Header().Set(`X-Cursor`, nextCursor)
+	return c.JSON(http.StatusOK, entries)
+}
+
 // GetByID will get user by given id
 func (a *UserHandler) GetByID(c echo.Context) error {
 	idP, err := strconv.Atoi(c.Param("id"))
@@ -54,7 +85,7 @@ func (a *UserHandler) GetByID(c echo.Context) error {
 
 	user, err := a.UserUsecase.GetByID(ctx, id)
 	if err != nil {
-		return c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, user)
@@ -65,13 +96,13 @@ func (a *UserHandler) Store(c echo.Context) (err error) {
 	var user domain.User
 	err = c.Bind(&user)
 	if err != nil {
-		return c.JSON(http.StatusUnprocessableEntity, err.Error())
+		return middleware.BindError(c, err)
 	}
 
 	ctx := c.Request().Context()
 	err = a.UserUsecase.Store(ctx, &user)
 	if err != nil {
-		return c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusCreated, user)
@@ -79,16 +110,22 @@ func (a *UserHandler) Store(c echo.Context) (err error) {
 
 // Update will store the user by given request body
 func (a *UserHandler) Update(c echo.Context) (err error) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
 	var user domain.User
 	err = c.Bind(&user)
 	if err != nil {
-		return c.JSON(http.StatusUnprocessableEntity, err.Error())
+		return middleware.BindError(c, err)
 	}
+	user.ID = int64(idP)
 
 	ctx := c.Request().Context()
 	err = a.UserUsecase.Update(ctx, &user)
 	if err != nil {
-		return c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusCreated, user)
@@ -106,18 +143,79 @@ func (a *UserHandler) Delete(c echo.Context) error {
 
 	err = a.UserUsecase.Delete(ctx, id)
 	if err != nil {
-		return c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-func getStatusCode(err error) int {
+// ChangePassword will set a new password for the user after checking the current one
+func (a *UserHandler) ChangePassword(c echo.Context) (err error) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	var req domain.ChangePasswordRequest
+	err = c.Bind(&req)
+	if err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	err = a.UserUsecase.ChangePassword(ctx, int64(idP), req)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// VerifyEmail will mark the email of the user the given token was issued for as verified
+func (a *UserHandler) VerifyEmail(c echo.Context) (err error) {
+	var body struct {
+		Token string `json:"token"`
+	}
+	err = c.Bind(&body)
+	if err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	err = a.UserUsecase.VerifyEmail(ctx, body.Token)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ResendVerification will email another verification link. The response does not tell whether the email exists.
+func (a *UserHandler) ResendVerification(c echo.Context) (err error) {
+	var req domain.ResendVerificationRequest
+	err = c.Bind(&req)
+	if err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	err = a.UserUsecase.ResendVerification(ctx, req)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func getStatusCode(ctx context.Context, err error) int {
 	if err == nil {
 		return http.StatusOK
 	}
 
-	logrus.Error(err)
+	logging.FromContext(ctx).Error(err)
+	if middleware.BodyTooLarge(err) {
+		return http.StatusRequestEntityTooLarge
+	}
 	switch err {
 	case domain.ErrInternalServerError:
 		return http.StatusInternalServerError
@@ -125,6 +223,18 @@ func getStatusCode(err error) int {
 		return http.StatusNotFound
 	case domain.ErrConflict:
 		return http.StatusConflict
+	case domain.ErrBadParamInput, domain.ErrInvalidToken, domain.ErrWeakPassword, domain.ErrPasswordReused:
+		return http.StatusBadRequest
+	case domain.ErrUnauthorized, domain.ErrInvalidCredentials:
+		return http.StatusUnauthorized
+	case domain.ErrForbidden, domain.ErrEmailNotVerified, domain.ErrPasswordMismatch:
+		return http.StatusForbidden
+	case domain.ErrTooManyAttempts:
+		return http.StatusTooManyRequests
+	case domain.ErrTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case domain.ErrUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
 	default:
 		return http.StatusInternalServerError
 	}
@@ -185,3 +185,114 @@ func TestDelete(t *testing.T) {
 	mockUCase.AssertExpectations(t)
 
 }
+
+func TestDeleteForbidden(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Delete", mock.Anything, int64(2)).Return(domain.ErrForbidden)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.DELETE, "/users/2", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("users/:id")
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	handler := userHttp.UserHandler{
+		UserUsecase: mockUCase,
+	}
+	err = handler.Delete(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("VerifyEmail", mock.Anything, "good").Return(nil)
+	mockUCase.On("VerifyEmail", mock.Anything, "expired").Return(domain.ErrInvalidToken)
+
+	handler := userHttp.UserHandler{
+		UserUsecase: mockUCase,
+	}
+	e := echo.New()
+
+	for token, code := range map[string]int{"good": http.StatusNoContent, "expired": http.StatusBadRequest} {
+		req, err := http.NewRequest(echo.POST, "/users/verify", strings.NewReader(`{"token":"`+token+`"}`))
+		assert.NoError(t, err)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		err = handler.VerifyEmail(c)
+		require.NoError(t, err)
+		assert.Equal(t, code, rec.Code)
+	}
+	mockUCase.AssertExpectations(t)
+}
+
+func TestChangePassword(t *testing.T) {
+	req := domain.ChangePasswordRequest{CurrentPassword: "ASDF1234", NewPassword: "QWER5678"}
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("ChangePassword", mock.Anything, int64(1), req).Return(domain.ErrPasswordReused)
+
+	j, err := json.Marshal(req)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	r, err := http.NewRequest(echo.PUT, "/users/1/password", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+	r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(r, rec)
+	c.SetPath("users/:id/password")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	handler := userHttp.UserHandler{
+		UserUsecase: mockUCase,
+	}
+	err = handler.ChangePassword(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestFetchAudit(t *testing.T) {
+	entries := []domain.AuditEntry{{ID: 1, Action: domain.AuditUserCreated, TargetUserID: 7}}
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("FetchAudit", mock.Anything, int64(7), "abc", int64(5)).Return(entries, "def", nil)
+	mockUCase.On("FetchAudit", mock.Anything, int64(8), "", int64(0)).Return(nil, "", domain.ErrForbidden)
+
+	handler := userHttp.UserHandler{
+		UserUsecase: mockUCase,
+	}
+	e := echo.New()
+
+	req, err := http.NewRequest(echo.GET, "/users/7/audit?num=5&cursor=abc", nil)
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/users/:id/audit")
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+	err = handler.FetchAudit(c)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "def", rec.Header().Get("X-Cursor"))
+
+	req, err = http.NewRequest(echo.GET, "/users/8/audit", nil)
+	assert.NoError(t, err)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetPath("/users/:id/audit")
+	c.SetParamNames("id")
+	c.SetParamValues("8")
+	err = handler.FetchAudit(c)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// Authenticate will resolve the bearer token of the request into a domain.Actor stored in the request context.
+// Requests without a token go through anonymously, the usecase decides whether that is allowed.
+func (m *GoMiddleware) Authenticate(au domain.AuthUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if header == "" {
+				return next(c)
+			}
+
+			token := strings.TrimPrefix(header, "Bearer ")
+			if token == header {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"message": domain.ErrUnauthorized.Error()})
+			}
+
+			ctx := c.Request().Context()
+			actor, err := au.Authenticate(ctx, token)
+			if err != nil {
+				status := http.StatusInternalServerError
+				if err == domain.ErrUnauthorized {
+					status = http.StatusUnauthorized
+				}
+				return c.JSON(status, map[string]string{"message": err.Error()})
+			}
+
+			c.Set(UserIDKey, actor.UserID)
+			c.SetRequest(c.Request().WithContext(domain.NewContextWithActor(ctx, actor)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// ParseTrustedProxies will parse the addresses of the proxies in front of the service, IPs or CIDRs
+// such as 10.0.0.0/8
+func ParseTrustedProxies(list []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, s := range list {
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q: invalid IP", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %v", s, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func (m *GoMiddleware) trusted(ip net.IP) bool {
+	for _, n := range m.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the IP of the client of r. The X-Forwarded-For and X-Real-IP headers are only
+// believed when they were set by a trusted proxy, anyone else can write them.
+func (m *GoMiddleware) clientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if ip := net.ParseIP(peer); ip == nil || !m.trusted(ip) {
+		return peer
+	}
+
+	// every proxy appends the address it got the request from, the client is the last address
+	// not added by a trusted proxy
+	if xff := r.Header.Get(echo.HeaderXForwardedFor); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			ip := net.ParseIP(hop)
+			if ip == nil {
+				break
+			}
+			if !m.trusted(ip) || i == 0 {
+				return hop
+			}
+		}
+	}
+	if ip := net.ParseIP(r.Header.Get(echo.HeaderXRealIP)); ip != nil {
+		return ip.String()
+	}
+	return peer
+}
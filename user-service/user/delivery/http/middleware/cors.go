@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// CORSConfig is the cross-origin policy of the API
+type CORSConfig struct {
+	// AllowOrigins are exact origins such as https://blogo.dev, "*" for any origin,
+	// or a wildcard subdomain such as https://*.blogo.dev. No origin is allowed when empty.
+	AllowOrigins []string
+	// AllowMethods are answered to preflight requests, DefaultCORSMethods when empty
+	AllowMethods []string
+	// AllowHeaders are answered to preflight requests, the requested headers are allowed when empty
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response, not sent when zero
+	MaxAge time.Duration
+}
+
+// DefaultCORSMethods are the methods allowed when CORSConfig.AllowMethods is empty
+var DefaultCORSMethods = []string{echo.GET, echo.HEAD, echo.POST, echo.PUT, echo.PATCH, echo.DELETE}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for origin, or false when it is not allowed
+func (cfg CORSConfig) allowOrigin(origin string) (string, bool) {
+	origin = strings.ToLower(origin)
+	for _, o := range cfg.AllowOrigins {
+		o = strings.ToLower(o)
+		switch {
+		case o == "*":
+			if cfg.AllowCredentials {
+				// browsers reject the wildcard on credentialed requests
+				return origin, true
+			}
+			return "*", true
+		case o == origin:
+			return origin, true
+		case strings.Contains(o, "*") && matchSubdomain(o, origin):
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// matchSubdomain reports whether origin matches pattern, e.g. https://api.blogo.dev matches https://*.blogo.dev
+func matchSubdomain(pattern, origin string) bool {
+	parts := strings.SplitN(pattern, "*", 2)
+	prefix, suffix := parts[0], parts[1]
+	if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	sub := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(sub, "/:@") && !strings.HasPrefix(sub, ".") && !strings.HasSuffix(sub, ".")
+}
+
+// CORS will apply the configured cross-origin policy, and answer preflight requests without calling the handler
+func (m *GoMiddleware) CORS(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		h := c.Response().Header()
+		h.Add(echo.HeaderVary, echo.HeaderOrigin)
+
+		origin := req.Header.Get(echo.HeaderOrigin)
+		preflight := req.Method == echo.OPTIONS && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+		if origin == "" {
+			return next(c)
+		}
+
+		allowed, ok := m.cors.allowOrigin(origin)
+		if !ok {
+			if preflight {
+				return c.NoContent(http.StatusForbidden)
+			}
+			return next(c)
+		}
+
+		h.Set(echo.HeaderAccessControlAllowOrigin, allowed)
+		if m.cors.AllowCredentials {
+			h.Set(echo.HeaderAccessControlAllowCredentials, "true")
+		}
+		if !preflight {
+			if len(m.cors.ExposeHeaders) > 0 {
+				h.Set(echo.HeaderAccessControlExposeHeaders, strings.Join(m.cors.ExposeHeaders, ", "))
+			}
+			return next(c)
+		}
+
+		h.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+		h.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+		methods := m.cors.AllowMethods
+		if len(methods) == 0 {
+			methods = DefaultCORSMethods
+		}
+		h.Set(echo.HeaderAccessControlAllowMethods, strings.Join(methods, ", "))
+		if len(m.cors.AllowHeaders) > 0 {
+			h.Set(echo.HeaderAccessControlAllowHeaders, strings.Join(m.cors.AllowHeaders, ", "))
+		} else if requested := req.Header.Get(echo.HeaderAccessControlRequestHeaders); requested != "" {
+			h.Set(echo.HeaderAccessControlAllowHeaders, requested)
+		}
+		if m.cors.MaxAge > 0 {
+			h.Set(echo.HeaderAccessControlMaxAge, strconv.Itoa(int(m.cors.MaxAge.Seconds())))
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/sirupsen/logrus"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+const (
+	// HeaderXRequestID is the header used to propagate the request ID
+	HeaderXRequestID = "X-Request-ID"
+	// UserIDKey is the echo context key the authenticated user ID is stored under
+	UserIDKey = "user_id"
+)
+
+// RequestID will propagate the incoming X-Request-ID, or assign a new one,
+// and store it with the client IP and a request scoped logger in the request context
+func (m *GoMiddleware) RequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		id := req.Header.Get(HeaderXRequestID)
+		if id == "" || len(id) > 128 {
+			id = logging.NewRequestID()
+		}
+		c.Response().Header().Set(HeaderXRequestID, id)
+
+		ctx := logging.WithRequestID(req.Context(), id)
+		ctx = domain.NewContextWithClientIP(ctx, m.clientIP(req))
+		ctx = logging.NewContext(ctx, logrus.WithField("request_id", id))
+		c.SetRequest(req.WithContext(ctx))
+		return next(c)
+	}
+}
+
+// AccessLog will write one log line per request with its latency, status, route and user ID
+func (m *GoMiddleware) AccessLog(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		status := c.Response().Status
+		if err != nil {
+			status = http.StatusInternalServerError
+			if he, ok := err.(*echo.HTTPError); ok {
+				status = he.Code
+			}
+		}
+
+		fields := logrus.Fields{
+			"method":     c.Request().Method,
+			"route":      c.Path(),
+			"path":       c.Request().URL.Path,
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"remote_ip":  m.clientIP(c.Request()),
+		}
+		if userID := c.Get(UserIDKey); userID != nil {
+			fields[UserIDKey] = userID
+		}
+
+		entry := logging.FromContext(c.Request().Context()).WithFields(fields)
+		switch {
+		case status >= http.StatusInternalServerError:
+			entry.Error("request completed")
+		case status >= http.StatusBadRequest:
+			entry.Warn("request completed")
+		default:
+			entry.Info("request completed")
+		}
+		return err
+	}
+}
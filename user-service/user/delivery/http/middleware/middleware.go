@@ -1,21 +1,28 @@
 package middleware
 
-import "github.com/labstack/echo"
+import "net"
 
-// GoMiddleware represent the data-struct for middleware
-type GoMiddleware struct {
-	// another stuff , may be needed by middleware
+// Config configures the middleware
+type Config struct {
+	CORS     CORSConfig
+	Security SecurityConfig
+	// TrustedProxies are the proxies whose forwarding headers are believed, the client IP is
+	// the address of the peer when it is not one of them
+	TrustedProxies []*net.IPNet
 }
 
-// CORS will handle the CORS middleware
-func (m *GoMiddleware) CORS(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		c.Response().Header().Set("Access-Control-Allow-Origin", "*")
-		return next(c)
-	}
+// GoMiddleware represent the data-struct for middleware
+type GoMiddleware struct {
+	cors           CORSConfig
+	security       SecurityConfig
+	trustedProxies []*net.IPNet
 }
 
 // InitMiddleware initialize the middleware
-func InitMiddleware() *GoMiddleware {
-	return &GoMiddleware{}
+func InitMiddleware(cfg Config) *GoMiddleware {
+	return &GoMiddleware{
+		cors:           cfg.CORS,
+		security:       cfg.Security,
+		trustedProxies: cfg.TrustedProxies,
+	}
 }
@@ -3,27 +3,532 @@ package middleware_test
 import (
 	"net/http"
 	test "net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/logging"
 	"github.com/diantanjung/blogo/user-service/user/delivery/http/middleware"
+	"github.com/diantanjung/blogo/user-service/user/repository/memory"
 )
 
+func newCORSServer(cfg middleware.CORSConfig) *echo.Echo {
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{CORS: cfg})
+	e.Use(m.CORS)
+	e.GET("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.POST("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+	return e
+}
+
 func TestCORS(t *testing.T) {
+	e := newCORSServer(middleware.CORSConfig{
+		AllowOrigins:  []string{"https://blogo.dev", "https://*.blogo.dev"},
+		ExposeHeaders: []string{"X-Cursor"},
+	})
+
+	for origin, allowed := range map[string]bool{
+		"https://blogo.dev":           true,
+		"https://app.blogo.dev":       true,
+		"https://a.b.blogo.dev":       true,
+		"https://evil.dev":            false,
+		"http://app.blogo.dev":        false,
+		"https://blogo.dev.evil.dev":  false,
+		"https://evil.dev/.blogo.dev": false,
+	} {
+		req := test.NewRequest(echo.GET, "/users", nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		res := test.NewRecorder()
+		e.ServeHTTP(res, req)
+		require.Equal(t, http.StatusOK, res.Code, origin)
+		assert.Contains(t, res.Header()[echo.HeaderVary], echo.HeaderOrigin)
+		if allowed {
+			assert.Equal(t, origin, res.Header().Get(echo.HeaderAccessControlAllowOrigin), origin)
+			assert.Equal(t, "X-Cursor", res.Header().Get(echo.HeaderAccessControlExposeHeaders), origin)
+		} else {
+			assert.Empty(t, res.Header().Get(echo.HeaderAccessControlAllowOrigin), origin)
+		}
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	e := newCORSServer(middleware.CORSConfig{AllowOrigins: []string{"*"}})
+	req := test.NewRequest(echo.GET, "/users", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://any.dev")
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, "*", res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+
+	e = newCORSServer(middleware.CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true})
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, "https://any.dev", res.Header().Get(echo.HeaderAccessControlAllowOrigin), "the wildcard is not allowed with credentials")
+	assert.Equal(t, "true", res.Header().Get(echo.HeaderAccessControlAllowCredentials))
+}
+
+func TestCORSPreflight(t *testing.T) {
+	e := newCORSServer(middleware.CORSConfig{
+		AllowOrigins:     []string{"https://blogo.dev"},
+		AllowMethods:     []string{echo.GET, echo.POST},
+		AllowHeaders:     []string{echo.HeaderAuthorization, echo.HeaderContentType},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	req := test.NewRequest(echo.OPTIONS, "/users", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://blogo.dev")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, echo.POST)
+	req.Header.Set(echo.HeaderAccessControlRequestHeaders, "authorization, content-type")
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	require.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "https://blogo.dev", res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "GET, POST", res.Header().Get(echo.HeaderAccessControlAllowMethods))
+	assert.Equal(t, "Authorization, Content-Type", res.Header().Get(echo.HeaderAccessControlAllowHeaders))
+	assert.Equal(t, "true", res.Header().Get(echo.HeaderAccessControlAllowCredentials))
+	assert.Equal(t, "600", res.Header().Get(echo.HeaderAccessControlMaxAge))
+
+	req.Header.Set(echo.HeaderOrigin, "https://evil.dev")
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusForbidden, res.Code)
+	assert.Empty(t, res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+
+	// an OPTIONS request without Access-Control-Request-Method is not a preflight
+	req = test.NewRequest(echo.OPTIONS, "/users", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://blogo.dev")
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, res.Code)
+}
+
+func TestMetrics(t *testing.T) {
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{})
+	e.Use(m.Metrics)
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusNotFound)
+	})
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+
+	req := test.NewRequest(echo.GET, "/users/42", nil)
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	require.Equal(t, http.StatusNotFound, res.Code)
+
+	req = test.NewRequest(echo.GET, "/metrics", nil)
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Contains(t, res.Body.String(), `http_requests_total{code="404",method="GET",route="/users/:id"} 1`)
+}
+
+func TestTracing(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{})
+	e.Use(m.Tracing)
+	e.GET("/users/:id", func(c echo.Context) error {
+		assert.True(t, trace.SpanContextFromContext(c.Request().Context()).IsValid())
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := test.NewRequest(echo.GET, "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /users/:id", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+}
+
+func TestRequestID(t *testing.T) {
 	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{})
+	e.Use(m.RequestID)
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, logging.RequestID(c.Request().Context()))
+	})
+
 	req := test.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(middleware.HeaderXRequestID, "abc-123")
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, "abc-123", res.Header().Get(middleware.HeaderXRequestID))
+	assert.Equal(t, "abc-123", res.Body.String())
+
+	req = test.NewRequest(echo.GET, "/", nil)
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	generated := res.Header().Get(middleware.HeaderXRequestID)
+	assert.Len(t, generated, 32)
+	assert.Equal(t, generated, res.Body.String())
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	_, err = middleware.ParseTrustedProxies([]string{"proxy"})
+	assert.Error(t, err)
+
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{TrustedProxies: proxies})
+	e.Use(m.RequestID)
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, domain.ClientIPFromContext(c.Request().Context()))
+	})
+
+	cases := []struct {
+		name   string
+		remote string
+		xff    string
+		realIP string
+		want   string
+	}{
+		{name: "direct", remote: "203.0.113.7:4000", want: "203.0.113.7"},
+		{name: "spoofed forwarded for", remote: "203.0.113.7:4000", xff: "1.2.3.4", want: "203.0.113.7"},
+		{name: "spoofed real ip", remote: "203.0.113.7:4000", realIP: "1.2.3.4", want: "203.0.113.7"},
+		{name: "trusted proxy", remote: "10.0.0.2:4000", xff: "203.0.113.7", want: "203.0.113.7"},
+		{name: "forged hop before the proxies", remote: "10.0.0.2:4000", xff: "1.2.3.4, 203.0.113.7, 192.168.1.1", want: "203.0.113.7"},
+		{name: "real ip of trusted proxy", remote: "192.168.1.1:4000", realIP: "203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted proxy without header", remote: "10.0.0.2:4000", want: "10.0.0.2"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := test.NewRequest(echo.GET, "/", nil)
+			req.RemoteAddr = tc.remote
+			if tc.xff != "" {
+				req.Header.Set(echo.HeaderXForwardedFor, tc.xff)
+			}
+			if tc.realIP != "" {
+				req.Header.Set(echo.HeaderXRealIP, tc.realIP)
+			}
+			res := test.NewRecorder()
+			e.ServeHTTP(res, req)
+			assert.Equal(t, tc.want, res.Body.String())
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{})
+	e.Use(m.RequestID)
+	e.Use(m.AccessLog)
+	e.GET("/users/:id", func(c echo.Context) error {
+		c.Set(middleware.UserIDKey, int64(7))
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := test.NewRequest(echo.GET, "/users/7", nil)
+	req.Header.Set(middleware.HeaderXRequestID, "abc-123")
 	res := test.NewRecorder()
-	c := e.NewContext(req, res)
-	m := middleware.InitMiddleware()
+	e.ServeHTTP(res, req)
 
-	h := m.CORS(echo.HandlerFunc(func(c echo.Context) error {
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "abc-123", entry.Data["request_id"])
+	assert.Equal(t, "/users/:id", entry.Data["route"])
+	assert.Equal(t, http.StatusOK, entry.Data["status"])
+	assert.Equal(t, int64(7), entry.Data["user_id"])
+	assert.Contains(t, entry.Data, "latency_ms")
+}
+
+func TestAuthenticate(t *testing.T) {
+	actor := domain.Actor{UserID: 7, SessionID: "abc", Roles: []domain.Role{domain.RoleReader}}
+	au := new(mocks.AuthUsecase)
+	au.On("Authenticate", mock.Anything, "good").Return(actor, nil)
+	au.On("Authenticate", mock.Anything, "bad").Return(domain.Actor{}, domain.ErrUnauthorized)
+
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{})
+	e.Use(m.Authenticate(au))
+	e.GET("/", func(c echo.Context) error {
+		got, ok := domain.ActorFromContext(c.Request().Context())
+		if !ok {
+			return c.NoContent(http.StatusNoContent)
+		}
+		assert.Equal(t, actor, got)
 		return c.NoContent(http.StatusOK)
-	}))
+	})
 
-	err := h(c)
+	req := test.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer good")
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	req = test.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer bad")
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	req = test.NewRequest(echo.GET, "/", nil)
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNoContent, res.Code)
+}
+
+func TestParseRateLimitPolicies(t *testing.T) {
+	policies, err := middleware.ParseRateLimitPolicies("*=ip:300/1m; POST /users=ip:5/1m,api_key:100/1h")
+	require.NoError(t, err)
+	assert.Equal(t, []middleware.RateLimitPolicy{
+		{Method: "*", Route: "*", Identity: middleware.IdentityIP, Limit: domain.RateLimit{Burst: 300, Period: time.Minute}},
+		{Method: "POST", Route: "/users", Identity: middleware.IdentityIP, Limit: domain.RateLimit{Burst: 5, Period: time.Minute}},
+		{Method: "POST", Route: "/users", Identity: middleware.IdentityAPIKey, Limit: domain.RateLimit{Burst: 100, Period: time.Hour}},
+	}, policies)
+
+	for _, s := range []string{"/users", "/users=host:5/1m", "/users=ip:0/1m", "/users=ip:5/soon"} {
+		_, err = middleware.ParseRateLimitPolicies(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	policies, err := middleware.ParseRateLimitPolicies("POST /users=ip:2/1m;*=user:100/1m")
+	require.NoError(t, err)
+
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{})
+	e.Use(m.RateLimit(memory.NewMemoryRateLimitStore(time.Hour), policies))
+	e.POST("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+	e.GET("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for i, code := range []int{http.StatusCreated, http.StatusCreated, http.StatusTooManyRequests} {
+		req := test.NewRequest(echo.POST, "/users", nil)
+		res := test.NewRecorder()
+		e.ServeHTTP(res, req)
+		require.Equal(t, code, res.Code, "request %d", i)
+		assert.Equal(t, "2", res.Header().Get("RateLimit-Limit"))
+		if code == http.StatusTooManyRequests {
+			assert.Equal(t, "0", res.Header().Get("RateLimit-Remaining"))
+			assert.Equal(t, "30", res.Header().Get("Retry-After"))
+			assert.Equal(t, "60", res.Header().Get("RateLimit-Reset"))
+		}
+	}
+
+	// a forged X-Forwarded-For does not get the client a new bucket
+	req := test.NewRequest(echo.POST, "/users", nil)
+	req.Header.Set(echo.HeaderXForwardedFor, "1.2.3.4")
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+
+	// no policy applies to anonymous GET requests
+	req = test.NewRequest(echo.GET, "/users", nil)
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, res.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitAroundAuthenticate(t *testing.T) {
+	au := new(mocks.AuthUsecase)
+	au.On("Authenticate", mock.Anything, "good").Return(domain.Actor{UserID: 7}, nil)
+	policies, err := middleware.ParseRateLimitPolicies("*=ip:2/1m;*=user:5/1m")
 	require.NoError(t, err)
-	assert.Equal(t, "*", res.Header().Get("Access-Control-Allow-Origin"))
+	beforeAuth, afterAuth := middleware.SplitRateLimitPolicies(policies)
+	assert.Equal(t, []middleware.RateLimitPolicy{policies[0]}, beforeAuth)
+	assert.Equal(t, []middleware.RateLimitPolicy{policies[1]}, afterAuth)
+
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{})
+	store := memory.NewMemoryRateLimitStore(time.Hour)
+	e.Use(m.RateLimit(store, beforeAuth))
+	e.Use(m.Authenticate(au))
+	e.Use(m.RateLimit(store, afterAuth))
+	e.GET("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for i, code := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		req := test.NewRequest(echo.GET, "/users", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer good")
+		res := test.NewRecorder()
+		e.ServeHTTP(res, req)
+		require.Equal(t, code, res.Code, "request %d", i)
+		// the headers describe the IP policy, it is more restrictive than the user one
+		assert.Equal(t, "2", res.Header().Get("RateLimit-Limit"), "request %d", i)
+	}
+	// the limited request was turned away before its token was checked
+	au.AssertNumberOfCalls(t, "Authenticate", 2)
+}
+
+func TestSecureHeaders(t *testing.T) {
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{Security: middleware.SecurityConfig{HSTSMaxAge: time.Hour}})
+	e.Use(m.SecureHeaders)
+	e.GET("/users", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []string{})
+	})
+	e.GET("/docs", func(c echo.Context) error {
+		return c.HTML(http.StatusOK, "<html></html>")
+	})
+	e.GET("/embed", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentSecurityPolicy, "default-src 'self' https://cdn.example.com")
+		return c.HTML(http.StatusOK, "<html></html>")
+	})
+
+	req := test.NewRequest(echo.GET, "/users", nil)
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, "nosniff", res.Header().Get(echo.HeaderXContentTypeOptions))
+	assert.Equal(t, "DENY", res.Header().Get(echo.HeaderXFrameOptions))
+	assert.Equal(t, "no-referrer", res.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "max-age=3600; includeSubDomains", res.Header().Get(echo.HeaderStrictTransportSecurity))
+	assert.Empty(t, res.Header().Get(echo.HeaderContentSecurityPolicy))
+
+	req = test.NewRequest(echo.GET, "/docs", nil)
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, middleware.DefaultContentSecurityPolicy, res.Header().Get(echo.HeaderContentSecurityPolicy))
+
+	req = test.NewRequest(echo.GET, "/embed", nil)
+	res = test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, "default-src 'self' https://cdn.example.com", res.Header().Get(echo.HeaderContentSecurityPolicy))
+}
+
+func TestBodyLimitAndRequireJSON(t *testing.T) {
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{Security: middleware.SecurityConfig{BodyLimit: 16}})
+	e.Use(m.BodyLimit)
+	e.Use(m.RequireJSON("/users"))
+	e.POST("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+	e.POST("/users/:id/unlock", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	for body, tc := range map[string]struct {
+		contentType string
+		code        int
+	}{
+		`{"name":"dias"}`:                  {echo.MIMEApplicationJSONCharsetUTF8, http.StatusCreated},
+		`name=dias`:                        {echo.MIMEApplicationForm, http.StatusUnsupportedMediaType},
+		`{"name":"a much longer name"}`:    {echo.MIMEApplicationJSON, http.StatusRequestEntityTooLarge},
+		`{"name":"dias", "email":"a@b.c"}`: {"", http.StatusRequestEntityTooLarge},
+	} {
+		req := test.NewRequest(echo.POST, "/users", strings.NewReader(body))
+		if tc.contentType != "" {
+			req.Header.Set(echo.HeaderContentType, tc.contentType)
+		}
+		res := test.NewRecorder()
+		e.ServeHTTP(res, req)
+		assert.Equal(t, tc.code, res.Code, body)
+	}
+
+	// requests without a body don't need a Content-Type
+	req := test.NewRequest(echo.POST, "/users/1/unlock", nil)
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNoContent, res.Code)
+}
+
+func TestBindError(t *testing.T) {
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{Security: middleware.SecurityConfig{BodyLimit: 16}})
+	e.Use(m.BodyLimit)
+	e.POST("/users", func(c echo.Context) error {
+		var body map[string]string
+		if err := c.Bind(&body); err != nil {
+			return middleware.BindError(c, err)
+		}
+		return c.NoContent(http.StatusCreated)
+	})
+
+	for body, code := range map[string]int{
+		`{"name":"dias"}`:               http.StatusCreated,
+		`{"name":`:                      http.StatusUnprocessableEntity,
+		`{"name":"a much longer name"}`: http.StatusRequestEntityTooLarge,
+	} {
+		req := test.NewRequest(echo.POST, "/users", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		// without a Content-Length the limit is only reached while binding
+		req.ContentLength = -1
+		res := test.NewRecorder()
+		e.ServeHTTP(res, req)
+		assert.Equal(t, code, res.Code, body)
+	}
+}
+
+func TestRawBodyRoutes(t *testing.T) {
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{Security: middleware.SecurityConfig{
+		BodyLimit:     16,
+		RawBodyRoutes: []string{"/users:method"},
+		RawBodyLimit:  48,
+	}})
+	e.Use(m.BodyLimit)
+	e.Use(m.RequireJSON("/users"))
+	e.POST("/users:method", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for body, code := range map[string]int{
+		"username,name,email,password\n":                                    http.StatusOK,
+		"username,name,email,password\ndias,Dias,dias@gmail.com,ASDF1234\n": http.StatusRequestEntityTooLarge,
+	} {
+		req := test.NewRequest(echo.POST, "/users:import", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, "text/csv")
+		res := test.NewRecorder()
+		e.ServeHTTP(res, req)
+		assert.Equal(t, code, res.Code, body)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	e := echo.New()
+	m := middleware.InitMiddleware(middleware.Config{})
+	e.Use(m.Recover)
+	e.GET("/users/:id", func(c echo.Context) error {
+		panic("boom")
+	})
+
+	req := test.NewRequest(echo.GET, "/users/7", nil)
+	res := test.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.JSONEq(t, `{"message":"`+domain.ErrInternalServerError.Error()+`"}`, res.Body.String())
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "boom", entry.Data["panic"])
+	assert.Contains(t, entry.Data["stack"], "runtime/debug.Stack")
 }
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// HeaderXAPIKey is the header API clients identify themselves with
+const HeaderXAPIKey = "X-API-Key"
+
+// Identities a rate limit policy can count requests by
+const (
+	IdentityIP     = "ip"
+	IdentityUser   = "user"
+	IdentityAPIKey = "api_key"
+)
+
+// RateLimitPolicy limits the requests to a route per identity.
+// Method and Route may be "*" to match any, Route is the echo route template, e.g. /users/:id
+type RateLimitPolicy struct {
+	Method   string
+	Route    string
+	Identity string
+	Limit    domain.RateLimit
+}
+
+func (p RateLimitPolicy) matches(method, route string) bool {
+	return (p.Method == "*" || p.Method == method) && (p.Route == "*" || p.Route == route)
+}
+
+// identify returns the value requests are counted by, or false when the request has none,
+// e.g. an anonymous request for a user policy. ip is the client IP, as told by the trusted proxies.
+func (p RateLimitPolicy) identify(c echo.Context, ip string) (string, bool) {
+	switch p.Identity {
+	case IdentityIP:
+		return ip, true
+	case IdentityUser:
+		id, ok := c.Get(UserIDKey).(int64)
+		return strconv.FormatInt(id, 10), ok
+	case IdentityAPIKey:
+		key := c.Request().Header.Get(HeaderXAPIKey)
+		return key, key != ""
+	}
+	return "", false
+}
+
+// ParseRateLimitPolicies will parse policies from config, a ";" separated list of
+// "[METHOD] ROUTE=IDENTITY:BURST/PERIOD[,IDENTITY:BURST/PERIOD...]", e.g.
+//
+//	*=ip:300/1m;POST /users=ip:5/1m,api_key:100/1m;POST /auth/login=ip:20/1m
+func ParseRateLimitPolicies(s string) ([]RateLimitPolicy, error) {
+	var policies []RateLimitPolicy
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("rate limit %q: missing limits", entry)
+		}
+
+		method, route := "*", strings.TrimSpace(parts[0])
+		if fields := strings.Fields(route); len(fields) == 2 {
+			method, route = strings.ToUpper(fields[0]), fields[1]
+		}
+
+		for _, l := range strings.Split(parts[1], ",") {
+			p := RateLimitPolicy{Method: method, Route: route}
+			var limit string
+			idAndLimit := strings.SplitN(strings.TrimSpace(l), ":", 2)
+			if len(idAndLimit) != 2 {
+				return nil, fmt.Errorf("rate limit %q: invalid limit %q", entry, l)
+			}
+			p.Identity, limit = idAndLimit[0], idAndLimit[1]
+			switch p.Identity {
+			case IdentityIP, IdentityUser, IdentityAPIKey:
+			default:
+				return nil, fmt.Errorf("rate limit %q: unknown identity %q", entry, p.Identity)
+			}
+
+			burstAndPeriod := strings.SplitN(limit, "/", 2)
+			if len(burstAndPeriod) != 2 {
+				return nil, fmt.Errorf("rate limit %q: invalid limit %q", entry, l)
+			}
+			burst, err := strconv.ParseInt(burstAndPeriod[0], 10, 64)
+			if err != nil || burst < 1 {
+				return nil, fmt.Errorf("rate limit %q: invalid burst %q", entry, burstAndPeriod[0])
+			}
+			period, err := time.ParseDuration(burstAndPeriod[1])
+			if err != nil || period <= 0 {
+				return nil, fmt.Errorf("rate limit %q: invalid period %q", entry, burstAndPeriod[1])
+			}
+			p.Limit = domain.RateLimit{Burst: burst, Period: period}
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
+
+// SplitRateLimitPolicies will split policies into the ones counting requests by IP or API key, which apply
+// before Authenticate, and the user ones, which need the user Authenticate resolves
+func SplitRateLimitPolicies(policies []RateLimitPolicy) (beforeAuth, afterAuth []RateLimitPolicy) {
+	for _, p := range policies {
+		if p.Identity == IdentityUser {
+			afterAuth = append(afterAuth, p)
+		} else {
+			beforeAuth = append(beforeAuth, p)
+		}
+	}
+	return beforeAuth, afterAuth
+}
+
+// RateLimit will take a token from the bucket of every policy matching the request, and answer 429
+// with Retry-After when one of them is empty. The RateLimit-* headers describe the most restrictive policy.
+// Run it before Authenticate with the policies that do not need the user, so floods are turned away before
+// their tokens are checked, and after it with the user ones, see SplitRateLimitPolicies. The headers then
+// describe the most restrictive policy of both. Store failures let the request through.
+func (m *GoMiddleware) RateLimit(store domain.RateLimitStore, policies []RateLimitPolicy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := req.Context()
+			ip := m.clientIP(req)
+			now := time.Now()
+
+			var (
+				limited    bool
+				retryAfter float64
+				headerSet  bool
+				remaining  float64
+				limit      int64
+				reset      float64
+			)
+			for _, p := range policies {
+				if !p.matches(req.Method, c.Path()) {
+					continue
+				}
+				id, ok := p.identify(c, ip)
+				if !ok {
+					continue
+				}
+
+				key := fmt.Sprintf("%s %s|%s:%s", p.Method, p.Route, p.Identity, id)
+				tokens, allowed, err := store.Take(ctx, key, p.Limit, now)
+				if err != nil {
+					logging.FromContext(ctx).WithError(err).Error("rate limit store failed")
+					continue
+				}
+
+				rate := p.Limit.Rate()
+				if !allowed {
+					limited = true
+					retryAfter = math.Max(retryAfter, (1-tokens)/rate)
+				}
+				if !headerSet || tokens < remaining {
+					headerSet = true
+					remaining, limit = tokens, p.Limit.Burst
+					reset = (float64(p.Limit.Burst) - tokens) / rate
+				}
+			}
+
+			h := c.Response().Header()
+			// an earlier RateLimit may have set the headers of a more restrictive policy
+			if prev, err := strconv.ParseFloat(h.Get("RateLimit-Remaining"), 64); err == nil && prev <= math.Floor(remaining) {
+				headerSet = false
+			}
+			if headerSet {
+				h.Set("RateLimit-Limit", strconv.FormatInt(limit, 10))
+				h.Set("RateLimit-Remaining", strconv.FormatInt(int64(math.Max(math.Floor(remaining), 0)), 10))
+				h.Set("RateLimit-Reset", strconv.FormatInt(int64(math.Ceil(reset)), 10))
+			}
+			if limited {
+				c.Response().Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter)), 10))
+				return c.JSON(http.StatusTooManyRequests, map[string]string{"message": domain.ErrTooManyRequests.Error()})
+			}
+			return next(c)
+		}
+	}
+}
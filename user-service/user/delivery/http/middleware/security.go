@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// SecurityConfig configures the security headers and request limits
+type SecurityConfig struct {
+	// HSTSMaxAge is the max-age of Strict-Transport-Security, the header is not sent when zero
+	HSTSMaxAge time.Duration
+	// ContentSecurityPolicy is sent with HTML responses, DefaultContentSecurityPolicy when empty
+	ContentSecurityPolicy string
+	// FrameOptions is the X-Frame-Options value, DENY when empty
+	FrameOptions string
+	// ReferrerPolicy is the Referrer-Policy value, no-referrer when empty
+	ReferrerPolicy string
+	// BodyLimit is the maximum size of request bodies in bytes, not limited when zero
+	BodyLimit int64
+	// RawBodyRoutes are the routes, as registered, reading a body that is not JSON such as imports.
+	// RequireJSON lets them through and RawBodyLimit applies to them instead of BodyLimit.
+	RawBodyRoutes []string
+	// RawBodyLimit is the maximum size of the request bodies of RawBodyRoutes in bytes, not limited when zero
+	RawBodyLimit int64
+}
+
+func (s SecurityConfig) isRawBodyRoute(path string) bool {
+	for _, r := range s.RawBodyRoutes {
+		if r == path {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultContentSecurityPolicy only allows resources from the API origin
+const DefaultContentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'self'"
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// SecureHeaders will set the security headers on every response, and a Content-Security-Policy on HTML ones
+// unless the handler set its own
+func (m *GoMiddleware) SecureHeaders(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res := c.Response()
+		h := res.Header()
+		h.Set(echo.HeaderXContentTypeOptions, "nosniff")
+		h.Set(echo.HeaderXFrameOptions, orDefault(m.security.FrameOptions, "DENY"))
+		h.Set("Referrer-Policy", orDefault(m.security.ReferrerPolicy, "no-referrer"))
+		if m.security.HSTSMaxAge > 0 {
+			h.Set(echo.HeaderStrictTransportSecurity, fmt.Sprintf("max-age=%d; includeSubDomains", int64(m.security.HSTSMaxAge.Seconds())))
+		}
+		res.Before(func() {
+			if strings.HasPrefix(h.Get(echo.HeaderContentType), echo.MIMETextHTML) && h.Get(echo.HeaderContentSecurityPolicy) == "" {
+				h.Set(echo.HeaderContentSecurityPolicy, orDefault(m.security.ContentSecurityPolicy, DefaultContentSecurityPolicy))
+			}
+		})
+		return next(c)
+	}
+}
+
+// BodyLimit will reject request bodies larger than SecurityConfig.BodyLimit with 413
+func (m *GoMiddleware) BodyLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		limit := m.security.BodyLimit
+		if m.security.isRawBodyRoute(c.Path()) {
+			limit = m.security.RawBodyLimit
+		}
+		if limit <= 0 {
+			return next(c)
+		}
+
+		req := c.Request()
+		if req.ContentLength > limit {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"message": "Request body must not exceed " + strconv.FormatInt(limit, 10) + " bytes"})
+		}
+		// bodies without a Content-Length fail to read once they reach the limit, see BodyTooLarge
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+		return next(c)
+	}
+}
+
+// BodyTooLarge tells whether err comes from reading a body over the limit of BodyLimit, the errors of
+// echo.Context.Bind included
+func BodyTooLarge(err error) bool {
+	if he, ok := err.(*echo.HTTPError); ok && he.Internal != nil {
+		err = he.Internal
+	}
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
+
+// BindError will answer a request whose body failed to bind. A body over the limit of BodyLimit is 413,
+// like a Content-Length over it, any other error is 422.
+func BindError(c echo.Context, err error) error {
+	if BodyTooLarge(err) {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"message": err.Error()})
+	}
+	return c.JSON(http.StatusUnprocessableEntity, err.Error())
+}
+
+// RequireJSON will reject POST and PATCH requests with a body that is not JSON with 415,
+// on the routes starting with one of prefixes but SecurityConfig.RawBodyRoutes
+func (m *GoMiddleware) RequireJSON(prefixes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if (req.Method != echo.POST && req.Method != echo.PATCH) || req.ContentLength == 0 || !hasAnyPrefix(c.Path(), prefixes) || m.security.isRawBodyRoute(c.Path()) {
+				return next(c)
+			}
+
+			mediaType, _, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+			if err != nil || mediaType != echo.MIMEApplicationJSON {
+				return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"message": "Content-Type must be " + echo.MIMEApplicationJSON})
+			}
+			return next(c)
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Recover will turn a panic of the next handlers into a 500 ResponseError, logging the panic with its stack trace
+func (m *GoMiddleware) Recover(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) (err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			logging.FromContext(c.Request().Context()).
+				WithField("panic", fmt.Sprint(r)).
+				WithField("stack", string(debug.Stack())).
+				Error("recovered from panic")
+			if c.Response().Committed {
+				return
+			}
+			err = c.JSON(http.StatusInternalServerError, map[string]string{"message": domain.ErrInternalServerError.Error()})
+		}()
+		return next(c)
+	}
+}
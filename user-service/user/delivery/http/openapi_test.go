@@ -0,0 +1,196 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	userHttp "github.com/diantanjung/blogo/user-service/user/delivery/http"
+)
+
+func loadSpec(t *testing.T) *openapi3.T {
+	e := echo.New()
+	userHttp.NewOpenAPIHandler(e)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	doc, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	return doc
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc := loadSpec(t)
+
+	// the custom methods share a route, they are documented on their own path
+	customMethods := map[string]string{echo.POST: "/users:import", echo.GET: "/users:export"}
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, new(mocks.UserUsecase))
+	for _, r := range e.Routes() {
+		path := r.Path
+		if path == userHttp.CustomMethodsPath {
+			path = customMethods[r.Method]
+		}
+		for _, seg := range strings.Split(r.Path, "/") {
+			if strings.HasPrefix(seg, ":") {
+				path = strings.Replace(path, seg, "{"+seg[1:]+"}", 1)
+			}
+		}
+		item := doc.Paths.Find(path)
+		if assert.NotNil(t, item, "%s is not documented", path) {
+			assert.NotNil(t, item.GetOperation(r.Method), "%s %s is not documented", r.Method, path)
+		}
+	}
+}
+
+func TestOpenAPIContract(t *testing.T) {
+	doc := loadSpec(t)
+	router, err := legacy.NewRouter(doc)
+	require.NoError(t, err)
+
+	now := time.Now()
+	user := domain.User{ID: 1, Username: "dias", Name: "Dias", Email: "dias@gmail.com", Roles: []domain.Role{domain.RoleAuthor},
+		EmailVerifiedAt: &now, CreatedAt: now, UpdatedAt: now}
+	redacted := domain.User{ID: 2, Username: "tanjung", Name: "Tanjung", CreatedAt: now, UpdatedAt: now}
+	entries := []domain.AuditEntry{
+		{ID: 1, Action: domain.AuditUserCreated, TargetUserID: 1, Diff: map[string]domain.AuditChange{"name": {After: "Dias"}}, CreatedAt: now},
+		{ID: 2, ActorID: 1, Action: domain.AuditUserUpdated, TargetUserID: 1, IP: "10.0.0.1", RequestID: "abc",
+			Diff: map[string]domain.AuditChange{"password": {Before: "[REDACTED]", After: "[REDACTED]"}}, CreatedAt: now},
+	}
+
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Fetch", mock.Anything, "", int64(2)).Return([]domain.User{user, redacted}, "next", nil)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).Return(user, nil)
+	mockUCase.On("GetByID", mock.Anything, int64(9)).Return(domain.User{}, domain.ErrNotFound)
+	mockUCase.On("Store", mock.Anything, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) {
+		u := args.Get(1).(*domain.User)
+		u.ID, u.Password, u.Roles, u.CreatedAt, u.UpdatedAt = 3, "", []domain.Role{domain.RoleReader}, now, now
+	}).Return(nil)
+	mockUCase.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool { return u.ID == 1 })).Run(func(args mock.Arguments) {
+		u := args.Get(1).(*domain.User)
+		u.Roles, u.CreatedAt, u.UpdatedAt = user.Roles, now, now
+	}).Return(nil)
+	mockUCase.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool { return u.ID == 2 })).Return(domain.ErrForbidden)
+	mockUCase.On("Delete", mock.Anything, int64(1)).Return(nil)
+	mockUCase.On("ChangePassword", mock.Anything, int64(1), mock.Anything).Return(domain.ErrPasswordMismatch)
+	mockUCase.On("VerifyEmail", mock.Anything, "good").Return(nil)
+	mockUCase.On("VerifyEmail", mock.Anything, "expired").Return(domain.ErrInvalidToken)
+	mockUCase.On("FetchAudit", mock.Anything, int64(1), "", int64(0)).Return(entries, "", nil)
+	mockUCase.On("Import", mock.Anything, mock.Anything, true).Return(domain.ImportReport{DryRun: true, Total: 2, Imported: 1,
+		Failed: []domain.ImportFailure{{Row: 2, Username: "dias", Error: domain.ErrConflict.Error()}}}, nil)
+	mockUCase.On("Export", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(func(domain.User) error)(user)
+	}).Return(nil)
+	profile := domain.Profile{UserID: 1, Username: "dias", Name: "Dias", Bio: "Writes about Go", Website: "https://dias.dev",
+		Avatars: []domain.ProfileAvatar{}, Links: []domain.ProfileLink{{Label: "GitHub", URL: "https://github.com/diantanjung"}}, JoinedAt: &now, UpdatedAt: now,
+		Preferences: domain.ProfilePreferences{Theme: "dark", ShowJoinDate: true}}
+	mockUCase.On("GetProfile", mock.Anything, int64(1)).Return(profile, nil)
+	mockUCase.On("GetByUsername", mock.Anything, "dias").Return(user, nil)
+	mockUCase.On("GetByUsername", mock.Anything, "diantanjung").Return(user, nil)
+	mockUCase.On("GetByUsername", mock.Anything, "nobody").Return(domain.User{}, domain.ErrNotFound)
+	mockUCase.On("UpdateProfile", mock.Anything, mock.MatchedBy(func(p *domain.Profile) bool { return p.UserID == 1 })).Run(func(args mock.Arguments) {
+		*args.Get(1).(*domain.Profile) = profile
+	}).Return(nil)
+	mockUCase.On("UpdateProfile", mock.Anything, mock.MatchedBy(func(p *domain.Profile) bool { return p.UserID == 2 })).Return(domain.ErrBadParamInput)
+	withAvatar := profile
+	withAvatar.AvatarURL = "https://cdn.blogo.dev/avatars/1/256.jpg?v=1f2e"
+	withAvatar.Avatars = []domain.ProfileAvatar{{Size: 64, URL: "https://cdn.blogo.dev/avatars/1/64.jpg?v=1f2e"}, {Size: 256, URL: withAvatar.AvatarURL}}
+	mockUCase.On("UpdateAvatar", mock.Anything, int64(1), mock.Anything).Return(withAvatar, nil)
+	mockUCase.On("UpdateAvatar", mock.Anything, int64(2), mock.Anything).Return(domain.Profile{}, domain.ErrUnsupportedMediaType)
+
+	// the image parts are only checked to be files
+	openapi3filter.RegisterBodyDecoder("image/png", openapi3filter.FileBodyDecoder)
+	defer openapi3filter.UnregisterBodyDecoder("image/png")
+	avatarBody := "--avatar\r\nContent-Disposition: form-data; name=\"avatar\"; filename=\"me.png\"\r\nContent-Type: image/png\r\n\r\n" +
+		"\x89PNG\r\n\x1a\n\r\n--avatar--\r\n"
+
+	e := echo.New()
+	userHttp.NewUsersHandler(e, mockUCase)
+
+	for _, tc := range []struct {
+		method, path, body string
+		code               int
+		// invalid requests are outside the spec, only their response is checked
+		invalid bool
+	}{
+		{echo.GET, "/users?num=2", "", http.StatusOK, false},
+		{echo.GET, "/users/1", "", http.StatusOK, false},
+		{echo.GET, "/users/9", "", http.StatusNotFound, false},
+		{echo.GET, "/users/abc", "", http.StatusNotFound, true},
+		{echo.POST, "/users", `{"username":"new","name":"New","email":"new@gmail.com","password":"ASDF1234"}`, http.StatusCreated, false},
+		{echo.POST, "/users", `{"username":`, http.StatusUnprocessableEntity, true},
+		{echo.PATCH, "/users/1", `{"username":"dias","name":"Dias T","email":"dias@gmail.com"}`, http.StatusCreated, false},
+		{echo.PATCH, "/users/2", `{"username":"tanjung","name":"Tanjung","email":"t@gmail.com"}`, http.StatusForbidden, false},
+		{echo.DELETE, "/users/1", "", http.StatusNoContent, false},
+		{echo.PUT, "/users/1/password", `{"current_password":"wrong","new_password":"QWER5678"}`, http.StatusForbidden, false},
+		{echo.POST, "/users/verify", `{"token":"good"}`, http.StatusNoContent, false},
+		{echo.POST, "/users/verify", `{"token":"expired"}`, http.StatusBadRequest, false},
+		{echo.GET, "/users/1/audit", "", http.StatusOK, false},
+		{echo.POST, "/users:import?dry_run=true", "username,name,email,password\nnew,New,new@gmail.com,ASDF1234\n", http.StatusOK, false},
+		{echo.GET, "/users:export?format=csv", "", http.StatusOK, false},
+		{echo.GET, "/users/1/profile", "", http.StatusOK, false},
+		{echo.PUT, "/users/1/profile", `{"bio":"Writes about Go","website":"https://dias.dev","links":[{"label":"GitHub","url":"https://github.com/diantanjung"}],"preferences":{"theme":"dark","show_join_date":true}}`, http.StatusOK, false},
+		{echo.PUT, "/users/2/profile", `{"bio":"","links":null}`, http.StatusBadRequest, false},
+		{echo.PUT, "/users/1/avatar", avatarBody, http.StatusOK, false},
+		{echo.PUT, "/users/2/avatar", avatarBody, http.StatusUnsupportedMediaType, false},
+		{echo.GET, "/authors/dias", "", http.StatusOK, false},
+		{echo.GET, "/authors/diantanjung", "", http.StatusFound, false},
+		{echo.GET, "/authors/nobody", "", http.StatusNotFound, false},
+	} {
+		name := tc.method + " " + tc.path
+		req := httptest.NewRequest(tc.method, "http://localhost:9090"+tc.path, strings.NewReader(tc.body))
+		if strings.HasPrefix(tc.path, "/users:import") {
+			req.Header.Set(echo.HeaderContentType, userHttp.MIMETextCSV)
+		} else if strings.HasSuffix(tc.path, "/avatar") {
+			req.Header.Set(echo.HeaderContentType, "multipart/form-data; boundary=avatar")
+		} else if tc.body != "" {
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, tc.code, rec.Code, name)
+
+		route, pathParams, err := router.FindRoute(req)
+		require.NoError(t, err, name)
+		reqInput := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if !tc.invalid {
+			req.Body = io.NopCloser(strings.NewReader(tc.body))
+			assert.NoError(t, openapi3filter.ValidateRequest(context.Background(), reqInput), name)
+		}
+
+		err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: reqInput,
+			Status:                 rec.Code,
+			Header:                 rec.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+			Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+		})
+		assert.NoError(t, err, name)
+	}
+	mockUCase.AssertExpectations(t)
+}
@@ -2,29 +2,21 @@ package repository
 
 import (
 	"encoding/base64"
-	"time"
+	"strconv"
 )
 
-const (
-	timeFormat = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
-)
-
-// DecodeCursor will decode cursor from user for mysql
-func DecodeCursor(encodedTime string) (time.Time, error) {
-	byt, err := base64.StdEncoding.DecodeString(encodedTime)
+// DecodeIDCursor will decode a cursor of EncodeIDCursor
+func DecodeIDCursor(encodedID string) (int64, error) {
+	byt, err := base64.StdEncoding.DecodeString(encodedID)
 	if err != nil {
-		return time.Time{}, err
+		return 0, err
 	}
 
-	timeString := string(byt)
-	t, err := time.Parse(timeFormat, timeString)
-
-	return t, err
+	return strconv.ParseInt(string(byt), 10, 64)
 }
 
-// EncodeCursor will encode cursor from mysql to user
-func EncodeCursor(t time.Time) string {
-	timeString := t.Format(timeFormat)
-
-	return base64.StdEncoding.EncodeToString([]byte(timeString))
+// EncodeIDCursor will encode the id of the last row of a page as cursor. Tables with a serial id are paged by it,
+// a cursor from a timestamp loses the rows sharing it.
+func EncodeIDCursor(id int64) string {
+	return base64.StdEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
 }
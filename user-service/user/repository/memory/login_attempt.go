@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginAttempt
+}
+
+// NewMemoryLoginAttemptStore will create a domain.LoginAttemptStore kept in process memory.
+// Counters are not shared between replicas, use the psql store when running more than one.
+func NewMemoryLoginAttemptStore() domain.LoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: make(map[string]domain.LoginAttempt)}
+}
+
+func (m *memoryLoginAttemptStore) Get(ctx context.Context, key string) (domain.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.attempts[key], nil
+}
+
+func (m *memoryLoginAttemptStore) RegisterFailure(ctx context.Context, key string, at time.Time, window time.Duration) (domain.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a := m.attempts[key]
+	if at.Sub(a.LastFailureAt) > window {
+		a.Failures = 0
+	}
+	a.Key = key
+	a.Failures++
+	a.LastFailureAt = at
+	m.attempts[key] = a
+	return a, nil
+}
+
+func (m *memoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.attempts, key)
+	return nil
+}
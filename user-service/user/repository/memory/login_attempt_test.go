@@ -0,0 +1,34 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diantanjung/blogo/user-service/user/repository/memory"
+)
+
+func TestLoginAttemptStore(t *testing.T) {
+	s := memory.NewMemoryLoginAttemptStore()
+	ctx := context.TODO()
+	now := time.Now()
+
+	a, err := s.RegisterFailure(ctx, "ip:10.0.0.1", now, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), a.Failures)
+
+	a, err = s.RegisterFailure(ctx, "ip:10.0.0.1", now.Add(time.Minute), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), a.Failures)
+
+	a, err = s.RegisterFailure(ctx, "ip:10.0.0.1", now.Add(3*time.Hour), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), a.Failures, "failures older than the window are forgotten")
+
+	assert.NoError(t, s.Reset(ctx, "ip:10.0.0.1"))
+	a, err = s.Get(ctx, "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), a.Failures)
+}
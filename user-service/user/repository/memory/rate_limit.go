@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu       sync.Mutex
+	buckets  map[string]bucket
+	idleTTL  time.Duration
+	prunedAt time.Time
+}
+
+// NewMemoryRateLimitStore will create a domain.RateLimitStore kept in process memory.
+// Buckets are not shared between replicas, use the psql store when running more than one.
+// The buckets idle for longer than idleTTL are evicted, it must outlast the period of every limit.
+func NewMemoryRateLimitStore(idleTTL time.Duration) domain.RateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]bucket), idleTTL: idleTTL}
+}
+
+func (m *memoryRateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// every replica keeps its own buckets, so each one evicts them itself rather than in a job
+	if m.idleTTL > 0 && now.Sub(m.prunedAt) > m.idleTTL {
+		m.prune(now.Add(-m.idleTTL), math.MaxInt64)
+		m.prunedAt = now
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = bucket{tokens: float64(limit.Burst), updatedAt: now}
+	}
+	elapsed := math.Max(now.Sub(b.updatedAt).Seconds(), 0)
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate())
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	m.buckets[key] = b
+	return b.tokens, allowed, nil
+}
+
+func (m *memoryRateLimitStore) Prune(ctx context.Context, idleSince time.Time, num int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.prune(idleSince, num), nil
+}
+
+func (m *memoryRateLimitStore) prune(idleSince time.Time, num int64) int64 {
+	var n int64
+	for key, b := range m.buckets {
+		if n == num {
+			break
+		}
+		if b.updatedAt.Before(idleSince) {
+			delete(m.buckets, key)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,56 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/user/repository/memory"
+)
+
+func TestRateLimitStore(t *testing.T) {
+	s := memory.NewMemoryRateLimitStore(time.Hour)
+	ctx := context.TODO()
+	limit := domain.RateLimit{Burst: 2, Period: time.Minute}
+	now := time.Now()
+
+	tokens, allowed, err := s.Take(ctx, "ip:10.0.0.1", limit, now)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, float64(1), tokens)
+
+	_, allowed, _ = s.Take(ctx, "ip:10.0.0.1", limit, now)
+	assert.True(t, allowed)
+	_, allowed, _ = s.Take(ctx, "ip:10.0.0.1", limit, now)
+	assert.False(t, allowed, "the bucket is empty")
+
+	_, allowed, _ = s.Take(ctx, "ip:10.0.0.2", limit, now)
+	assert.True(t, allowed, "buckets are per key")
+
+	_, allowed, _ = s.Take(ctx, "ip:10.0.0.1", limit, now.Add(30*time.Second))
+	assert.True(t, allowed, "a token is refilled every 30 seconds")
+}
+
+func TestRateLimitStorePrune(t *testing.T) {
+	s := memory.NewMemoryRateLimitStore(time.Hour)
+	ctx := context.TODO()
+	limit := domain.RateLimit{Burst: 2, Period: time.Minute}
+	now := time.Now()
+
+	_, _, _ = s.Take(ctx, "ip:10.0.0.1", limit, now)
+	_, _, _ = s.Take(ctx, "ip:10.0.0.2", limit, now.Add(time.Minute))
+
+	n, err := s.Prune(ctx, now.Add(time.Second), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, _ = s.Prune(ctx, now.Add(time.Second), 10)
+	assert.Zero(t, n, "the idle bucket is gone")
+
+	// taking from a store evicts the buckets idle for longer than its ttl
+	_, _, _ = s.Take(ctx, "ip:10.0.0.3", limit, now.Add(2*time.Hour))
+	n, _ = s.Prune(ctx, now.Add(2*time.Hour), 10)
+	assert.Zero(t, n)
+}
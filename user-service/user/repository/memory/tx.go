@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// TxManager is a domain.TxManager fake for tests and in-memory stores. It runs every unit of work
+// directly, counting the calls and failing them with Err when set.
+type TxManager struct {
+	Calls int
+	Err   error
+}
+
+// NewTxManager will create a TxManager fake
+func NewTxManager() *TxManager {
+	return &TxManager{}
+}
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Calls++
+	if m.Err != nil {
+		return m.Err
+	}
+	return fn(ctx)
+}
+
+var _ domain.TxManager = (*TxManager)(nil)
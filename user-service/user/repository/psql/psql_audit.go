@@ -0,0 +1,80 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+)
+
+type psqlAuditRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlAuditRepository will create an object that represent the domain.AuditRepository interface
+func NewPsqlAuditRepository(Conn *sql.DB) domain.AuditRepository {
+	return &psqlAuditRepository{Conn}
+}
+
+func (m *psqlAuditRepository) Store(ctx context.Context, e *domain.AuditEntry) (err error) {
+	query := `INSERT INTO audit_log (actor_id, action, target_user_id, diff, ip, request_id, created_at)
+  						VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	diff, err := json.Marshal(e.Diff)
+	if err != nil {
+		return
+	}
+	actorID := sql.NullInt64{Int64: e.ActorID, Valid: e.ActorID != 0}
+	return conn(ctx, m.Conn).QueryRowContext(ctx, query, actorID, e.Action, e.TargetUserID, diff, e.IP, e.RequestID, e.CreatedAt).Scan(&e.ID)
+}
+
+func (m *psqlAuditRepository) FetchByTargetUserID(ctx context.Context, userID int64, cursor string, num int64) (res []domain.AuditEntry, nextCursor string, err error) {
+	query := `SELECT id, actor_id, action, target_user_id, diff, ip, request_id, created_at
+  						FROM audit_log WHERE target_user_id = $1 AND id > $2 ORDER BY id LIMIT $3`
+
+	// audit_log is append only, its ids follow the order of the entries
+	decodedCursor, err := repository.DecodeIDCursor(cursor)
+	if err != nil && cursor != "" {
+		return nil, "", domain.ErrBadParamInput
+	}
+
+	log := logging.FromContext(ctx).WithField("repository", "psql_audit")
+	rows, err := conn(ctx, m.Conn).QueryContext(ctx, query, userID, decodedCursor, num)
+	if err != nil {
+		log.Error(err)
+		return nil, "", err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			log.Error(errRow)
+		}
+	}()
+
+	res = make([]domain.AuditEntry, 0)
+	for rows.Next() {
+		e := domain.AuditEntry{}
+		var actorID sql.NullInt64
+		var diff []byte
+		err = rows.Scan(&e.ID, &actorID, &e.Action, &e.TargetUserID, &diff, &e.IP, &e.RequestID, &e.CreatedAt)
+		if err != nil {
+			log.Error(err)
+			return nil, "", err
+		}
+		e.ActorID = actorID.Int64
+		if err = json.Unmarshal(diff, &e.Diff); err != nil {
+			log.Error(err)
+			return nil, "", err
+		}
+		res = append(res, e)
+	}
+
+	if len(res) == int(num) {
+		nextCursor = repository.EncodeIDCursor(res[len(res)-1].ID)
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestStoreAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	e := &domain.AuditEntry{
+		Action:       domain.AuditUserCreated,
+		TargetUserID: 7,
+		Diff:         map[string]domain.AuditChange{"name": {After: "Dias"}},
+		IP:           "10.0.0.1",
+		RequestID:    "abc-123",
+		CreatedAt:    now,
+	}
+
+	query := "INSERT INTO audit_log \\(actor_id, action, target_user_id, diff, ip, request_id, created_at\\)"
+	mock.ExpectQuery(query).
+		WithArgs(nil, domain.AuditUserCreated, int64(7), []byte(`{"name":{"after":"Dias"}}`), "10.0.0.1", "abc-123", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+
+	a := userPsqlRepo.NewPsqlAuditRepository(db)
+
+	err = a.Store(context.TODO(), e)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), e.ID)
+}
+
+func TestFetchAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "actor_id", "action", "target_user_id", "diff", "ip", "request_id", "created_at"}).
+		AddRow(1, nil, domain.AuditUserCreated, 7, []byte(`{"name":{"after":"Dias"}}`), "10.0.0.1", "abc-123", now).
+		AddRow(2, 1, domain.AuditUserUpdated, 7, []byte(`{"name":{"before":"Dias","after":"Diantanjung"}}`), "10.0.0.2", "def-456", now.Add(time.Minute))
+
+	query := "SELECT id, actor_id, action, target_user_id, diff, ip, request_id, created_at FROM audit_log WHERE target_user_id = \\$1 AND id > \\$2 ORDER BY id"
+	mock.ExpectQuery(query).WithArgs(7, 0, 2).WillReturnRows(rows)
+
+	a := userPsqlRepo.NewPsqlAuditRepository(db)
+
+	list, nextCursor, err := a.FetchByTargetUserID(context.TODO(), 7, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, repository.EncodeIDCursor(2), nextCursor)
+	assert.Len(t, list, 2)
+	assert.Equal(t, int64(0), list[0].ActorID)
+	assert.Equal(t, int64(1), list[1].ActorID)
+	assert.Equal(t, domain.AuditChange{Before: "Dias", After: "Diantanjung"}, list[1].Diff["name"])
+}
@@ -0,0 +1,47 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type psqlLoginAttemptStore struct {
+	Conn *sql.DB
+}
+
+// NewPsqlLoginAttemptStore will create an object that represent the domain.LoginAttemptStore interface,
+// shared by every replica using the same database
+func NewPsqlLoginAttemptStore(Conn *sql.DB) domain.LoginAttemptStore {
+	return &psqlLoginAttemptStore{Conn}
+}
+
+func (m *psqlLoginAttemptStore) Get(ctx context.Context, key string) (res domain.LoginAttempt, err error) {
+	query := `SELECT key, failures, last_failure_at FROM login_attempts WHERE key = $1`
+
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, key).Scan(&res.Key, &res.Failures, &res.LastFailureAt)
+	if err == sql.ErrNoRows {
+		return domain.LoginAttempt{}, nil
+	}
+	return
+}
+
+func (m *psqlLoginAttemptStore) RegisterFailure(ctx context.Context, key string, at time.Time, window time.Duration) (res domain.LoginAttempt, err error) {
+	query := `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 1, $2)
+  						ON CONFLICT (key) DO UPDATE SET
+  						failures = CASE WHEN login_attempts.last_failure_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+  						last_failure_at = $2
+  						RETURNING key, failures, last_failure_at`
+
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, key, at, at.Add(-window)).Scan(&res.Key, &res.Failures, &res.LastFailureAt)
+	return
+}
+
+func (m *psqlLoginAttemptStore) Reset(ctx context.Context, key string) (err error) {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, key)
+	return
+}
@@ -0,0 +1,47 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestRegisterLoginFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"key", "failures", "last_failure_at"}).AddRow("ip:10.0.0.1", 3, now)
+
+	query := "INSERT INTO login_attempts \\(key, failures, last_failure_at\\) VALUES \\(\\$1, 1, \\$2\\) ON CONFLICT \\(key\\) DO UPDATE"
+	mock.ExpectQuery(query).WithArgs("ip:10.0.0.1", now, now.Add(-time.Hour)).WillReturnRows(rows)
+
+	a := userPsqlRepo.NewPsqlLoginAttemptStore(db)
+
+	attempt, err := a.RegisterFailure(context.TODO(), "ip:10.0.0.1", now, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), attempt.Failures)
+}
+
+func TestGetLoginAttemptNone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "SELECT key, failures, last_failure_at FROM login_attempts WHERE key = \\$1"
+	mock.ExpectQuery(query).WithArgs("ip:10.0.0.1").WillReturnRows(sqlmock.NewRows([]string{"key", "failures", "last_failure_at"}))
+
+	a := userPsqlRepo.NewPsqlLoginAttemptStore(db)
+
+	attempt, err := a.Get(context.TODO(), "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), attempt.Failures)
+}
@@ -0,0 +1,97 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+type psqlOutboxRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlOutboxRepository will create an object that represent the domain.OutboxRepository interface
+func NewPsqlOutboxRepository(Conn *sql.DB) domain.OutboxRepository {
+	return &psqlOutboxRepository{Conn}
+}
+
+func (m *psqlOutboxRepository) Store(ctx context.Context, events ...domain.Event) (err error) {
+	query := `INSERT INTO outbox (id, type, aggregate_id, payload, occurred_at) VALUES ($1, $2, $3, $4, $5)`
+
+	for _, e := range events {
+		_, err = conn(ctx, m.Conn).ExecContext(ctx, query, e.ID, e.Type, e.AggregateID, []byte(e.Payload), e.OccurredAt)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (m *psqlOutboxRepository) Claim(ctx context.Context, num int64, lease time.Duration) (res []domain.Event, err error) {
+	// SKIP LOCKED lets concurrent relays claim different events instead of waiting on each other.
+	// locked_until is both the lease of a claim and the retry time of a failed event.
+	query := `UPDATE outbox SET locked_until = now() + make_interval(secs => $1), attempts = attempts + 1
+  						WHERE id IN (
+  							SELECT id FROM outbox WHERE published_at IS NULL AND dead_at IS NULL AND (locked_until IS NULL OR locked_until < now())
+  							ORDER BY seq LIMIT $2 FOR UPDATE SKIP LOCKED)
+  						RETURNING seq, id, type, aggregate_id, payload, occurred_at, attempts`
+
+	log := logging.FromContext(ctx).WithField("repository", "psql_outbox")
+	rows, err := conn(ctx, m.Conn).QueryContext(ctx, query, lease.Seconds(), num)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			log.Error(errRow)
+		}
+	}()
+
+	seqs := make(map[string]int64)
+	res = make([]domain.Event, 0)
+	for rows.Next() {
+		var seq int64
+		e := domain.Event{}
+		var payload []byte
+		err = rows.Scan(&seq, &e.ID, &e.Type, &e.AggregateID, &payload, &e.OccurredAt, &e.Attempts)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		e.Payload = payload
+		seqs[e.ID] = seq
+		res = append(res, e)
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sort.Slice(res, func(i, j int) bool { return seqs[res[i].ID] < seqs[res[j].ID] })
+	return res, nil
+}
+
+func (m *psqlOutboxRepository) MarkPublished(ctx context.Context, id string, at time.Time) (err error) {
+	query := `UPDATE outbox SET published_at = $1, locked_until = NULL, last_error = '' WHERE id = $2`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, at, id)
+	return
+}
+
+func (m *psqlOutboxRepository) MarkFailed(ctx context.Context, id string, reason string, retryAt time.Time) (err error) {
+	query := `UPDATE outbox SET last_error = $1, locked_until = $2 WHERE id = $3`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, reason, retryAt, id)
+	return
+}
+
+func (m *psqlOutboxRepository) MarkDead(ctx context.Context, id string, reason string, at time.Time) (err error) {
+	query := `UPDATE outbox SET last_error = $1, dead_at = $2, locked_until = NULL WHERE id = $3`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, reason, at, id)
+	return
+}
@@ -0,0 +1,85 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestClaimOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"seq", "id", "type", "aggregate_id", "payload", "occurred_at", "attempts"}).
+		AddRow(2, "b", domain.EventUserUpdated, 7, []byte(`{}`), now, 1).
+		AddRow(1, "a", domain.EventUserCreated, 7, []byte(`{}`), now, 3)
+
+	query := "UPDATE outbox SET locked_until = now\\(\\) \\+ make_interval\\(secs => \\$1\\), attempts = attempts \\+ 1"
+	mock.ExpectQuery(query).WithArgs(float64(30), 10).WillReturnRows(rows)
+
+	o := userPsqlRepo.NewPsqlOutboxRepository(db)
+
+	list, err := o.Claim(context.TODO(), 10, 30*time.Second)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "a", list[0].ID, "events are ordered by seq")
+	assert.Equal(t, "b", list[1].ID)
+	assert.Equal(t, int64(3), list[0].Attempts)
+}
+
+func TestMarkPublished(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "UPDATE outbox SET published_at = \\$1, locked_until = NULL, last_error = '' WHERE id = \\$2"
+	mock.ExpectExec(query).WithArgs(now, "a").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	o := userPsqlRepo.NewPsqlOutboxRepository(db)
+
+	err = o.MarkPublished(context.TODO(), "a", now)
+	assert.NoError(t, err)
+}
+
+func TestMarkFailed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	retryAt := time.Now().Add(time.Minute)
+	query := "UPDATE outbox SET last_error = \\$1, locked_until = \\$2 WHERE id = \\$3"
+	mock.ExpectExec(query).WithArgs("consumer down", retryAt, "a").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	o := userPsqlRepo.NewPsqlOutboxRepository(db)
+
+	err = o.MarkFailed(context.TODO(), "a", "consumer down", retryAt)
+	assert.NoError(t, err)
+}
+
+func TestMarkDead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "UPDATE outbox SET last_error = \\$1, dead_at = \\$2, locked_until = NULL WHERE id = \\$3"
+	mock.ExpectExec(query).WithArgs("consumer down", now, "a").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	o := userPsqlRepo.NewPsqlOutboxRepository(db)
+
+	err = o.MarkDead(context.TODO(), "a", "consumer down", now)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,57 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+type psqlPasswordHistoryRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlPasswordHistoryRepository will create an object that represent the domain.PasswordHistoryRepository interface
+func NewPsqlPasswordHistoryRepository(Conn *sql.DB) domain.PasswordHistoryRepository {
+	return &psqlPasswordHistoryRepository{Conn}
+}
+
+func (m *psqlPasswordHistoryRepository) Store(ctx context.Context, userID int64, hash string, at time.Time) (err error) {
+	query := `INSERT INTO password_history (user_id, hash, created_at) VALUES ($1, $2, $3)`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, userID, hash, at)
+	return
+}
+
+// GetRecent will return the num most recent previous password hashes of the user, newest first
+func (m *psqlPasswordHistoryRepository) GetRecent(ctx context.Context, userID int64, num int64) (res []string, err error) {
+	query := `SELECT hash FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`
+
+	log := logging.FromContext(ctx).WithField("repository", "psql_password_history")
+	rows, err := conn(ctx, m.Conn).QueryContext(ctx, query, userID, num)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			log.Error(errRow)
+		}
+	}()
+
+	res = make([]string, 0, num)
+	for rows.Next() {
+		var hash string
+		if err = rows.Scan(&hash); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		res = append(res, hash)
+	}
+
+	return res, rows.Err()
+}
@@ -0,0 +1,46 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestStorePasswordHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "INSERT INTO password_history \\(user_id, hash, created_at\\) VALUES \\(\\$1, \\$2, \\$3\\)"
+	mock.ExpectExec(query).WithArgs(12, "hash", now).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	a := userPsqlRepo.NewPsqlPasswordHistoryRepository(db)
+
+	err = a.Store(context.TODO(), 12, "hash", now)
+	assert.NoError(t, err)
+}
+
+func TestGetRecentPasswordHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"hash"}).AddRow("hash2").AddRow("hash1")
+
+	query := "SELECT hash FROM password_history WHERE user_id = \\$1 ORDER BY created_at DESC LIMIT \\$2"
+	mock.ExpectQuery(query).WithArgs(12, 5).WillReturnRows(rows)
+
+	a := userPsqlRepo.NewPsqlPasswordHistoryRepository(db)
+
+	hashes, err := a.GetRecent(context.TODO(), 12, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hash2", "hash1"}, hashes)
+}
@@ -0,0 +1,62 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type psqlPasswordResetRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlPasswordResetRepository will create an object that represent the domain.PasswordResetRepository interface
+func NewPsqlPasswordResetRepository(Conn *sql.DB) domain.PasswordResetRepository {
+	return &psqlPasswordResetRepository{Conn}
+}
+
+func (m *psqlPasswordResetRepository) Store(ctx context.Context, pr *domain.PasswordReset) (err error) {
+	query := `INSERT INTO password_resets (user_id, token_hash, created_at, expires_at) VALUES ($1, $2, $3, $4) RETURNING id`
+
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, pr.UserID, pr.TokenHash, pr.CreatedAt, pr.ExpiresAt).Scan(&pr.ID)
+	return
+}
+
+func (m *psqlPasswordResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (res domain.PasswordReset, err error) {
+	query := `SELECT id, user_id, token_hash, created_at, expires_at, used_at FROM password_resets WHERE token_hash = $1`
+
+	var usedAt sql.NullTime
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, tokenHash).Scan(&res.ID, &res.UserID, &res.TokenHash, &res.CreatedAt, &res.ExpiresAt, &usedAt)
+	if err == sql.ErrNoRows {
+		return domain.PasswordReset{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.PasswordReset{}, err
+	}
+	if usedAt.Valid {
+		res.UsedAt = &usedAt.Time
+	}
+
+	return
+}
+
+// MarkUsed will consume the reset, it returns domain.ErrNotFound when it was already used
+func (m *psqlPasswordResetRepository) MarkUsed(ctx context.Context, id int64, at time.Time) (err error) {
+	query := `UPDATE password_resets SET used_at = $1 WHERE id = $2 AND used_at IS NULL`
+
+	res, err := conn(ctx, m.Conn).ExecContext(ctx, query, at, id)
+	if err != nil {
+		return
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affect != 1 {
+		return domain.ErrNotFound
+	}
+
+	return
+}
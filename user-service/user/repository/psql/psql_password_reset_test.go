@@ -0,0 +1,67 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestStorePasswordReset(t *testing.T) {
+	now := time.Now()
+	pr := &domain.PasswordReset{UserID: 12, TokenHash: "hash", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "INSERT INTO password_resets \\(user_id, token_hash, created_at, expires_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id"
+	mock.ExpectQuery(query).WithArgs(pr.UserID, pr.TokenHash, pr.CreatedAt, pr.ExpiresAt).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	a := userPsqlRepo.NewPsqlPasswordResetRepository(db)
+
+	err = a.Store(context.TODO(), pr)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), pr.ID)
+}
+
+func TestGetPasswordResetByTokenHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "token_hash", "created_at", "expires_at", "used_at"}).
+		AddRow(3, 12, "hash", time.Now(), time.Now().Add(time.Hour), nil)
+
+	query := "SELECT id, user_id, token_hash, created_at, expires_at, used_at FROM password_resets WHERE token_hash = \\$1"
+	mock.ExpectQuery(query).WithArgs("hash").WillReturnRows(rows)
+
+	a := userPsqlRepo.NewPsqlPasswordResetRepository(db)
+
+	pr, err := a.GetByTokenHash(context.TODO(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), pr.UserID)
+	assert.Nil(t, pr.UsedAt)
+}
+
+func TestMarkPasswordResetUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE password_resets SET used_at = \\$1 WHERE id = \\$2 AND used_at IS NULL"
+	mock.ExpectExec(query).WithArgs(sqlmock.AnyArg(), 3).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	a := userPsqlRepo.NewPsqlPasswordResetRepository(db)
+
+	err = a.MarkUsed(context.TODO(), 3, time.Now())
+	assert.Equal(t, domain.ErrNotFound, err)
+}
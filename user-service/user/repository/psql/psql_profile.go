@@ -0,0 +1,77 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+type psqlProfileRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlProfileRepository will create an object that represent the domain.ProfileRepository interface
+func NewPsqlProfileRepository(Conn *sql.DB) domain.ProfileRepository {
+	return &psqlProfileRepository{Conn}
+}
+
+func (m *psqlProfileRepository) GetByUserID(ctx context.Context, userID int64) (res domain.Profile, err error) {
+	query := `SELECT user_id, bio, avatar_url, avatars, website, links, preferences, updated_at FROM profiles WHERE user_id = $1`
+
+	var avatars, links, preferences []byte
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, userID).
+		Scan(&res.UserID, &res.Bio, &res.AvatarURL, &avatars, &res.Website, &links, &preferences, &res.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.Profile{}, domain.ErrNotFound
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithField("repository", "psql_profile").Error(err)
+		return domain.Profile{}, err
+	}
+
+	if err = json.Unmarshal(avatars, &res.Avatars); err != nil {
+		return domain.Profile{}, err
+	}
+	if err = json.Unmarshal(links, &res.Links); err != nil {
+		return domain.Profile{}, err
+	}
+	if err = json.Unmarshal(preferences, &res.Preferences); err != nil {
+		return domain.Profile{}, err
+	}
+	return res, nil
+}
+
+func (m *psqlProfileRepository) Upsert(ctx context.Context, p *domain.Profile) (err error) {
+	query := `INSERT INTO profiles (user_id, bio, avatar_url, avatars, website, links, preferences, updated_at)
+  						VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+  						ON CONFLICT (user_id) DO UPDATE SET bio = EXCLUDED.bio, avatar_url = EXCLUDED.avatar_url,
+  							avatars = EXCLUDED.avatars, website = EXCLUDED.website, links = EXCLUDED.links, preferences = EXCLUDED.preferences,
+  							updated_at = EXCLUDED.updated_at`
+
+	avatars := p.Avatars
+	if avatars == nil {
+		avatars = []domain.ProfileAvatar{}
+	}
+	avatarsJSON, err := json.Marshal(avatars)
+	if err != nil {
+		return
+	}
+	links := p.Links
+	if links == nil {
+		links = []domain.ProfileLink{}
+	}
+	linksJSON, err := json.Marshal(links)
+	if err != nil {
+		return
+	}
+	preferences, err := json.Marshal(p.Preferences)
+	if err != nil {
+		return
+	}
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, p.UserID, p.Bio, p.AvatarURL, avatarsJSON, p.Website, linksJSON, preferences, p.UpdatedAt)
+	return
+}
@@ -0,0 +1,63 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestGetProfile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"user_id", "bio", "avatar_url", "avatars", "website", "links", "preferences", "updated_at"}).
+		AddRow(1, "Writes about Go", "https://cdn.blogo.dev/avatars/1/512.jpg", []byte(`[{"size":64,"url":"https://cdn.blogo.dev/avatars/1/64.jpg"},{"size":512,"url":"https://cdn.blogo.dev/avatars/1/512.jpg"}]`), "https://dias.dev", []byte(`[{"label":"GitHub","url":"https://github.com/diantanjung"}]`), []byte(`{"theme":"dark"}`), now)
+
+	query := "SELECT user_id, bio, avatar_url, avatars, website, links, preferences, updated_at FROM profiles WHERE user_id = \\$1"
+	mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(query).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	r := userPsqlRepo.NewPsqlProfileRepository(db)
+
+	p, err := r.GetByUserID(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "https://dias.dev", p.Website)
+	assert.Len(t, p.Avatars, 2)
+	assert.Equal(t, domain.ProfileAvatar{Size: 64, URL: "https://cdn.blogo.dev/avatars/1/64.jpg"}, p.Avatars[0])
+	assert.Equal(t, []domain.ProfileLink{{Label: "GitHub", URL: "https://github.com/diantanjung"}}, p.Links)
+	assert.Equal(t, "dark", p.Preferences.Theme)
+
+	_, err = r.GetByUserID(context.TODO(), 2)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestUpsertProfile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	p := &domain.Profile{UserID: 1, Bio: "Writes about Go", Preferences: domain.ProfilePreferences{ShowJoinDate: true}, UpdatedAt: now}
+
+	query := "INSERT INTO profiles \\(user_id, bio, avatar_url, avatars, website, links, preferences, updated_at\\) .* ON CONFLICT \\(user_id\\) DO UPDATE"
+	mock.ExpectExec(query).
+		WithArgs(p.UserID, p.Bio, "", []byte(`[]`), "", []byte(`[]`), []byte(`{"show_join_date":true}`), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := userPsqlRepo.NewPsqlProfileRepository(db)
+
+	err = r.Upsert(context.TODO(), p)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,44 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type psqlRateLimitStore struct {
+	Conn *sql.DB
+}
+
+// NewPsqlRateLimitStore will create an object that represent the domain.RateLimitStore interface,
+// shared by every replica using the same database
+func NewPsqlRateLimitStore(Conn *sql.DB) domain.RateLimitStore {
+	return &psqlRateLimitStore{Conn}
+}
+
+func (m *psqlRateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (tokens float64, allowed bool, err error) {
+	// the bucket is refilled and taken from in a single upsert, so concurrent requests can't both take the last token
+	query := `INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at) VALUES ($1, $2::float8 - 1, TRUE, $3)
+  						ON CONFLICT (key) DO UPDATE SET
+  						tokens = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($3 - b.updated_at)), 0) * $4::float8)
+  							- CASE WHEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($3 - b.updated_at)), 0) * $4::float8) >= 1 THEN 1 ELSE 0 END,
+  						allowed = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($3 - b.updated_at)), 0) * $4::float8) >= 1,
+  						updated_at = GREATEST(b.updated_at, $3)
+  						RETURNING tokens, allowed`
+
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, key, float64(limit.Burst), now, limit.Rate()).Scan(&tokens, &allowed)
+	return
+}
+
+func (m *psqlRateLimitStore) Prune(ctx context.Context, idleSince time.Time, num int64) (int64, error) {
+	query := `DELETE FROM rate_limit_buckets WHERE key IN (
+  						SELECT key FROM rate_limit_buckets WHERE updated_at < $1 LIMIT $2)`
+
+	res, err := conn(ctx, m.Conn).ExecContext(ctx, query, idleSince, num)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,51 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestTakeRateLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"tokens", "allowed"}).AddRow(0.5, false)
+
+	query := "INSERT INTO rate_limit_buckets AS b \\(key, tokens, allowed, updated_at\\) VALUES \\(\\$1, \\$2::float8 - 1, TRUE, \\$3\\) ON CONFLICT \\(key\\) DO UPDATE"
+	mock.ExpectQuery(query).WithArgs("ip:10.0.0.1", float64(60), now, float64(1)).WillReturnRows(rows)
+
+	s := userPsqlRepo.NewPsqlRateLimitStore(db)
+
+	tokens, allowed, err := s.Take(context.TODO(), "ip:10.0.0.1", domain.RateLimit{Burst: 60, Period: time.Minute}, now)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 0.5, tokens)
+}
+
+func TestPruneRateLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	idleSince := time.Now().Add(-time.Hour)
+	query := "DELETE FROM rate_limit_buckets WHERE key IN \\(\\s+SELECT key FROM rate_limit_buckets WHERE updated_at < \\$1 LIMIT \\$2\\)"
+	mock.ExpectExec(query).WithArgs(idleSince, int64(500)).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	s := userPsqlRepo.NewPsqlRateLimitStore(db)
+
+	n, err := s.Prune(context.TODO(), idleSince, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,102 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+type psqlRoleRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlRoleRepository will create an object that represent the domain.RoleRepository interface
+func NewPsqlRoleRepository(Conn *sql.DB) domain.RoleRepository {
+	return &psqlRoleRepository{Conn}
+}
+
+func (m *psqlRoleRepository) GetByUserID(ctx context.Context, userID int64) (res []domain.Role, err error) {
+	roles, err := m.GetByUserIDs(ctx, []int64{userID})
+	if err != nil {
+		return nil, err
+	}
+	return roles[userID], nil
+}
+
+func (m *psqlRoleRepository) GetByUserIDs(ctx context.Context, userIDs []int64) (res map[int64][]domain.Role, err error) {
+	query := `SELECT user_id, role FROM user_roles WHERE user_id = ANY($1) ORDER BY user_id, role`
+
+	log := logging.FromContext(ctx).WithField("repository", "psql_role")
+	rows, err := conn(ctx, m.Conn).QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			log.Error(errRow)
+		}
+	}()
+
+	res = make(map[int64][]domain.Role, len(userIDs))
+	for rows.Next() {
+		var userID int64
+		var role domain.Role
+		err = rows.Scan(&userID, &role)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		res[userID] = append(res[userID], role)
+	}
+
+	return res, rows.Err()
+}
+
+// Store will replace the roles of the user with the given ones
+func (m *psqlRoleRepository) Store(ctx context.Context, userID int64, roles []domain.Role) (err error) {
+	return withinTx(ctx, m.Conn, func(q querier) error {
+		_, err := q.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, role := range roles {
+			_, err = q.ExecContext(ctx, `INSERT INTO user_roles (user_id, role) VALUES ($1, $2)`, userID, role)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// StoreBatch will add the roles of the users with COPY, in the transaction of ctx or in a new one
+func (m *psqlRoleRepository) StoreBatch(ctx context.Context, roles map[int64][]domain.Role) (err error) {
+	if len(roles) == 0 {
+		return nil
+	}
+	return withinTx(ctx, m.Conn, func(q querier) error {
+		stmt, err := q.PrepareContext(ctx, pq.CopyIn("user_roles", "user_id", "role"))
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for userID, list := range roles {
+			for _, role := range list {
+				if _, err = stmt.ExecContext(ctx, userID, role); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = stmt.ExecContext(ctx)
+		return err
+	})
+}
@@ -0,0 +1,71 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestGetRolesByUserIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"user_id", "role"}).
+		AddRow(1, "admin").
+		AddRow(1, "reader").
+		AddRow(2, "author")
+
+	query := "SELECT user_id, role FROM user_roles WHERE user_id = ANY\\(\\$1\\) ORDER BY user_id, role"
+
+	mock.ExpectQuery(query).WillReturnRows(rows)
+	a := userPsqlRepo.NewPsqlRoleRepository(db)
+
+	roles, err := a.GetByUserIDs(context.TODO(), []int64{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Role{domain.RoleAdmin, domain.RoleReader}, roles[1])
+	assert.Equal(t, []domain.Role{domain.RoleAuthor}, roles[2])
+}
+
+func TestStoreRoles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM user_roles WHERE user_id = \\$1").WithArgs(12).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO user_roles \\(user_id, role\\) VALUES \\(\\$1, \\$2\\)").WithArgs(12, "author").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	a := userPsqlRepo.NewPsqlRoleRepository(db)
+
+	err = a.Store(context.TODO(), 12, []domain.Role{domain.RoleAuthor})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreRolesBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(`COPY "user_roles" \("user_id", "role"\) FROM STDIN`)
+	prep.ExpectExec().WithArgs(1, "admin").WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs(1, "reader").WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	a := userPsqlRepo.NewPsqlRoleRepository(db)
+	err = a.StoreBatch(context.TODO(), map[int64][]domain.Role{1: {domain.RoleAdmin, domain.RoleReader}})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,58 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type psqlSessionRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlSessionRepository will create an object that represent the domain.SessionRepository interface
+func NewPsqlSessionRepository(Conn *sql.DB) domain.SessionRepository {
+	return &psqlSessionRepository{Conn}
+}
+
+func (m *psqlSessionRepository) Store(ctx context.Context, s *domain.Session) (err error) {
+	query := `INSERT INTO sessions (id, user_id, created_at, expires_at) VALUES ($1, $2, $3, $4)`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, s.ID, s.UserID, s.CreatedAt, s.ExpiresAt)
+	return
+}
+
+func (m *psqlSessionRepository) GetByID(ctx context.Context, id string) (res domain.Session, err error) {
+	query := `SELECT id, user_id, created_at, expires_at, revoked_at FROM sessions WHERE id = $1`
+
+	var revokedAt sql.NullTime
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, id).Scan(&res.ID, &res.UserID, &res.CreatedAt, &res.ExpiresAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return domain.Session{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Session{}, err
+	}
+	if revokedAt.Valid {
+		res.RevokedAt = &revokedAt.Time
+	}
+
+	return
+}
+
+func (m *psqlSessionRepository) Revoke(ctx context.Context, id string) (err error) {
+	query := `UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, time.Now(), id)
+	return
+}
+
+// RevokeByUserID will revoke every active session of the user except the one with exceptID
+func (m *psqlSessionRepository) RevokeByUserID(ctx context.Context, userID int64, exceptID string) (err error) {
+	query := `UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, time.Now(), userID, exceptID)
+	return
+}
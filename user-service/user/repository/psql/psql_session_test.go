@@ -0,0 +1,66 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestStoreSession(t *testing.T) {
+	now := time.Now()
+	s := &domain.Session{ID: "abc", UserID: 12, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "INSERT INTO sessions \\(id, user_id, created_at, expires_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\)"
+	mock.ExpectExec(query).WithArgs(s.ID, s.UserID, s.CreatedAt, s.ExpiresAt).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	a := userPsqlRepo.NewPsqlSessionRepository(db)
+
+	err = a.Store(context.TODO(), s)
+	assert.NoError(t, err)
+}
+
+func TestGetSessionByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "created_at", "expires_at", "revoked_at"}).
+		AddRow("abc", 12, time.Now(), time.Now().Add(time.Hour), nil)
+
+	query := "SELECT id, user_id, created_at, expires_at, revoked_at FROM sessions WHERE id = \\$1"
+	mock.ExpectQuery(query).WithArgs("abc").WillReturnRows(rows)
+
+	a := userPsqlRepo.NewPsqlSessionRepository(db)
+
+	s, err := a.GetByID(context.TODO(), "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), s.UserID)
+	assert.Nil(t, s.RevokedAt)
+}
+
+func TestRevokeSessionsByUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE sessions SET revoked_at = \\$1 WHERE user_id = \\$2 AND id <> \\$3 AND revoked_at IS NULL"
+	mock.ExpectExec(query).WithArgs(sqlmock.AnyArg(), 12, "abc").WillReturnResult(sqlmock.NewResult(0, 2))
+
+	a := userPsqlRepo.NewPsqlSessionRepository(db)
+
+	err = a.RevokeByUserID(context.TODO(), 12, "abc")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,107 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/sqltx"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier = sqltx.Querier
+
+// conn returns the transaction started in ctx by the TxManager, or db outside of one
+func conn(ctx context.Context, db *sql.DB) querier {
+	return sqltx.Conn(ctx, db)
+}
+
+// withinTx will run fn in the transaction of ctx, or in a new one for writes that must be atomic on their own
+func withinTx(ctx context.Context, db *sql.DB, fn func(q querier) error) (err error) {
+	if tx, ok := sqltx.FromContext(ctx); ok {
+		return fn(tx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	if err = fn(tx); err != nil {
+		rollback(ctx, tx)
+		return
+	}
+	return tx.Commit()
+}
+
+func rollback(ctx context.Context, tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		logging.FromContext(ctx).WithField("repository", "psql_tx").Error(err)
+	}
+}
+
+type psqlTxManager struct {
+	Conn    *sql.DB
+	opts    *sql.TxOptions
+	retries int
+}
+
+// NewPsqlTxManager will create an object that represent the domain.TxManager interface.
+// Transactions failing on a serialization conflict or a deadlock are retried up to retries times.
+func NewPsqlTxManager(Conn *sql.DB, isolation sql.IsolationLevel, retries int) domain.TxManager {
+	return &psqlTxManager{Conn: Conn, opts: &sql.TxOptions{Isolation: isolation}, retries: retries}
+}
+
+func (m *psqlTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := sqltx.FromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = m.run(ctx, fn)
+		if err == nil || attempt >= m.retries || !isSerializationFailure(err) {
+			return
+		}
+
+		logging.FromContext(ctx).WithField("attempt", attempt+1).WithError(err).Warn("retrying transaction")
+		backoff := time.Duration(10<<uint(attempt))*time.Millisecond + time.Duration(rand.Int63n(int64(10*time.Millisecond)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (m *psqlTxManager) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := m.Conn.BeginTx(ctx, m.opts)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			rollback(ctx, tx)
+			panic(p)
+		}
+	}()
+
+	if err = fn(sqltx.NewContext(ctx, tx)); err != nil {
+		rollback(ctx, tx)
+		return
+	}
+	return tx.Commit()
+}
+
+// isSerializationFailure reports whether err is a conflict Postgres expects the transaction to be retried on
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
@@ -0,0 +1,97 @@
+package psql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	userPsqlRepo "github.com/diantanjung/blogo/user-service/user/repository/psql"
+)
+
+func TestWithinTxJoinsRepositories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM user_roles WHERE user_id = \\$1").WithArgs(12).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO user_roles").WithArgs(12, domain.RoleAuthor).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tm := userPsqlRepo.NewPsqlTxManager(db, sql.LevelSerializable, 0)
+	roles := userPsqlRepo.NewPsqlRoleRepository(db)
+	outbox := userPsqlRepo.NewPsqlOutboxRepository(db)
+
+	err = tm.WithinTx(context.TODO(), func(ctx context.Context) error {
+		if err := roles.Store(ctx, 12, []domain.Role{domain.RoleAuthor}); err != nil {
+			return err
+		}
+		// nested units of work join the outer transaction
+		return tm.WithinTx(ctx, func(ctx context.Context) error {
+			return outbox.Store(ctx, domain.Event{ID: "a", Type: domain.EventUserUpdated, AggregateID: 12, Payload: []byte(`{}`)})
+		})
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTxRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	tm := userPsqlRepo.NewPsqlTxManager(db, sql.LevelSerializable, 3)
+	err = tm.WithinTx(context.TODO(), func(ctx context.Context) error {
+		return domain.ErrForbidden
+	})
+	assert.Equal(t, domain.ErrForbidden, err, "only serialization failures are retried")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTxRetriesSerializationFailures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	conflict := &pq.Error{Code: "40001"}
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(conflict)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	tm := userPsqlRepo.NewPsqlTxManager(db, sql.LevelSerializable, 2)
+	calls := 0
+	err = tm.WithinTx(context.TODO(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return conflict
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	tm = userPsqlRepo.NewPsqlTxManager(db, sql.LevelSerializable, 0)
+	err = tm.WithinTx(context.TODO(), func(ctx context.Context) error {
+		return conflict
+	})
+	assert.True(t, errors.Is(err, conflict), "retries are exhausted")
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/lib/pq"
 
 	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
 	"github.com/diantanjung/blogo/user-service/user/repository"
 )
 
@@ -21,35 +24,41 @@ func NewPsqlUserRepository(Conn *sql.DB) domain.UserRepository {
 }
 
 func (m *psqlUserRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.User, err error) {
-	rows, err := m.Conn.QueryContext(ctx, query, args...)
+	log := logging.FromContext(ctx).WithField("repository", "psql_user")
+	rows, err := conn(ctx, m.Conn).QueryContext(ctx, query, args...)
 	if err != nil {
-		logrus.Error(err)
+		log.Error(err)
 		return nil, err
 	}
 
 	defer func() {
 		errRow := rows.Close()
 		if errRow != nil {
-			logrus.Error(errRow)
+			log.Error(errRow)
 		}
 	}()
 
 	result = make([]domain.User, 0)
 	for rows.Next() {
 		user := domain.User{}
+		var verifiedAt sql.NullTime
 		err = rows.Scan(
 			&user.ID,
 			&user.Username,
 			&user.Name,
 			&user.Email,
+			&verifiedAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
 
 		if err != nil {
-			logrus.Error(err)
+			log.Error(err)
 			return nil, err
 		}
+		if verifiedAt.Valid {
+			user.EmailVerifiedAt = &verifiedAt.Time
+		}
 		result = append(result, user)
 	}
 
@@ -57,10 +66,11 @@ func (m *psqlUserRepository) fetch(ctx context.Context, query string, args ...in
 }
 
 func (m *psqlUserRepository) Fetch(ctx context.Context, cursor string, num int64) (res []domain.User, nextCursor string, err error) {
-	query := `SELECT id, username, name, email, created_at, updated_at
-  						FROM users WHERE created_at > ? ORDER BY created_at LIMIT ? `
+	query := `SELECT id, username, name, email, email_verified_at, created_at, updated_at
+  						FROM users WHERE id > $1 AND deleted_at IS NULL ORDER BY id LIMIT $2 `
 
-	decodedCursor, err := repository.DecodeCursor(cursor)
+	// the users of an import share their created_at, the ids tell them apart
+	decodedCursor, err := repository.DecodeIDCursor(cursor)
 	if err != nil && cursor != "" {
 		return nil, "", domain.ErrBadParamInput
 	}
@@ -71,14 +81,14 @@ func (m *psqlUserRepository) Fetch(ctx context.Context, cursor string, num int64
 	}
 
 	if len(res) == int(num) {
-		nextCursor = repository.EncodeCursor(res[len(res)-1].CreatedAt)
+		nextCursor = repository.EncodeIDCursor(res[len(res)-1].ID)
 	}
 
 	return
 }
 func (m *psqlUserRepository) GetByID(ctx context.Context, id int64) (res domain.User, err error) {
-	query := `SELECT id, username, name, email, created_at, updated_at
-  						FROM users WHERE ID = ?`
+	query := `SELECT id, username, name, email, email_verified_at, created_at, updated_at
+  						FROM users WHERE ID = $1 AND deleted_at IS NULL`
 
 	list, err := m.fetch(ctx, query, id)
 	if err != nil {
@@ -94,16 +104,70 @@ func (m *psqlUserRepository) GetByID(ctx context.Context, id int64) (res domain.
 	return
 }
 
+func (m *psqlUserRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.User, error) {
+	query := `SELECT id, username, name, email, email_verified_at, created_at, updated_at
+  						FROM users WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id`
+
+	return m.fetch(ctx, query, pq.Array(ids))
+}
+
+// GetByUsername will prefer the user currently named username over the one who had it before,
+// former usernames are reserved to their user so they only differ in legacy data
+func (m *psqlUserRepository) GetByUsername(ctx context.Context, username string) (res domain.User, err error) {
+	query := `SELECT id, username, name, email, email_verified_at, created_at, updated_at
+  						FROM users WHERE deleted_at IS NULL AND (lower(username) = lower($1)
+  							OR id = (SELECT user_id FROM username_history WHERE lower(username) = lower($1)))
+  						ORDER BY lower(username) = lower($1) DESC LIMIT 1`
+
+	list, err := m.fetch(ctx, query, username)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if len(list) == 0 {
+		return domain.User{}, domain.ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (m *psqlUserRepository) GetByEmail(ctx context.Context, email string) (res domain.User, err error) {
+	query := `SELECT id, username, name, email, password, email_verified_at, created_at, updated_at
+  						FROM users WHERE email = $1 AND deleted_at IS NULL`
+
+	var verifiedAt sql.NullTime
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, email).Scan(
+		&res.ID,
+		&res.Username,
+		&res.Name,
+		&res.Email,
+		&res.Password,
+		&verifiedAt,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.User{}, domain.ErrNotFound
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithField("repository", "psql_user").Error(err)
+		return domain.User{}, err
+	}
+	if verifiedAt.Valid {
+		res.EmailVerifiedAt = &verifiedAt.Time
+	}
+
+	return
+}
+
 func (m *psqlUserRepository) Update(ctx context.Context, u *domain.User) (err error) {
 
-	query := `UPDATE users SET username=?, password=?, name=?, email=?, updated_at=? WHERE id=?`
+	query := `UPDATE users SET username=$1, name=$2, email=$3, email_verified_at=$4, updated_at=$5 WHERE id=$6 AND deleted_at IS NULL`
 
-	stmt, err := m.Conn.PrepareContext(ctx, query)
+	stmt, err := conn(ctx, m.Conn).PrepareContext(ctx, query)
 	if err != nil {
 		return
 	}
 
-	res, err := stmt.ExecContext(ctx, u.Username, u.Password, u.Name, u.Email, u.UpdatedAt, u.ID)
+	res, err := stmt.ExecContext(ctx, u.Username, u.Name, u.Email, u.EmailVerifiedAt, u.UpdatedAt, u.ID)
 	if err != nil {
 		return
 	}
@@ -120,28 +184,136 @@ func (m *psqlUserRepository) Update(ctx context.Context, u *domain.User) (err er
 }
 
 func (m *psqlUserRepository) Store(ctx context.Context, u *domain.User) (err error) {
-	query := `INSERT INTO users (username,name,email,password,created_at,updated_at) VALUE (username=?, name=?, email=?, password=?, created_at=?, updated_at=?)`
-	stmt, err := m.Conn.PrepareContext(ctx, query)
+	query := `INSERT INTO users (username,name,email,password,created_at,updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	stmt, err := conn(ctx, m.Conn).PrepareContext(ctx, query)
 	if err != nil {
 		return
 	}
 
-	res, err := stmt.ExecContext(ctx, u.Username, u.Name, u.Email, u.Password, u.CreatedAt, u.UpdatedAt)
+	err = stmt.QueryRowContext(ctx, u.Username, u.Name, u.Email, u.Password, u.CreatedAt, u.UpdatedAt).Scan(&u.ID)
+	return
+}
+
+// StoreBatch will insert the users with COPY, in the transaction of ctx or in a new one
+func (m *psqlUserRepository) StoreBatch(ctx context.Context, users []domain.User) (err error) {
+	if len(users) == 0 {
+		return nil
+	}
+	return withinTx(ctx, m.Conn, func(q querier) error {
+		stmt, err := q.PrepareContext(ctx, pq.CopyIn("users", "username", "name", "email", "password", "created_at", "updated_at"))
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, u := range users {
+			if _, err = stmt.ExecContext(ctx, u.Username, u.Name, u.Email, u.Password, u.CreatedAt, u.UpdatedAt); err != nil {
+				return err
+			}
+		}
+		if _, err = stmt.ExecContext(ctx); err != nil {
+			return err
+		}
+
+		// COPY returns nothing, the ids are read back by the unique usernames
+		usernames := make([]string, len(users))
+		for i, u := range users {
+			usernames[i] = u.Username
+		}
+		ids, err := m.idsByUsername(ctx, q, usernames)
+		if err != nil {
+			return err
+		}
+		for i := range users {
+			users[i].ID = ids[users[i].Username]
+		}
+		return nil
+	})
+}
+
+func (m *psqlUserRepository) idsByUsername(ctx context.Context, q querier, usernames []string) (map[string]int64, error) {
+	rows, err := q.QueryContext(ctx, `SELECT id, username FROM users WHERE username = ANY($1)`, pq.Array(usernames))
 	if err != nil {
-		return
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int64, len(usernames))
+	for rows.Next() {
+		var id int64
+		var username string
+		if err = rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		ids[username] = id
 	}
-	lastID, err := res.LastInsertId()
+	return ids, rows.Err()
+}
+
+func (m *psqlUserRepository) FetchAfter(ctx context.Context, afterID int64, num int64) ([]domain.User, error) {
+	query := `SELECT id, username, name, email, email_verified_at, created_at, updated_at
+  						FROM users WHERE id > $1 AND deleted_at IS NULL ORDER BY id LIMIT $2`
+
+	return m.fetch(ctx, query, afterID, num)
+}
+
+func (m *psqlUserRepository) Taken(ctx context.Context, usernames, emails []string) (takenUsernames, takenEmails map[string]bool, err error) {
+	query := `SELECT username, email FROM users WHERE lower(username) = ANY($1) OR email = ANY($2)
+  						UNION ALL SELECT username, '' FROM username_history WHERE lower(username) = ANY($1)`
+
+	// the given usernames are looked up by their lower case
+	wantedUsernames := make(map[string][]string, len(usernames))
+	lowered := make([]string, 0, len(usernames))
+	for _, v := range usernames {
+		l := strings.ToLower(v)
+		if _, ok := wantedUsernames[l]; !ok {
+			lowered = append(lowered, l)
+		}
+		wantedUsernames[l] = append(wantedUsernames[l], v)
+	}
+	wantedEmails := make(map[string]bool, len(emails))
+	for _, v := range emails {
+		wantedEmails[v] = true
+	}
+
+	rows, err := conn(ctx, m.Conn).QueryContext(ctx, query, pq.Array(lowered), pq.Array(emails))
 	if err != nil {
-		return
+		logging.FromContext(ctx).WithField("repository", "psql_user").Error(err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	takenUsernames, takenEmails = make(map[string]bool), make(map[string]bool)
+	for rows.Next() {
+		var username, email string
+		if err = rows.Scan(&username, &email); err != nil {
+			return nil, nil, err
+		}
+		for _, v := range wantedUsernames[strings.ToLower(username)] {
+			takenUsernames[v] = true
+		}
+		if wantedEmails[email] {
+			takenEmails[email] = true
+		}
 	}
-	u.ID = lastID
+	return takenUsernames, takenEmails, rows.Err()
+}
+
+// StoreFormerUsername will point the username to the user, taking it over from a previous owner
+func (m *psqlUserRepository) StoreFormerUsername(ctx context.Context, id int64, username string, at time.Time) (err error) {
+	query := `INSERT INTO username_history (user_id, username, changed_at) VALUES ($1, $2, $3)
+  						ON CONFLICT ((lower(username))) DO UPDATE SET user_id = EXCLUDED.user_id,
+  							username = EXCLUDED.username, changed_at = EXCLUDED.changed_at`
+
+	_, err = conn(ctx, m.Conn).ExecContext(ctx, query, id, username, at)
 	return
 }
 
+// Delete will soft delete the user, it can be brought back with Restore
 func (m *psqlUserRepository) Delete(ctx context.Context, id int64) (err error) {
-	query := "DELETE FROM users WHERE id = ?"
+	query := "UPDATE users SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL"
 
-	stmt, err := m.Conn.PrepareContext(ctx, query)
+	stmt, err := conn(ctx, m.Conn).PrepareContext(ctx, query)
 	if err != nil {
 		return
 	}
@@ -163,3 +335,81 @@ func (m *psqlUserRepository) Delete(ctx context.Context, id int64) (err error) {
 
 	return
 }
+
+// Restore will bring back a soft deleted user
+func (m *psqlUserRepository) Restore(ctx context.Context, id int64, at time.Time) (err error) {
+	query := `UPDATE users SET deleted_at=NULL, updated_at=$1 WHERE id=$2 AND deleted_at IS NOT NULL`
+
+	res, err := conn(ctx, m.Conn).ExecContext(ctx, query, at, id)
+	if err != nil {
+		return
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affect != 1 {
+		return domain.ErrNotFound
+	}
+
+	return
+}
+
+func (m *psqlUserRepository) Purge(ctx context.Context, deletedBefore time.Time, num int64) (int64, error) {
+	// roles, sessions, password resets and history are deleted by their foreign keys
+	query := `DELETE FROM users WHERE id IN (
+  						SELECT id FROM users WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2)`
+
+	res, err := conn(ctx, m.Conn).ExecContext(ctx, query, deletedBefore, num)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// MarkEmailVerified will set the verification time of the user, as long as its email is still the verified one
+func (m *psqlUserRepository) MarkEmailVerified(ctx context.Context, id int64, email string, at time.Time) (err error) {
+	query := `UPDATE users SET email_verified_at=$1 WHERE id=$2 AND email=$3 AND deleted_at IS NULL`
+
+	res, err := conn(ctx, m.Conn).ExecContext(ctx, query, at, id, email)
+	if err != nil {
+		return
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affect != 1 {
+		return domain.ErrNotFound
+	}
+
+	return
+}
+
+func (m *psqlUserRepository) UpdatePassword(ctx context.Context, id int64, hash string, at time.Time) (err error) {
+	query := `UPDATE users SET password=$1, updated_at=$2 WHERE id=$3 AND deleted_at IS NULL`
+
+	res, err := conn(ctx, m.Conn).ExecContext(ctx, query, hash, at, id)
+	if err != nil {
+		return
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affect != 1 {
+		return domain.ErrNotFound
+	}
+
+	return
+}
+
+func (m *psqlUserRepository) GetPasswordHash(ctx context.Context, id int64) (res string, err error) {
+	query := `SELECT password FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	err = conn(ctx, m.Conn).QueryRowContext(ctx, query, id).Scan(&res)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrNotFound
+	}
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 
@@ -30,20 +31,20 @@ func TestFetch(t *testing.T) {
 		},
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "username", "name", "email", "updated_at", "created_at"}).
+	rows := sqlmock.NewRows([]string{"id", "username", "name", "email", "email_verified_at", "updated_at", "created_at"}).
 		AddRow(mockUsers[0].ID, mockUsers[0].Username, mockUsers[0].Name,
-			mockUsers[0].Email, mockUsers[0].UpdatedAt, mockUsers[0].CreatedAt).
-			AddRow(mockUsers[1].ID, mockUsers[1].Username, mockUsers[1].Name,
-				mockUsers[1].Email, mockUsers[1].UpdatedAt, mockUsers[1].CreatedAt)
+			mockUsers[0].Email, nil, mockUsers[0].UpdatedAt, mockUsers[0].CreatedAt).
+		AddRow(mockUsers[1].ID, mockUsers[1].Username, mockUsers[1].Name,
+			mockUsers[1].Email, time.Now(), mockUsers[1].UpdatedAt, mockUsers[1].CreatedAt)
 
-	query := "SELECT id, username, name, email, created_at, updated_at  FROM users WHERE created_at > \\? ORDER BY created_at LIMIT \\?"
+	query := "SELECT id, username, name, email, email_verified_at, created_at, updated_at  FROM users WHERE id > \\$1 AND deleted_at IS NULL ORDER BY id LIMIT \\$2"
 
 	mock.ExpectQuery(query).WillReturnRows(rows)
 	a := userPsqlRepo.NewPsqlUserRepository(db)
-	cursor := repository.EncodeCursor(mockUsers[1].CreatedAt)
+	cursor := repository.EncodeIDCursor(mockUsers[0].ID - 1)
 	num := int64(2)
 	list, nextCursor, err := a.Fetch(context.TODO(), cursor, num)
-	assert.NotEmpty(t, nextCursor)
+	assert.Equal(t, repository.EncodeIDCursor(mockUsers[1].ID), nextCursor)
 	assert.NoError(t, err)
 	assert.Len(t, list, 2)
 }
@@ -54,10 +55,10 @@ func TestGetByID(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "username", "name", "email", "created_at", "updated_at"}).
-		AddRow(1, "usrname1", "Name 1", "username1@gmail.com", time.Now(), time.Now())
+	rows := sqlmock.NewRows([]string{"id", "username", "name", "email", "email_verified_at", "created_at", "updated_at"}).
+		AddRow(1, "usrname1", "Name 1", "username1@gmail.com", nil, time.Now(), time.Now())
 
-	query := "SELECT id, username, name, email, created_at, updated_at FROM users WHERE ID = \\?"
+	query := "SELECT id, username, name, email, email_verified_at, created_at, updated_at FROM users WHERE ID = \\$1"
 
 	mock.ExpectQuery(query).WillReturnRows(rows)
 	a := userPsqlRepo.NewPsqlUserRepository(db)
@@ -83,9 +84,9 @@ func TestStore(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "INSERT INTO users \\(username,name,email,password,created_at,updated_at\\) VALUE \\(username=\\?, name=\\?, email=\\?, password=\\?, created_at=\\?, updated_at=\\?\\)"
+	query := "INSERT INTO users \\(username,name,email,password,created_at,updated_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6\\) RETURNING id"
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(u.Username, u.Name, u.Email, u.Password, u.CreatedAt, u.UpdatedAt).WillReturnResult(sqlmock.NewResult(12, 1))
+	prep.ExpectQuery().WithArgs(u.Username, u.Name, u.Email, u.Password, u.CreatedAt, u.UpdatedAt).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
 
 	a := userPsqlRepo.NewPsqlUserRepository(db)
 
@@ -100,7 +101,7 @@ func TestDelete(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "DELETE FROM users WHERE id = \\?"
+	query := "UPDATE users SET deleted_at = now\\(\\) WHERE id = \\$1 AND deleted_at IS NULL"
 
 	prep := mock.ExpectPrepare(query)
 	prep.ExpectExec().WithArgs(12).WillReturnResult(sqlmock.NewResult(12, 1))
@@ -128,13 +129,209 @@ func TestUpdate(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "UPDATE users SET username=\\?, password=\\?, name=\\?, email=\\?, updated_at=\\? WHERE id=\\?"
+	query := "UPDATE users SET username=\\$1, name=\\$2, email=\\$3, email_verified_at=\\$4, updated_at=\\$5 WHERE id=\\$6"
 
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(u.Username, u.Password, u.Name, u.Email, u.UpdatedAt, u.ID).WillReturnResult(sqlmock.NewResult(12, 1))
+	prep.ExpectExec().WithArgs(u.Username, u.Name, u.Email, u.EmailVerifiedAt, u.UpdatedAt, u.ID).WillReturnResult(sqlmock.NewResult(12, 1))
 
 	a := userPsqlRepo.NewPsqlUserRepository(db)
 
 	err = a.Update(context.TODO(), u)
 	assert.NoError(t, err)
-}
\ No newline at end of file
+}
+func TestMarkEmailVerified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "UPDATE users SET email_verified_at=\\$1 WHERE id=\\$2 AND email=\\$3"
+	mock.ExpectExec(query).WithArgs(now, 12, "email1@gmail.com").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+
+	err = a.MarkEmailVerified(context.TODO(), 12, "email1@gmail.com", now)
+	assert.NoError(t, err)
+}
+
+func TestUpdatePassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "UPDATE users SET password=\\$1, updated_at=\\$2 WHERE id=\\$3"
+	mock.ExpectExec(query).WithArgs("hash", now, 12).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+
+	err = a.UpdatePassword(context.TODO(), 12, "hash", now)
+	assert.NoError(t, err)
+}
+
+func TestGetByIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "username", "name", "email", "email_verified_at", "created_at", "updated_at"}).
+		AddRow(1, "dias", "Dias", "dias@gmail.com", nil, time.Now(), time.Now()).
+		AddRow(3, "tanjung", "Tanjung", "tanjung@gmail.com", nil, time.Now(), time.Now())
+
+	query := "SELECT id, username, name, email, email_verified_at, created_at, updated_at\\s+FROM users WHERE id = ANY\\(\\$1\\) AND deleted_at IS NULL ORDER BY id"
+	mock.ExpectQuery(query).WillReturnRows(rows)
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+
+	list, err := a.GetByIDs(context.TODO(), []int64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, int64(3), list[1].ID)
+}
+
+func TestRestore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	query := "UPDATE users SET deleted_at=NULL, updated_at=\\$1 WHERE id=\\$2 AND deleted_at IS NOT NULL"
+	mock.ExpectExec(query).WithArgs(now, 12).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(now, 13).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+
+	err = a.Restore(context.TODO(), 12, now)
+	assert.NoError(t, err)
+	err = a.Restore(context.TODO(), 13, now)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	before := time.Now()
+	query := "DELETE FROM users WHERE id IN \\(\\s*SELECT id FROM users WHERE deleted_at < \\$1 ORDER BY deleted_at LIMIT \\$2\\)"
+	mock.ExpectExec(query).WithArgs(before, int64(100)).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+
+	n, err := a.Purge(context.TODO(), before, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+}
+
+func TestStoreBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	users := []domain.User{
+		{Username: "dias", Name: "Dias", Email: "dias@gmail.com", Password: "hash1", CreatedAt: now, UpdatedAt: now},
+		{Username: "tanjung", Name: "Tanjung", Email: "tanjung@gmail.com", Password: "hash2", CreatedAt: now, UpdatedAt: now},
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(`COPY "users" \("username", "name", "email", "password", "created_at", "updated_at"\) FROM STDIN`)
+	for _, u := range users {
+		prep.ExpectExec().WithArgs(u.Username, u.Name, u.Email, u.Password, u.CreatedAt, u.UpdatedAt).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT id, username FROM users WHERE username = ANY\\(\\$1\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(8, "tanjung").AddRow(7, "dias"))
+	mock.ExpectCommit()
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+	err = a.StoreBatch(context.TODO(), users)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), users[0].ID)
+	assert.Equal(t, int64(8), users[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchAfter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "username", "name", "email", "email_verified_at", "created_at", "updated_at"}).
+		AddRow(11, "username1", "Nama1", "email1@gmail.com", nil, time.Now(), time.Now())
+	query := "SELECT id, username, name, email, email_verified_at, created_at, updated_at\\s+FROM users WHERE id > \\$1 AND deleted_at IS NULL ORDER BY id LIMIT \\$2"
+	mock.ExpectQuery(query).WithArgs(10, 2).WillReturnRows(rows)
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+	list, err := a.FetchAfter(context.TODO(), 10, 2)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(11), list[0].ID)
+}
+
+func TestTaken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	// the first user only has a taken username, the second only a taken email, the last row is a former username
+	rows := sqlmock.NewRows([]string{"username", "email"}).
+		AddRow("dias", "other@gmail.com").
+		AddRow("other", "tanjung@gmail.com").
+		AddRow("Former", "")
+	mock.ExpectQuery("SELECT username, email FROM users WHERE lower\\(username\\) = ANY\\(\\$1\\) OR email = ANY\\(\\$2\\)\\s+UNION ALL SELECT username, '' FROM username_history WHERE lower\\(username\\) = ANY\\(\\$1\\)").
+		WithArgs(pq.Array([]string{"dias", "tanjung", "former"}), pq.Array([]string{"dias@gmail.com", "tanjung@gmail.com"})).
+		WillReturnRows(rows)
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+	usernames, emails, err := a.Taken(context.TODO(), []string{"Dias", "tanjung", "former"}, []string{"dias@gmail.com", "tanjung@gmail.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"Dias": true, "former": true}, usernames)
+	assert.Equal(t, map[string]bool{"tanjung@gmail.com": true}, emails)
+}
+
+func TestGetByUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "username", "name", "email", "email_verified_at", "created_at", "updated_at"}).
+		AddRow(1, "dias", "Dias", "dias@gmail.com", nil, time.Now(), time.Now())
+	query := "SELECT id, username, name, email, email_verified_at, created_at, updated_at\\s+FROM users WHERE deleted_at IS NULL AND \\(lower\\(username\\) = lower\\(\\$1\\)\\s+" +
+		"OR id = \\(SELECT user_id FROM username_history WHERE lower\\(username\\) = lower\\(\\$1\\)\\)\\)"
+	mock.ExpectQuery(query).WithArgs("Dias").WillReturnRows(rows)
+	mock.ExpectQuery(query).WithArgs("nobody").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+	u, err := a.GetByUsername(context.TODO(), "Dias")
+	assert.NoError(t, err)
+	assert.Equal(t, "dias", u.Username)
+
+	_, err = a.GetByUsername(context.TODO(), "nobody")
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreFormerUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	mock.ExpectExec("INSERT INTO username_history \\(user_id, username, changed_at\\) .* ON CONFLICT \\(\\(lower\\(username\\)\\)\\) DO UPDATE").
+		WithArgs(1, "dias", now).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	a := userPsqlRepo.NewPsqlUserRepository(db)
+	assert.NoError(t, a.StoreFormerUsername(context.TODO(), 1, "dias", now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/tracing"
+)
+
+type tracingUserRepository struct {
+	next   domain.UserRepository
+	tracer trace.Tracer
+}
+
+// NewTracingUserRepository will wrap a domain.UserRepository and start a span for every call
+func NewTracingUserRepository(next domain.UserRepository) domain.UserRepository {
+	return &tracingUserRepository{
+		next:   next,
+		tracer: otel.Tracer("github.com/diantanjung/blogo/user-service/user/repository"),
+	}
+}
+
+func (t *tracingUserRepository) Fetch(ctx context.Context, cursor string, num int64) (res []domain.User, nextCursor string, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.Fetch", trace.WithAttributes(attribute.Int64("num", num)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Fetch(ctx, cursor, num)
+}
+
+func (t *tracingUserRepository) GetByID(ctx context.Context, id int64) (res domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.GetByID", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetByID(ctx, id)
+}
+
+func (t *tracingUserRepository) GetByIDs(ctx context.Context, ids []int64) (res []domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.GetByIDs", trace.WithAttributes(attribute.Int("user.count", len(ids))))
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetByIDs(ctx, ids)
+}
+
+func (t *tracingUserRepository) GetByUsername(ctx context.Context, username string) (res domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.GetByUsername")
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetByUsername(ctx, username)
+}
+
+func (t *tracingUserRepository) GetByEmail(ctx context.Context, email string) (res domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.GetByEmail")
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetByEmail(ctx, email)
+}
+
+func (t *tracingUserRepository) Update(ctx context.Context, u *domain.User) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.Update", trace.WithAttributes(attribute.Int64("user.id", u.ID)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Update(ctx, u)
+}
+
+func (t *tracingUserRepository) Store(ctx context.Context, u *domain.User) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.Store")
+	defer func() { tracing.End(span, err) }()
+	return t.next.Store(ctx, u)
+}
+
+func (t *tracingUserRepository) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.Delete", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Delete(ctx, id)
+}
+
+func (t *tracingUserRepository) StoreBatch(ctx context.Context, users []domain.User) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.StoreBatch", trace.WithAttributes(attribute.Int("user.count", len(users))))
+	defer func() { tracing.End(span, err) }()
+	return t.next.StoreBatch(ctx, users)
+}
+
+func (t *tracingUserRepository) FetchAfter(ctx context.Context, afterID int64, num int64) (res []domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.FetchAfter", trace.WithAttributes(attribute.Int64("user.after_id", afterID), attribute.Int64("num", num)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.FetchAfter(ctx, afterID, num)
+}
+
+func (t *tracingUserRepository) Taken(ctx context.Context, usernames, emails []string) (takenUsernames, takenEmails map[string]bool, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.Taken")
+	defer func() { tracing.End(span, err) }()
+	return t.next.Taken(ctx, usernames, emails)
+}
+
+func (t *tracingUserRepository) StoreFormerUsername(ctx context.Context, id int64, username string, at time.Time) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.StoreFormerUsername", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.StoreFormerUsername(ctx, id, username, at)
+}
+
+func (t *tracingUserRepository) Restore(ctx context.Context, id int64, at time.Time) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.Restore", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Restore(ctx, id, at)
+}
+
+func (t *tracingUserRepository) Purge(ctx context.Context, deletedBefore time.Time, num int64) (n int64, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.Purge")
+	defer func() {
+		span.SetAttributes(attribute.Int64("user.count", n))
+		tracing.End(span, err)
+	}()
+	return t.next.Purge(ctx, deletedBefore, num)
+}
+
+func (t *tracingUserRepository) MarkEmailVerified(ctx context.Context, id int64, email string, at time.Time) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.MarkEmailVerified", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.MarkEmailVerified(ctx, id, email, at)
+}
+
+func (t *tracingUserRepository) UpdatePassword(ctx context.Context, id int64, hash string, at time.Time) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.UpdatePassword", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.UpdatePassword(ctx, id, hash, at)
+}
+
+func (t *tracingUserRepository) GetPasswordHash(ctx context.Context, id int64) (res string, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserRepository.GetPasswordHash", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetPasswordHash(ctx, id)
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+const redactedValue = "[REDACTED]"
+
+// auditRedacted lists the fields whose values never reach the audit log, only the fact they changed
+var auditRedacted = map[string]bool{"password": true}
+
+// auditIgnored lists the fields that change on every write and tell nothing about it
+var auditIgnored = map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+// audit will record the change of the target user made by the actor in ctx
+func (a *userUsecase) audit(ctx context.Context, action string, targetID int64, diff map[string]domain.AuditChange) error {
+	actor, _ := domain.ActorFromContext(ctx)
+	return a.auditRepo.Store(ctx, &domain.AuditEntry{
+		ActorID:      actor.UserID,
+		Action:       action,
+		TargetUserID: targetID,
+		Diff:         diff,
+		IP:           domain.ClientIPFromContext(ctx),
+		RequestID:    logging.RequestID(ctx),
+		CreatedAt:    time.Now(),
+	})
+}
+
+// auditDiff returns the fields that differ between before and after, keyed by their JSON name.
+// before is nil on creation and after is nil on deletion, both are a *domain.User or a *domain.Profile.
+func auditDiff(before, after interface{}) (map[string]domain.AuditChange, error) {
+	b, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	af, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]domain.AuditChange)
+	for _, fields := range []map[string]interface{}{b, af} {
+		for k := range fields {
+			if _, done := diff[k]; done || auditIgnored[k] || reflect.DeepEqual(b[k], af[k]) {
+				continue
+			}
+			change := domain.AuditChange{Before: b[k], After: af[k]}
+			if auditRedacted[k] {
+				change = domain.AuditChange{Before: redactValue(b[k]), After: redactValue(af[k])}
+			}
+			diff[k] = change
+		}
+	}
+	return diff, nil
+}
+
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil || reflect.ValueOf(v).IsNil() {
+		return fields, nil
+	}
+	byt, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(byt, &fields)
+	return fields, err
+}
+
+func redactValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return redactedValue
+}
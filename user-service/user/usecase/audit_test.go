@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+)
+
+func TestAuditDiff(t *testing.T) {
+	before := &domain.User{ID: 2, Username: "dias", Name: "Dias", Email: "dias@gmail.com", Password: "$2a$10$old",
+		Roles: []domain.Role{domain.RoleReader}, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	cases := []struct {
+		name   string
+		before *domain.User
+		after  func(u domain.User) *domain.User
+		want   map[string]domain.AuditChange
+	}{
+		{
+			name:   "unchanged",
+			before: before,
+			after: func(u domain.User) *domain.User {
+				u.UpdatedAt = u.UpdatedAt.Add(time.Minute)
+				return &u
+			},
+			want: map[string]domain.AuditChange{},
+		},
+		{
+			name:   "changed fields only",
+			before: before,
+			after: func(u domain.User) *domain.User {
+				u.Name = "Dias Tanjung"
+				u.Roles = []domain.Role{domain.RoleReader, domain.RoleAuthor}
+				return &u
+			},
+			want: map[string]domain.AuditChange{
+				"name":  {Before: "Dias", After: "Dias Tanjung"},
+				"roles": {Before: []interface{}{"reader"}, After: []interface{}{"reader", "author"}},
+			},
+		},
+		{
+			name:   "password",
+			before: before,
+			after: func(u domain.User) *domain.User {
+				u.Password = "$2a$10$new"
+				return &u
+			},
+			want: map[string]domain.AuditChange{"password": {Before: redactedValue, After: redactedValue}},
+		},
+		{
+			name:   "created",
+			before: nil,
+			after: func(u domain.User) *domain.User {
+				return &u
+			},
+			want: map[string]domain.AuditChange{
+				"username": {After: "dias"},
+				"name":     {After: "Dias"},
+				"email":    {After: "dias@gmail.com"},
+				"password": {After: redactedValue},
+				"roles":    {After: []interface{}{"reader"}},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := auditDiff(tc.before, tc.after(*before))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, diff)
+		})
+	}
+}
+
+func TestRecordNeverStoresThePasswordHash(t *testing.T) {
+	var entry *domain.AuditEntry
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entry = args.Get(1).(*domain.AuditEntry)
+	}).Return(nil)
+	var event domain.Event
+	mockOutboxRepo := new(mocks.OutboxRepository)
+	mockOutboxRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		event = args.Get(1).(domain.Event)
+	}).Return(nil)
+	a := &userUsecase{auditRepo: mockAuditRepo, outboxRepo: mockOutboxRepo}
+
+	before := &domain.User{ID: 2, Username: "dias", Name: "Dias", Email: "dias@gmail.com", Password: "$2a$10$old"}
+	after := *before
+	after.Password = "$2a$10$new"
+	ctx := domain.NewContextWithActor(context.TODO(), domain.Actor{UserID: 1})
+	require.NoError(t, a.record(ctx, domain.AuditUserUpdated, domain.EventUserUpdated, before, &after))
+
+	require.NotNil(t, entry)
+	assert.Equal(t, int64(1), entry.ActorID)
+	assert.Equal(t, map[string]domain.AuditChange{"password": {Before: redactedValue, After: redactedValue}}, entry.Diff)
+	assert.Equal(t, domain.EventUserUpdated, event.Type)
+	assert.NotContains(t, string(event.Payload), "$2a$10$")
+}
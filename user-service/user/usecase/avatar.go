@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/imaging"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// AvatarConfig configures the avatar uploads
+type AvatarConfig struct {
+	Store domain.BlobStore
+	// Sizes are the sides in pixels of the square thumbnails, the largest is the AvatarURL of the profile
+	Sizes []int
+	// MaxBytes bounds the size of the upload, MaxPixels the size of the decoded image
+	MaxBytes  int64
+	MaxPixels int64
+	// Quality is the JPEG quality of the thumbnails, from 1 to 100
+	Quality int
+}
+
+// UpdateAvatar will store the thumbnails of the image and record their URLs on the profile. The
+// thumbnails are stored under the same keys on every upload, their URLs change with the image so
+// that caches pick the new one up.
+func (a *userUsecase) UpdateAvatar(c context.Context, userID int64, r io.Reader) (res domain.Profile, err error) {
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": userID})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionUpdateProfile, userID); err != nil {
+		return
+	}
+	if _, err = a.userRepo.GetByID(ctx, userID); err != nil {
+		return
+	}
+
+	cfg := a.cfg.Avatar
+	data, err := io.ReadAll(io.LimitReader(r, cfg.MaxBytes+1))
+	if err != nil {
+		return
+	}
+	if int64(len(data)) > cfg.MaxBytes {
+		return domain.Profile{}, domain.ErrTooLarge
+	}
+	img, err := imaging.Decode(data, cfg.MaxPixels)
+	if err != nil {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	version := hex.EncodeToString(sum[:8])
+	sizes := append([]int(nil), cfg.Sizes...)
+	sort.Ints(sizes)
+	avatars := make([]domain.ProfileAvatar, 0, len(sizes))
+	for _, size := range sizes {
+		thumb, err := imaging.EncodeJPEG(imaging.Thumbnail(img, size), cfg.Quality)
+		if err != nil {
+			return domain.Profile{}, err
+		}
+		url, err := cfg.Store.Put(ctx, fmt.Sprintf("avatars/%d/%d.jpg", userID, size), bytes.NewReader(thumb), "image/jpeg")
+		if err != nil {
+			return domain.Profile{}, err
+		}
+		avatars = append(avatars, domain.ProfileAvatar{Size: size, URL: url + "?v=" + version})
+	}
+
+	err = a.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		u, err := a.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		var before *domain.Profile
+		p, err := a.profileRepo.GetByUserID(ctx, userID)
+		switch err {
+		case nil:
+			fillProfile(&p, u)
+			existed := p
+			before = &existed
+		case domain.ErrNotFound:
+			p = domain.Profile{UserID: userID}
+		default:
+			return err
+		}
+
+		p.Avatars = avatars
+		p.AvatarURL = ""
+		if len(avatars) > 0 {
+			p.AvatarURL = avatars[len(avatars)-1].URL
+		}
+		p.UpdatedAt = time.Now()
+		if err = a.profileRepo.Upsert(ctx, &p); err != nil {
+			return err
+		}
+		fillProfile(&p, u)
+		res = p
+
+		diff, err := auditDiff(before, &p)
+		if err != nil {
+			return err
+		}
+		return a.audit(ctx, domain.AuditProfileUpdated, userID, diff)
+	})
+	if err != nil {
+		return domain.Profile{}, err
+	}
+	return res, nil
+}
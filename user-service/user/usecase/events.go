@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// newUserEvent will build an event about u, the fields of diff are listed as changed
+func newUserEvent(eventType string, u *domain.User, diff map[string]domain.AuditChange) (e domain.Event, err error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return
+	}
+
+	var changed []string
+	if eventType == domain.EventUserUpdated {
+		for k := range diff {
+			changed = append(changed, k)
+		}
+		sort.Strings(changed)
+	}
+	payload, err := json.Marshal(domain.UserEvent{
+		Username: u.Username,
+		Name:     u.Name,
+		Email:    u.Email,
+		Changed:  changed,
+	})
+	if err != nil {
+		return
+	}
+
+	return domain.Event{
+		ID:          id,
+		Type:        eventType,
+		AggregateID: u.ID,
+		Payload:     payload,
+		OccurredAt:  time.Now(),
+	}, nil
+}
+
+// record will write the event and the audit entry of a change to the user, in the transaction of ctx.
+// before is nil on creation and after is nil on deletion. Updates changing nothing emit no event.
+func (a *userUsecase) record(ctx context.Context, action, eventType string, before, after *domain.User) error {
+	diff, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	u := after
+	if u == nil {
+		u = before
+	}
+	if eventType != domain.EventUserUpdated || len(diff) > 0 {
+		event, err := newUserEvent(eventType, u, diff)
+		if err != nil {
+			return err
+		}
+		if err = a.outboxRepo.Store(ctx, event); err != nil {
+			return err
+		}
+	}
+	return a.audit(ctx, action, u.ID, diff)
+}
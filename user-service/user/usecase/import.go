@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// defaultImportBatch is the number of users imported per transaction when UserConfig.ImportBatch is unset
+const defaultImportBatch = 500
+
+type importRow struct {
+	row  int
+	user domain.User
+}
+
+// importer keeps the state of a bulk import across its batches
+type importer struct {
+	*userUsecase
+	dryRun    bool
+	report    domain.ImportReport
+	usernames map[string]bool
+	emails    map[string]bool
+}
+
+func (a *userUsecase) importBatch() int {
+	if a.cfg.ImportBatch > 0 {
+		return int(a.cfg.ImportBatch)
+	}
+	return defaultImportBatch
+}
+
+func (a *userUsecase) Import(ctx context.Context, r domain.UserReader, dryRun bool) (res domain.ImportReport, err error) {
+	if err = authorize(ctx, actionImport, 0); err != nil {
+		return
+	}
+
+	im := &importer{
+		userUsecase: a,
+		dryRun:      dryRun,
+		report:      domain.ImportReport{DryRun: dryRun, Failed: []domain.ImportFailure{}},
+		usernames:   make(map[string]bool),
+		emails:      make(map[string]bool),
+	}
+	size := a.importBatch()
+	batch := make([]importRow, 0, size)
+	for {
+		u, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		im.report.Total++
+		row := im.report.Total
+		if errors.Is(err, domain.ErrBadParamInput) {
+			im.fail(row, u.Username, err)
+			continue
+		}
+		if err != nil {
+			return im.report, err
+		}
+
+		if err = im.validate(&u); err != nil {
+			im.fail(row, u.Username, err)
+			continue
+		}
+		batch = append(batch, importRow{row: row, user: u})
+		if len(batch) == size {
+			im.flush(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+	im.flush(ctx, batch)
+	return im.report, nil
+}
+
+func (im *importer) fail(row int, username string, err error) {
+	im.report.Failed = append(im.report.Failed, domain.ImportFailure{Row: row, Username: username, Error: err.Error()})
+}
+
+// validate will apply the rules of Store to u, and reject the usernames and emails seen earlier in the import
+func (im *importer) validate(u *domain.User) error {
+	if len(u.Roles) == 0 {
+		u.Roles = []domain.Role{domain.RoleReader}
+	}
+	now := time.Now()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+	u.EmailVerifiedAt = nil
+
+	if err := isUserValid(u); err != nil {
+		return err
+	}
+	if err := isPasswordStrong(u.Password); err != nil {
+		return err
+	}
+	// usernames are unique ignoring case
+	username := strings.ToLower(u.Username)
+	if im.usernames[username] || im.emails[u.Email] {
+		return domain.ErrConflict
+	}
+	im.usernames[username] = true
+	im.emails[u.Email] = true
+	return nil
+}
+
+// flush will import a batch in its own transaction, the rows conflicting with existing users are
+// reported. A failing batch is reported as a whole and the import goes on with the next one.
+// The passwords are hashed outside of the deadlines, bcrypt takes far longer than the queries of a batch.
+func (im *importer) flush(c context.Context, batch []importRow) {
+	if len(batch) == 0 {
+		return
+	}
+
+	batch, err := im.skipTaken(c, batch)
+	if err != nil {
+		im.failBatch(c, batch, err)
+		return
+	}
+	if im.dryRun {
+		im.report.Imported += len(batch)
+		return
+	}
+	if err = hashPasswords(batch); err != nil {
+		im.failBatch(c, batch, err)
+		return
+	}
+
+	users := make([]domain.User, len(batch))
+	for i := range batch {
+		users[i] = batch[i].user
+	}
+	ctx, cancel := context.WithTimeout(c, im.contextTimeout)
+	defer cancel()
+	err = im.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := im.userRepo.StoreBatch(ctx, users); err != nil {
+			return err
+		}
+		roles := make(map[int64][]domain.Role, len(users))
+		for _, u := range users {
+			roles[u.ID] = u.Roles
+		}
+		if err := im.roleRepo.StoreBatch(ctx, roles); err != nil {
+			return err
+		}
+		// fn may run again on a serialization failure, users keep their password hashes for the next run,
+		// record redacts them
+		for i := range users {
+			if err := im.record(ctx, domain.AuditUserCreated, domain.EventUserCreated, nil, &users[i]); err != nil {
+				return err
+			}
+			// the verification emails are enqueued with the users, not sent one by one within the deadline
+			if err := im.cfg.Verification.send(ctx, &users[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		im.failBatch(ctx, batch, err)
+		return
+	}
+	im.report.Imported += len(users)
+}
+
+// skipTaken will report and drop the rows whose username or email belongs to an existing user
+func (im *importer) skipTaken(c context.Context, batch []importRow) ([]importRow, error) {
+	ctx, cancel := context.WithTimeout(c, im.contextTimeout)
+	defer cancel()
+
+	usernames := make([]string, len(batch))
+	emails := make([]string, len(batch))
+	for i, r := range batch {
+		usernames[i] = r.user.Username
+		emails[i] = r.user.Email
+	}
+	takenUsernames, takenEmails, err := im.userRepo.Taken(ctx, usernames, emails)
+	if err != nil {
+		return batch, err
+	}
+
+	free := batch[:0]
+	for _, r := range batch {
+		if takenUsernames[r.user.Username] || takenEmails[r.user.Email] {
+			im.fail(r.row, r.user.Username, domain.ErrConflict)
+			continue
+		}
+		free = append(free, r)
+	}
+	return free, nil
+}
+
+// failBatch will report every row of the batch, the errors that are not domain errors are logged
+// and reported as internal ones
+func (im *importer) failBatch(ctx context.Context, batch []importRow, err error) {
+	if !isDomainError(err) {
+		logging.FromContext(ctx).Error(err)
+		err = domain.ErrInternalServerError
+	}
+	for _, r := range batch {
+		im.fail(r.row, r.user.Username, err)
+	}
+}
+
+func isDomainError(err error) bool {
+	switch err {
+	case domain.ErrConflict, domain.ErrBadParamInput, domain.ErrInternalServerError:
+		return true
+	}
+	return false
+}
+
+// hashPasswords will hash the passwords of the batch on every CPU, bcrypt being slow on purpose
+func hashPasswords(batch []importRow) error {
+	rows := make(chan *importRow)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range rows {
+				hash, err := hashPassword(r.user.Password)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					continue
+				}
+				r.user.Password = hash
+			}
+		}()
+	}
+	for i := range batch {
+		rows <- &batch[i]
+	}
+	close(rows)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// Export will page through the users by id, so that users created during the export are not missed or repeated
+func (a *userUsecase) Export(ctx context.Context, fn func(domain.User) error) error {
+	if err := authorize(ctx, actionExport, 0); err != nil {
+		return err
+	}
+
+	var afterID int64
+	for {
+		page, err := a.exportPage(ctx, afterID)
+		if err != nil {
+			return err
+		}
+		for _, u := range page {
+			if err = fn(u); err != nil {
+				return err
+			}
+		}
+		if len(page) < a.importBatch() {
+			return nil
+		}
+		afterID = page[len(page)-1].ID
+	}
+}
+
+func (a *userUsecase) exportPage(c context.Context, afterID int64) ([]domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	page, err := a.userRepo.FetchAfter(ctx, afterID, int64(a.importBatch()))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, len(page))
+	for i := range page {
+		ids[i] = page[i].ID
+	}
+	roles, err := a.roleRepo.GetByUserIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page {
+		page[i].Roles = roles[page[i].ID]
+		redact(ctx, &page[i])
+	}
+	return page, nil
+}
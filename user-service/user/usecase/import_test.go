@@ -0,0 +1,121 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/user/usecase"
+)
+
+func adminContext() context.Context {
+	return domain.NewContextWithActor(context.TODO(), domain.Actor{UserID: 1, Roles: []domain.Role{domain.RoleAdmin}})
+}
+
+// withinTx makes the mocked transactions run their function
+func withinTx(tx *mocks.TxManager) {
+	tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	})
+}
+
+func TestImport(t *testing.T) {
+	const rows = 4
+	mockReader := new(mocks.UserReader)
+	for i := 1; i <= rows; i++ {
+		mockReader.On("Read").Return(domain.User{
+			Username: fmt.Sprintf("dias%d", i),
+			Name:     "Dias",
+			Email:    fmt.Sprintf("dias%d@gmail.com", i),
+			Password: "s3cret-password",
+		}, nil).Once()
+	}
+	mockReader.On("Read").Return(domain.User{}, io.EOF)
+
+	// every call must get a live context, the deadline does not cover the hashing of the passwords
+	live := func(args mock.Arguments) {
+		assert.NoError(t, args.Get(0).(context.Context).Err())
+	}
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("Taken", mock.Anything, mock.Anything, mock.Anything).Run(live).
+		Return(map[string]bool{}, map[string]bool{}, nil)
+	mockUserRepo.On("StoreBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		live(args)
+		users := args.Get(1).([]domain.User)
+		for i := range users {
+			assert.NotEqual(t, "s3cret-password", users[i].Password)
+			users[i].ID = int64(10 + i)
+		}
+	}).Return(nil)
+	mockRoleRepo := new(mocks.RoleRepository)
+	mockRoleRepo.On("StoreBatch", mock.Anything, mock.Anything).Run(live).Return(nil)
+	mockOutboxRepo := new(mocks.OutboxRepository)
+	mockOutboxRepo.On("Store", mock.Anything, mock.Anything).Run(live).Return(nil).Times(rows)
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Store", mock.Anything, mock.Anything).Run(live).Return(nil).Times(rows)
+	mockJobUCase := new(mocks.JobUsecase)
+	mockJobUCase.On("Enqueue", mock.Anything, mock.MatchedBy(func(j *domain.Job) bool {
+		return j.Type == domain.JobSendVerification
+	})).Run(live).Return(nil).Times(rows)
+	mockTx := new(mocks.TxManager)
+	withinTx(mockTx)
+
+	cfg := usecase.UserConfig{
+		Verification: usecase.EmailVerification{Jobs: mockJobUCase, Secret: []byte("secret"), TTL: time.Hour},
+		ImportBatch:  rows - 1,
+	}
+	// hashing a single password takes longer than the timeout
+	u := usecase.NewUserUsecase(mockUserRepo, mockRoleRepo, nil, nil, nil, mockAuditRepo, mockOutboxRepo, mockTx, cfg, 5*time.Millisecond)
+
+	report, err := u.Import(adminContext(), mockReader, false)
+	require.NoError(t, err)
+	assert.Equal(t, rows, report.Total)
+	assert.Equal(t, rows, report.Imported)
+	assert.Empty(t, report.Failed)
+	mockUserRepo.AssertNumberOfCalls(t, "StoreBatch", 2)
+	mockJobUCase.AssertExpectations(t)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestImportRetriedBatch(t *testing.T) {
+	mockReader := new(mocks.UserReader)
+	mockReader.On("Read").Return(domain.User{Username: "dias", Name: "Dias", Email: "dias@gmail.com", Password: "s3cret-password"}, nil).Once()
+	mockReader.On("Read").Return(domain.User{}, io.EOF)
+
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("Taken", mock.Anything, mock.Anything, mock.Anything).Return(map[string]bool{}, map[string]bool{}, nil)
+	mockUserRepo.On("StoreBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		for _, u := range args.Get(1).([]domain.User) {
+			assert.NotEmpty(t, u.Password, "every run stores the password hashes")
+		}
+	}).Return(nil)
+	mockRoleRepo := new(mocks.RoleRepository)
+	mockRoleRepo.On("StoreBatch", mock.Anything, mock.Anything).Return(nil)
+	mockOutboxRepo := new(mocks.OutboxRepository)
+	mockOutboxRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	// the transaction is retried once, as on a serialization failure
+	mockTx := new(mocks.TxManager)
+	mockTx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return fn(ctx)
+	})
+
+	u := usecase.NewUserUsecase(mockUserRepo, mockRoleRepo, nil, nil, nil, mockAuditRepo, mockOutboxRepo, mockTx, usecase.UserConfig{ImportBatch: 10}, time.Second)
+
+	report, err := u.Import(adminContext(), mockReader, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	mockUserRepo.AssertNumberOfCalls(t, "StoreBatch", 2)
+}
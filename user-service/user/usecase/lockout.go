@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// LockoutPolicy configures the protection of logins against brute-force and credential stuffing.
+// Failures are counted per account and per client IP, a threshold of 0 disables the counter.
+type LockoutPolicy struct {
+	AccountThreshold int64
+	IPThreshold      int64
+	// Backoff is the wait after the first failure, doubled on every following failure until the threshold
+	Backoff time.Duration
+	// Lockout is the lock once the threshold is reached, doubled on every following failure up to MaxLockout
+	Lockout    time.Duration
+	MaxLockout time.Duration
+	// Window is how long failures are remembered
+	Window time.Duration
+}
+
+const maxDuration = time.Duration(math.MaxInt64)
+
+type lockKey struct {
+	key       string
+	threshold int64
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func (p LockoutPolicy) keys(ctx context.Context, email string) []lockKey {
+	keys := make([]lockKey, 0, 2)
+	if p.AccountThreshold > 0 {
+		keys = append(keys, lockKey{key: accountKey(email), threshold: p.AccountThreshold})
+	}
+	if ip := domain.ClientIPFromContext(ctx); ip != "" && p.IPThreshold > 0 {
+		keys = append(keys, lockKey{key: "ip:" + ip, threshold: p.IPThreshold})
+	}
+	return keys
+}
+
+// delay is how long a key has to wait after its last failure before the next login attempt
+func (p LockoutPolicy) delay(failures, threshold int64) time.Duration {
+	var (
+		base  time.Duration
+		shift int64
+	)
+	switch {
+	case failures <= 0:
+		return 0
+	case failures < threshold:
+		base, shift = p.Backoff, failures-1
+	default:
+		base, shift = p.Lockout, failures-threshold
+	}
+	if base <= 0 {
+		return 0
+	}
+
+	// doubling once more than the bits left in a Duration would wrap around, the delay is then the longest one
+	d := maxDuration
+	if shift < 63 && base <= maxDuration>>uint(shift) {
+		d = base << uint(shift)
+	}
+	if p.MaxLockout > 0 && d > p.MaxLockout {
+		d = p.MaxLockout
+	}
+	return d
+}
+
+// checkLocked will return domain.ErrTooManyAttempts when any of the keys has to wait before trying again
+func (a *authUsecase) checkLocked(ctx context.Context, keys []lockKey, now time.Time) error {
+	for _, k := range keys {
+		attempt, err := a.attempts.Get(ctx, k.key)
+		if err != nil {
+			return err
+		}
+		retryAt := attempt.LastFailureAt.Add(a.cfg.Lockout.delay(attempt.Failures, k.threshold))
+		if now.Before(retryAt) {
+			auditLogin(ctx, "login.blocked", logrus.Fields{"key": k.key, "failures": attempt.Failures, "retry_at": retryAt})
+			return domain.ErrTooManyAttempts
+		}
+	}
+	return nil
+}
+
+func (a *authUsecase) registerFailure(ctx context.Context, keys []lockKey, now time.Time) error {
+	for _, k := range keys {
+		attempt, err := a.attempts.RegisterFailure(ctx, k.key, now, a.cfg.Lockout.Window)
+		if err != nil {
+			return err
+		}
+		auditLogin(ctx, "login.failed", logrus.Fields{"key": k.key, "failures": attempt.Failures})
+		if attempt.Failures == k.threshold {
+			auditLogin(ctx, "login.locked", logrus.Fields{"key": k.key, "failures": attempt.Failures})
+		}
+	}
+	return nil
+}
+
+// auditLogin will write an audit entry about the login protection
+func auditLogin(ctx context.Context, event string, fields logrus.Fields) {
+	entry := logging.FromContext(ctx).WithFields(fields).WithField("audit", event)
+	if ip := domain.ClientIPFromContext(ctx); ip != "" {
+		entry = entry.WithField("client_ip", ip)
+	}
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		entry = entry.WithField("actor_id", actor.UserID)
+	}
+	entry.Warn(event)
+}
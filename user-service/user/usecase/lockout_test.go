@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutDelay(t *testing.T) {
+	p := LockoutPolicy{Backoff: time.Second, Lockout: 15 * time.Minute, MaxLockout: 24 * time.Hour}
+	cases := []struct {
+		name     string
+		policy   LockoutPolicy
+		failures int64
+		want     time.Duration
+	}{
+		{name: "no failure", policy: p, failures: 0, want: 0},
+		{name: "first failure", policy: p, failures: 1, want: time.Second},
+		{name: "backoff doubles", policy: p, failures: 3, want: 4 * time.Second},
+		{name: "threshold", policy: p, failures: 5, want: 15 * time.Minute},
+		{name: "lockout doubles", policy: p, failures: 7, want: time.Hour},
+		{name: "clamped", policy: p, failures: 12, want: 24 * time.Hour},
+		{name: "shift overflow", policy: p, failures: 100, want: 24 * time.Hour},
+		{name: "no backoff", policy: LockoutPolicy{Lockout: time.Minute, MaxLockout: time.Hour}, failures: 2, want: 0},
+		{name: "no lockout", policy: LockoutPolicy{Backoff: time.Second, MaxLockout: time.Hour}, failures: 6, want: 0},
+		{name: "unbounded", policy: LockoutPolicy{Lockout: time.Minute}, failures: 7, want: 4 * time.Minute},
+		{name: "unbounded overflow", policy: LockoutPolicy{Lockout: time.Minute}, failures: 60, want: maxDuration},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.policy.delay(tc.failures, 5))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+const minPasswordLength = 8
+
+// dummyHash is compared against when there is no user to check the password of, so the answer takes
+// as long as for a wrong password and does not tell whether the account exists. It has the cost of
+// hashPassword.
+const dummyHash = "$2a$10$67PsHgloQ.7q3jNTjFfzQ.CV0qvEzN9uj4oVDyXp1bLCkYg1H3n.e"
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func checkPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// isPasswordStrong will check the password strength policy: at least 8 characters with a letter and a digit
+func isPasswordStrong(password string) error {
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if len([]rune(password)) < minPasswordLength || !letter || !digit {
+		return domain.ErrWeakPassword
+	}
+	return nil
+}
+
+// hashToken will hash a random token before it is stored, tokens are high entropy so a fast hash is enough
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
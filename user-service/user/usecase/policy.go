@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+type action string
+
+const (
+	actionUpdate         action = "update"
+	actionDelete         action = "delete"
+	actionRestore        action = "restore"
+	actionViewEmail      action = "view_email"
+	actionAssignRoles    action = "assign_roles"
+	actionChangePassword action = "change_password"
+	actionUnlock         action = "unlock"
+	actionResetLink      action = "reset_link"
+	actionViewAudit      action = "view_audit"
+	actionImport         action = "import"
+	actionExport         action = "export"
+	actionUpdateProfile  action = "update_profile"
+)
+
+// policies lists, for every action, whether the actor may perform it on the target user.
+// Admins are allowed every action, so they are not listed here.
+var policies = map[action]func(actor domain.Actor, targetID int64) bool{
+	actionUpdate:         isSelf,
+	actionDelete:         isSelf,
+	actionRestore:        func(domain.Actor, int64) bool { return false },
+	actionViewEmail:      isSelf,
+	actionAssignRoles:    func(domain.Actor, int64) bool { return false },
+	actionChangePassword: isSelf,
+	actionUnlock:         func(domain.Actor, int64) bool { return false },
+	actionResetLink:      func(domain.Actor, int64) bool { return false },
+	actionViewAudit:      func(domain.Actor, int64) bool { return false },
+	actionImport:         func(domain.Actor, int64) bool { return false },
+	actionExport:         func(domain.Actor, int64) bool { return false },
+	actionUpdateProfile:  isSelf,
+}
+
+func isSelf(actor domain.Actor, targetID int64) bool {
+	return actor.UserID == targetID
+}
+
+// authorize will check whether the actor in ctx may perform act on the target user.
+// It returns domain.ErrUnauthorized when there is no actor and domain.ErrForbidden when the policy denies it.
+func authorize(ctx context.Context, act action, targetID int64) error {
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+	if actor.IsAdmin() {
+		return nil
+	}
+	if allow, ok := policies[act]; ok && allow(actor, targetID) {
+		return nil
+	}
+	return domain.ErrForbidden
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	validator "gopkg.in/go-playground/validator.v9"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+func (a *userUsecase) GetProfile(c context.Context, userID int64) (res domain.Profile, err error) {
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": userID})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	u, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	res, err = a.profileRepo.GetByUserID(ctx, userID)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.Profile{}, err
+	}
+	fillProfile(&res, u)
+	return res, nil
+}
+
+// UpdateProfile will replace the whole profile, the fields missing from p are cleared. The avatar
+// thumbnails can only be set by UpdateAvatar.
+func (a *userUsecase) UpdateProfile(c context.Context, p *domain.Profile) (err error) {
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": p.UserID})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionUpdateProfile, p.UserID); err != nil {
+		return
+	}
+	if err = validator.New().Struct(p); err != nil {
+		return domain.ErrBadParamInput
+	}
+
+	return a.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		u, err := a.userRepo.GetByID(ctx, p.UserID)
+		if err != nil {
+			return err
+		}
+		var before *domain.Profile
+		existed, err := a.profileRepo.GetByUserID(ctx, p.UserID)
+		switch err {
+		case nil:
+			fillProfile(&existed, u)
+			before = &existed
+		case domain.ErrNotFound:
+		default:
+			return err
+		}
+		// the uploaded thumbnails are kept until another avatar is set
+		p.Avatars = nil
+		if before != nil && p.AvatarURL == before.AvatarURL {
+			p.Avatars = before.Avatars
+		}
+
+		p.UpdatedAt = time.Now()
+		if err = a.profileRepo.Upsert(ctx, p); err != nil {
+			return err
+		}
+		fillProfile(p, u)
+
+		diff, err := auditDiff(before, p)
+		if err != nil {
+			return err
+		}
+		return a.audit(ctx, domain.AuditProfileUpdated, p.UserID, diff)
+	})
+}
+
+// fillProfile will set the fields of the profile that come from its user
+func fillProfile(p *domain.Profile, u domain.User) {
+	p.UserID = u.ID
+	p.Username = u.Username
+	p.Name = u.Name
+	if p.Avatars == nil {
+		p.Avatars = []domain.ProfileAvatar{}
+	}
+	if p.Links == nil {
+		p.Links = []domain.ProfileLink{}
+	}
+	p.JoinedAt = nil
+	if p.Preferences.ShowJoinDate {
+		joinedAt := u.CreatedAt
+		p.JoinedAt = &joinedAt
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// purgeBatch is the number of rows deleted per statement, so a purge does not lock the whole table
+const purgeBatch = 500
+
+// NewPurgeUsersHandler will create the handler of the domain.JobPurgeUsers jobs, deleting for good the users
+// that can no longer be restored
+func NewPurgeUsersHandler(userRepo domain.UserRepository) func(ctx context.Context, p domain.PurgeUsersJob) error {
+	return func(ctx context.Context, p domain.PurgeUsersJob) error {
+		retention, err := time.ParseDuration(p.Retention)
+		if err != nil || retention <= 0 {
+			return fmt.Errorf("%w: invalid retention %q", domain.ErrBadParamInput, p.Retention)
+		}
+
+		before := time.Now().Add(-retention)
+		var total int64
+		for {
+			n, err := userRepo.Purge(ctx, before, purgeBatch)
+			if err != nil {
+				return err
+			}
+			total += n
+			if n < purgeBatch {
+				break
+			}
+		}
+		logging.FromContext(ctx).WithField("job_type", domain.JobPurgeUsers).Infof("%d deleted users purged", total)
+		return nil
+	}
+}
+
+// NewPruneRateLimitsHandler will create the handler of the domain.JobPruneRateLimits jobs, deleting the
+// rate limit buckets nobody took from for a while
+func NewPruneRateLimitsHandler(store domain.RateLimitStore) func(ctx context.Context, p domain.PruneRateLimitsJob) error {
+	return func(ctx context.Context, p domain.PruneRateLimitsJob) error {
+		idleFor, err := time.ParseDuration(p.IdleFor)
+		if err != nil || idleFor <= 0 {
+			return fmt.Errorf("%w: invalid idle duration %q", domain.ErrBadParamInput, p.IdleFor)
+		}
+
+		idleSince := time.Now().Add(-idleFor)
+		var total int64
+		for {
+			n, err := store.Prune(ctx, idleSince, purgeBatch)
+			if err != nil {
+				return err
+			}
+			total += n
+			if n < purgeBatch {
+				break
+			}
+		}
+		logging.FromContext(ctx).WithField("job_type", domain.JobPruneRateLimits).Infof("%d rate limit buckets pruned", total)
+		return nil
+	}
+}
@@ -0,0 +1,379 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+	validator "gopkg.in/go-playground/validator.v9"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+// AuthConfig configures the authUsecase
+type AuthConfig struct {
+	// Secret signs the access tokens
+	Secret   []byte
+	TokenTTL time.Duration
+	// Verification tells what users who have not verified their email are restricted from
+	Verification domain.VerificationPolicy
+	// Jobs enqueues the password reset emails, the link is only created when the job worker sends them with ResetMailer
+	Jobs        domain.JobUsecase
+	ResetMailer domain.Mailer
+	ResetTTL    time.Duration
+	// ResetURL is the page the reset token is appended to, e.g. https://blogo.dev/reset?token=
+	ResetURL string
+	Lockout  LockoutPolicy
+}
+
+type authUsecase struct {
+	userRepo       domain.UserRepository
+	roleRepo       domain.RoleRepository
+	sessionRepo    domain.SessionRepository
+	resetRepo      domain.PasswordResetRepository
+	historyRepo    domain.PasswordHistoryRepository
+	attempts       domain.LoginAttemptStore
+	txManager      domain.TxManager
+	cfg            AuthConfig
+	contextTimeout time.Duration
+}
+
+// NewAuthUsecase will create new an authUsecase object representation of domain.AuthUsecase interface
+func NewAuthUsecase(u domain.UserRepository, r domain.RoleRepository, s domain.SessionRepository, pr domain.PasswordResetRepository, h domain.PasswordHistoryRepository, la domain.LoginAttemptStore, tx domain.TxManager, cfg AuthConfig, timeout time.Duration) domain.AuthUsecase {
+	return &authUsecase{
+		userRepo:       u,
+		roleRepo:       r,
+		sessionRepo:    s,
+		resetRepo:      pr,
+		historyRepo:    h,
+		attempts:       la,
+		txManager:      tx,
+		cfg:            cfg,
+		contextTimeout: timeout,
+	}
+}
+
+func (a *authUsecase) Login(c context.Context, cred domain.Credentials) (res domain.Token, err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = validator.New().Struct(cred); err != nil {
+		return res, domain.ErrBadParamInput
+	}
+
+	now := time.Now()
+	keys := a.cfg.Lockout.keys(ctx, cred.Email)
+	if err = a.checkLocked(ctx, keys, now); err != nil {
+		return
+	}
+
+	user, err := a.userRepo.GetByEmail(ctx, cred.Email)
+	if err != nil && err != domain.ErrNotFound {
+		return
+	}
+	found := err != domain.ErrNotFound
+	if !found {
+		user.Password = dummyHash
+	}
+	// the password is checked without a user too, see dummyHash
+	if !checkPassword(user.Password, cred.Password) || !found {
+		if err = a.registerFailure(ctx, keys, now); err != nil {
+			return
+		}
+		return res, domain.ErrInvalidCredentials
+	}
+	if err = a.attempts.Reset(ctx, accountKey(cred.Email)); err != nil {
+		return
+	}
+	if a.cfg.Verification == domain.VerificationForLogin && user.EmailVerifiedAt == nil {
+		return res, domain.ErrEmailNotVerified
+	}
+
+	return a.newSession(ctx, user.ID)
+}
+
+func (a *authUsecase) Logout(c context.Context) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+	return a.sessionRepo.Revoke(ctx, actor.SessionID)
+}
+
+func (a *authUsecase) Authenticate(c context.Context, token string) (res domain.Actor, err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	claims := jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return a.cfg.Secret, nil
+	})
+	if err != nil {
+		return res, domain.ErrUnauthorized
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return res, domain.ErrUnauthorized
+	}
+
+	session, err := a.sessionRepo.GetByID(ctx, claims.ID)
+	if err == domain.ErrNotFound {
+		return res, domain.ErrUnauthorized
+	}
+	if err != nil {
+		return
+	}
+	if session.UserID != userID || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return res, domain.ErrUnauthorized
+	}
+
+	roles, err := a.roleRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return
+	}
+	roles, err = a.restrictUnverified(ctx, userID, roles)
+	if err != nil {
+		return
+	}
+
+	return domain.Actor{UserID: userID, SessionID: session.ID, Roles: roles}, nil
+}
+
+// restrictUnverified will withhold the author role from users who have not verified their email,
+// when the verification policy asks for it
+func (a *authUsecase) restrictUnverified(ctx context.Context, userID int64, roles []domain.Role) ([]domain.Role, error) {
+	if a.cfg.Verification != domain.VerificationForAuthoring || !(domain.Actor{Roles: roles}).HasRole(domain.RoleAuthor) {
+		return roles, nil
+	}
+
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt != nil {
+		return roles, nil
+	}
+
+	res := make([]domain.Role, 0, len(roles))
+	for _, r := range roles {
+		if r != domain.RoleAuthor {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// ForgotPassword will email a single-use reset link to the user. It succeeds whether the email
+// is registered or not, so callers cannot probe which accounts exist. The email is enqueued
+// rather than delivered, both answers only wait on the database.
+func (a *authUsecase) ForgotPassword(c context.Context, req domain.ForgotPasswordRequest) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = validator.New().Struct(req); err != nil {
+		return domain.ErrBadParamInput
+	}
+
+	user, err := a.userRepo.GetByEmail(ctx, req.Email)
+	if err == domain.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+
+	// the job only names the user, a token in its payload would be readable from the jobs
+	j, err := domain.NewJob(domain.JobSendPasswordReset, domain.PasswordResetJob{UserID: user.ID})
+	if err != nil {
+		return
+	}
+	return a.cfg.Jobs.Enqueue(ctx, j)
+}
+
+// NewSendPasswordResetHandler will create the handler of the domain.JobSendPasswordReset jobs, storing a new
+// reset and emailing its link with cfg.ResetMailer
+func NewSendPasswordResetHandler(userRepo domain.UserRepository, resetRepo domain.PasswordResetRepository, cfg AuthConfig) func(ctx context.Context, p domain.PasswordResetJob) error {
+	a := &authUsecase{userRepo: userRepo, resetRepo: resetRepo, cfg: cfg}
+	return func(ctx context.Context, p domain.PasswordResetJob) error {
+		user, err := a.userRepo.GetByID(ctx, p.UserID)
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// a reset stored by a failed attempt is harmless, its token was never sent
+		token, err := a.newReset(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		return a.cfg.ResetMailer.Send(ctx, domain.Message{
+			To:      user.Email,
+			Subject: "Reset your blogo password",
+			Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. Open the link below to choose a new one, it expires in %s.\n\n%s%s\n\nIf it was not you, you can ignore this email.\n",
+				user.Name, a.cfg.ResetTTL, a.cfg.ResetURL, token),
+		})
+	}
+}
+
+// ResetLink will create a password reset link for the user without emailing it, so that an admin
+// can hand it over. It is restricted to admins.
+func (a *authUsecase) ResetLink(c context.Context, userID int64) (res string, err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionResetLink, userID); err != nil {
+		return
+	}
+
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	token, err := a.newReset(ctx, user.ID)
+	if err != nil {
+		return
+	}
+	auditLogin(ctx, "password_reset.link_created", logrus.Fields{"target_user_id": userID})
+	return a.cfg.ResetURL + token, nil
+}
+
+// newReset will store a new password reset for the user and return its token
+func (a *authUsecase) newReset(ctx context.Context, userID int64) (token string, err error) {
+	token, err = randomToken(32)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	reset := domain.PasswordReset{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.cfg.ResetTTL),
+	}
+	if err = a.resetRepo.Store(ctx, &reset); err != nil {
+		return "", err
+	}
+	return
+}
+
+// ResetPassword will consume the reset token, set the new password and revoke every session of the user
+func (a *authUsecase) ResetPassword(c context.Context, req domain.ResetPasswordRequest) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = validator.New().Struct(req); err != nil {
+		return domain.ErrBadParamInput
+	}
+	if err = isPasswordStrong(req.Password); err != nil {
+		return
+	}
+
+	reset, err := a.resetRepo.GetByTokenHash(ctx, hashToken(req.Token))
+	if err == domain.ErrNotFound {
+		return domain.ErrInvalidToken
+	}
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	if reset.UsedAt != nil || now.After(reset.ExpiresAt) {
+		return domain.ErrInvalidToken
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return
+	}
+	return a.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := a.resetRepo.MarkUsed(ctx, reset.ID, now)
+		if err == domain.ErrNotFound {
+			return domain.ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		current, err := a.userRepo.GetPasswordHash(ctx, reset.UserID)
+		if err != nil {
+			return err
+		}
+		if err = a.historyRepo.Store(ctx, reset.UserID, current, now); err != nil {
+			return err
+		}
+		if err = a.userRepo.UpdatePassword(ctx, reset.UserID, hash, now); err != nil {
+			return err
+		}
+		return a.sessionRepo.RevokeByUserID(ctx, reset.UserID, "")
+	})
+}
+
+// Unlock will clear the failed login counter of the user account
+func (a *authUsecase) Unlock(c context.Context, userID int64) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionUnlock, userID); err != nil {
+		return
+	}
+
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	if err = a.attempts.Reset(ctx, accountKey(user.Email)); err != nil {
+		return
+	}
+	auditLogin(ctx, "account.unlocked", logrus.Fields{"target_user_id": userID})
+	return
+}
+
+func (a *authUsecase) newSession(ctx context.Context, userID int64) (res domain.Token, err error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	session := domain.Session{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.cfg.TokenTTL),
+	}
+	if err = a.sessionRepo.Store(ctx, &session); err != nil {
+		return
+	}
+
+	claims := jwt.RegisteredClaims{
+		ID:        session.ID,
+		Subject:   strconv.FormatInt(userID, 10),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.cfg.Secret)
+	if err != nil {
+		return
+	}
+
+	return domain.Token{AccessToken: signed, TokenType: "Bearer", ExpiresAt: session.ExpiresAt}, nil
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,218 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/user/repository/memory"
+	"github.com/diantanjung/blogo/user-service/user/usecase"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func hashed(t *testing.T, password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	return string(hash)
+}
+
+func TestLoginLockout(t *testing.T) {
+	user := domain.User{ID: 2, Email: "dias@gmail.com", Password: hashed(t, "s3cret-password")}
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockSessionRepo := new(mocks.SessionRepository)
+	mockSessionRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+
+	key := "account:" + user.Email
+	good := domain.Credentials{Email: user.Email, Password: "s3cret-password"}
+	bad := domain.Credentials{Email: user.Email, Password: "wrong-password1"}
+	newUsecase := func(attempts domain.LoginAttemptStore, p usecase.LockoutPolicy) domain.AuthUsecase {
+		p.Window = time.Hour
+		cfg := usecase.AuthConfig{Secret: testSecret, TokenTTL: time.Hour, Lockout: p}
+		return usecase.NewAuthUsecase(mockUserRepo, nil, mockSessionRepo, nil, nil, attempts, nil, cfg, time.Second)
+	}
+	// without a backoff, only reaching the threshold blocks the logins
+	lockout := usecase.LockoutPolicy{AccountThreshold: 3, Lockout: 15 * time.Minute, MaxLockout: time.Hour}
+
+	t.Run("threshold", func(t *testing.T) {
+		u := newUsecase(memory.NewMemoryLoginAttemptStore(), lockout)
+		for i := 0; i < 3; i++ {
+			_, err := u.Login(context.TODO(), bad)
+			require.Equal(t, domain.ErrInvalidCredentials, err, "attempt %d", i)
+		}
+		_, err := u.Login(context.TODO(), good)
+		assert.Equal(t, domain.ErrTooManyAttempts, err, "the right password does not unlock the account")
+	})
+
+	t.Run("backoff growth", func(t *testing.T) {
+		attempts := memory.NewMemoryLoginAttemptStore()
+		u := newUsecase(attempts, usecase.LockoutPolicy{AccountThreshold: 10, Backoff: time.Minute, Lockout: time.Hour})
+
+		// the first failure waits a minute, it was 90 seconds ago
+		_, err := attempts.RegisterFailure(context.TODO(), key, time.Now().Add(-90*time.Second), time.Hour)
+		require.NoError(t, err)
+		_, err = u.Login(context.TODO(), bad)
+		assert.Equal(t, domain.ErrInvalidCredentials, err)
+
+		// the second failure waits two minutes
+		_, err = u.Login(context.TODO(), good)
+		assert.Equal(t, domain.ErrTooManyAttempts, err)
+		a, _ := attempts.Get(context.TODO(), key)
+		assert.Equal(t, int64(2), a.Failures, "blocked attempts are not counted")
+	})
+
+	t.Run("reset on success", func(t *testing.T) {
+		attempts := memory.NewMemoryLoginAttemptStore()
+		u := newUsecase(attempts, lockout)
+		for i := 0; i < 2; i++ {
+			_, err := u.Login(context.TODO(), bad)
+			require.Equal(t, domain.ErrInvalidCredentials, err)
+		}
+
+		token, err := u.Login(context.TODO(), good)
+		require.NoError(t, err)
+		assert.NotEmpty(t, token.AccessToken)
+		a, _ := attempts.Get(context.TODO(), key)
+		assert.Zero(t, a.Failures)
+	})
+
+	t.Run("admin unlock", func(t *testing.T) {
+		u := newUsecase(memory.NewMemoryLoginAttemptStore(), lockout)
+		for i := 0; i < 3; i++ {
+			_, _ = u.Login(context.TODO(), bad)
+		}
+		_, err := u.Login(context.TODO(), good)
+		require.Equal(t, domain.ErrTooManyAttempts, err)
+
+		self := domain.NewContextWithActor(context.TODO(), domain.Actor{UserID: user.ID, Roles: []domain.Role{domain.RoleReader}})
+		assert.Equal(t, domain.ErrForbidden, u.Unlock(self, user.ID))
+		require.NoError(t, u.Unlock(adminContext(), user.ID))
+
+		_, err = u.Login(context.TODO(), good)
+		assert.NoError(t, err)
+	})
+}
+
+func TestLoginUnknownEmail(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByEmail", mock.Anything, "nobody@gmail.com").Return(domain.User{}, domain.ErrNotFound)
+	cfg := usecase.AuthConfig{Secret: testSecret, TokenTTL: time.Hour, Lockout: usecase.LockoutPolicy{Window: time.Hour}}
+	u := usecase.NewAuthUsecase(mockUserRepo, nil, nil, nil, nil, memory.NewMemoryLoginAttemptStore(), nil, cfg, time.Second)
+
+	// the password of the dummy hash compared against for unknown emails does not log in either
+	for _, password := range []string{"wrong-password1", "blogo-dummy-password"} {
+		_, err := u.Login(context.TODO(), domain.Credentials{Email: "nobody@gmail.com", Password: password})
+		assert.Equal(t, domain.ErrInvalidCredentials, err, password)
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByEmail", mock.Anything, "dias@gmail.com").Return(domain.User{ID: 2, Name: "Dias", Email: "dias@gmail.com"}, nil)
+	mockUserRepo.On("GetByEmail", mock.Anything, "nobody@gmail.com").Return(domain.User{}, domain.ErrNotFound)
+	mockJobUCase := new(mocks.JobUsecase)
+	mockJobUCase.On("Enqueue", mock.Anything, mock.MatchedBy(func(j *domain.Job) bool {
+		return j.Type == domain.JobSendPasswordReset
+	})).Return(nil).Once()
+
+	u := usecase.NewAuthUsecase(mockUserRepo, nil, nil, nil, nil, nil, nil, usecase.AuthConfig{Jobs: mockJobUCase}, time.Second)
+
+	t.Run("registered", func(t *testing.T) {
+		err := u.ForgotPassword(context.TODO(), domain.ForgotPasswordRequest{Email: "dias@gmail.com"})
+		assert.NoError(t, err)
+		// the job only names the user, the reset is created when the email is sent
+		j := mockJobUCase.Calls[0].Arguments.Get(1).(*domain.Job)
+		assert.JSONEq(t, `{"user_id":2}`, string(j.Payload))
+		mockJobUCase.AssertExpectations(t)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		err := u.ForgotPassword(context.TODO(), domain.ForgotPasswordRequest{Email: "nobody@gmail.com"})
+		assert.NoError(t, err)
+		mockJobUCase.AssertNumberOfCalls(t, "Enqueue", 1)
+	})
+
+	t.Run("enqueue failed", func(t *testing.T) {
+		mockJobUCase.On("Enqueue", mock.Anything, mock.Anything).Return(domain.ErrInternalServerError).Once()
+
+		err := u.ForgotPassword(context.TODO(), domain.ForgotPasswordRequest{Email: "dias@gmail.com"})
+		assert.Equal(t, domain.ErrInternalServerError, err)
+	})
+}
+
+func TestSendPasswordResetHandler(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByID", mock.Anything, int64(2)).Return(domain.User{ID: 2, Name: "Dias", Email: "dias@gmail.com"}, nil)
+	mockUserRepo.On("GetByID", mock.Anything, int64(3)).Return(domain.User{}, domain.ErrNotFound)
+	var reset *domain.PasswordReset
+	mockResetRepo := new(mocks.PasswordResetRepository)
+	mockResetRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		reset = args.Get(1).(*domain.PasswordReset)
+	}).Return(nil).Once()
+	var msg domain.Message
+	mockMailer := new(mocks.Mailer)
+	mockMailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		msg = args.Get(1).(domain.Message)
+	}).Return(nil).Once()
+
+	cfg := usecase.AuthConfig{ResetMailer: mockMailer, ResetTTL: time.Hour, ResetURL: "https://blogo.dev/reset?token="}
+	handle := usecase.NewSendPasswordResetHandler(mockUserRepo, mockResetRepo, cfg)
+
+	require.NoError(t, handle(context.TODO(), domain.PasswordResetJob{UserID: 2}))
+	require.NotNil(t, reset)
+	assert.Equal(t, "dias@gmail.com", msg.To)
+	assert.Contains(t, msg.Body, cfg.ResetURL)
+	assert.NotContains(t, msg.Body, reset.TokenHash, "only the hash of the token is stored")
+
+	t.Run("deleted user", func(t *testing.T) {
+		assert.NoError(t, handle(context.TODO(), domain.PasswordResetJob{UserID: 3}))
+		mockMailer.AssertNumberOfCalls(t, "Send", 1)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	now := time.Now()
+	mockResetRepo := new(mocks.PasswordResetRepository)
+	mockResetRepo.On("GetByTokenHash", mock.Anything, mock.Anything).Return(domain.PasswordReset{ID: 5, UserID: 2, ExpiresAt: now.Add(time.Hour)}, nil).Once()
+	mockResetRepo.On("MarkUsed", mock.Anything, int64(5), mock.Anything).Return(nil).Once()
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetPasswordHash", mock.Anything, int64(2)).Return("$2a$10$old", nil)
+	mockUserRepo.On("UpdatePassword", mock.Anything, int64(2), mock.Anything, mock.Anything).Return(nil).Once()
+	mockHistoryRepo := new(mocks.PasswordHistoryRepository)
+	mockHistoryRepo.On("Store", mock.Anything, int64(2), "$2a$10$old", mock.Anything).Return(nil).Once()
+	mockSessionRepo := new(mocks.SessionRepository)
+	mockSessionRepo.On("RevokeByUserID", mock.Anything, int64(2), "").Return(nil).Once()
+	mockTx := new(mocks.TxManager)
+	withinTx(mockTx)
+
+	u := usecase.NewAuthUsecase(mockUserRepo, nil, mockSessionRepo, mockResetRepo, mockHistoryRepo, nil, mockTx, usecase.AuthConfig{}, time.Second)
+
+	err := u.ResetPassword(context.TODO(), domain.ResetPasswordRequest{Token: "token", Password: "new-password1"})
+	require.NoError(t, err)
+	// every session is revoked, whoever knew the old password is logged out
+	mockSessionRepo.AssertExpectations(t)
+	mockHistoryRepo.AssertExpectations(t)
+
+	t.Run("used token", func(t *testing.T) {
+		used := now.Add(-time.Minute)
+		mockResetRepo.On("GetByTokenHash", mock.Anything, mock.Anything).Return(domain.PasswordReset{ID: 5, UserID: 2, ExpiresAt: now.Add(time.Hour), UsedAt: &used}, nil).Once()
+		err := u.ResetPassword(context.TODO(), domain.ResetPasswordRequest{Token: "token", Password: "new-password1"})
+		assert.Equal(t, domain.ErrInvalidToken, err)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		mockResetRepo.On("GetByTokenHash", mock.Anything, mock.Anything).Return(domain.PasswordReset{ID: 6, UserID: 2, ExpiresAt: now.Add(-time.Minute)}, nil).Once()
+		err := u.ResetPassword(context.TODO(), domain.ResetPasswordRequest{Token: "token", Password: "new-password1"})
+		assert.Equal(t, domain.ErrInvalidToken, err)
+	})
+	mockSessionRepo.AssertNumberOfCalls(t, "RevokeByUserID", 1)
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+var usecaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "user_usecase_duration_seconds",
+	Help:    "UserUsecase method latency by method and result.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "result"})
+
+func init() {
+	prometheus.MustRegister(usecaseDuration)
+}
+
+type metricsUserUsecase struct {
+	next domain.UserUsecase
+}
+
+// NewMetricsUserUsecase will wrap a domain.UserUsecase and record the timing of every call
+func NewMetricsUserUsecase(next domain.UserUsecase) domain.UserUsecase {
+	return &metricsUserUsecase{next: next}
+}
+
+func observe(method string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	usecaseDuration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+}
+
+func (m *metricsUserUsecase) Fetch(ctx context.Context, cursor string, num int64) (res []domain.User, nextCursor string, err error) {
+	defer func(start time.Time) { observe("Fetch", start, err) }(time.Now())
+	return m.next.Fetch(ctx, cursor, num)
+}
+
+func (m *metricsUserUsecase) GetByID(ctx context.Context, id int64) (res domain.User, err error) {
+	defer func(start time.Time) { observe("GetByID", start, err) }(time.Now())
+	return m.next.GetByID(ctx, id)
+}
+
+func (m *metricsUserUsecase) GetByUsername(ctx context.Context, username string) (res domain.User, err error) {
+	defer func(start time.Time) { observe("GetByUsername", start, err) }(time.Now())
+	return m.next.GetByUsername(ctx, username)
+}
+
+func (m *metricsUserUsecase) GetByIDs(ctx context.Context, ids []int64) (res []domain.User, err error) {
+	defer func(start time.Time) { observe("GetByIDs", start, err) }(time.Now())
+	return m.next.GetByIDs(ctx, ids)
+}
+
+func (m *metricsUserUsecase) Update(ctx context.Context, u *domain.User) (err error) {
+	defer func(start time.Time) { observe("Update", start, err) }(time.Now())
+	return m.next.Update(ctx, u)
+}
+
+func (m *metricsUserUsecase) Store(ctx context.Context, u *domain.User) (err error) {
+	defer func(start time.Time) { observe("Store", start, err) }(time.Now())
+	return m.next.Store(ctx, u)
+}
+
+func (m *metricsUserUsecase) Delete(ctx context.Context, id int64) (err error) {
+	defer func(start time.Time) { observe("Delete", start, err) }(time.Now())
+	return m.next.Delete(ctx, id)
+}
+
+func (m *metricsUserUsecase) Restore(ctx context.Context, id int64) (res domain.User, err error) {
+	defer func(start time.Time) { observe("Restore", start, err) }(time.Now())
+	return m.next.Restore(ctx, id)
+}
+
+func (m *metricsUserUsecase) VerifyEmail(ctx context.Context, token string) (err error) {
+	defer func(start time.Time) { observe("VerifyEmail", start, err) }(time.Now())
+	return m.next.VerifyEmail(ctx, token)
+}
+
+func (m *metricsUserUsecase) ResendVerification(ctx context.Context, req domain.ResendVerificationRequest) (err error) {
+	defer func(start time.Time) { observe("ResendVerification", start, err) }(time.Now())
+	return m.next.ResendVerification(ctx, req)
+}
+
+func (m *metricsUserUsecase) ChangePassword(ctx context.Context, id int64, req domain.ChangePasswordRequest) (err error) {
+	defer func(start time.Time) { observe("ChangePassword", start, err) }(time.Now())
+	return m.next.ChangePassword(ctx, id, req)
+}
+
+func (m *metricsUserUsecase) FetchAudit(ctx context.Context, id int64, cursor string, num int64) (res []domain.AuditEntry, nextCursor string, err error) {
+	defer func(start time.Time) { observe("FetchAudit", start, err) }(time.Now())
+	return m.next.FetchAudit(ctx, id, cursor, num)
+}
+
+func (m *metricsUserUsecase) Import(ctx context.Context, r domain.UserReader, dryRun bool) (res domain.ImportReport, err error) {
+	defer func(start time.Time) { observe("Import", start, err) }(time.Now())
+	return m.next.Import(ctx, r, dryRun)
+}
+
+func (m *metricsUserUsecase) Export(ctx context.Context, fn func(domain.User) error) (err error) {
+	defer func(start time.Time) { observe("Export", start, err) }(time.Now())
+	return m.next.Export(ctx, fn)
+}
+
+func (m *metricsUserUsecase) GetProfile(ctx context.Context, userID int64) (res domain.Profile, err error) {
+	defer func(start time.Time) { observe("GetProfile", start, err) }(time.Now())
+	return m.next.GetProfile(ctx, userID)
+}
+
+func (m *metricsUserUsecase) UpdateProfile(ctx context.Context, p *domain.Profile) (err error) {
+	defer func(start time.Time) { observe("UpdateProfile", start, err) }(time.Now())
+	return m.next.UpdateProfile(ctx, p)
+}
+
+func (m *metricsUserUsecase) UpdateAvatar(ctx context.Context, userID int64, r io.Reader) (res domain.Profile, err error) {
+	defer func(start time.Time) { observe("UpdateAvatar", start, err) }(time.Now())
+	return m.next.UpdateAvatar(ctx, userID, r)
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/tracing"
+)
+
+type tracingUserUsecase struct {
+	next   domain.UserUsecase
+	tracer trace.Tracer
+}
+
+// NewTracingUserUsecase will wrap a domain.UserUsecase and start a span for every call
+func NewTracingUserUsecase(next domain.UserUsecase) domain.UserUsecase {
+	return &tracingUserUsecase{
+		next:   next,
+		tracer: otel.Tracer("github.com/diantanjung/blogo/user-service/user/usecase"),
+	}
+}
+
+func (t *tracingUserUsecase) Fetch(ctx context.Context, cursor string, num int64) (res []domain.User, nextCursor string, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.Fetch", trace.WithAttributes(attribute.Int64("num", num)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Fetch(ctx, cursor, num)
+}
+
+func (t *tracingUserUsecase) GetByID(ctx context.Context, id int64) (res domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.GetByID", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetByID(ctx, id)
+}
+
+func (t *tracingUserUsecase) GetByUsername(ctx context.Context, username string) (res domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.GetByUsername")
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetByUsername(ctx, username)
+}
+
+func (t *tracingUserUsecase) GetByIDs(ctx context.Context, ids []int64) (res []domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.GetByIDs", trace.WithAttributes(attribute.Int("user.count", len(ids))))
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetByIDs(ctx, ids)
+}
+
+func (t *tracingUserUsecase) Update(ctx context.Context, u *domain.User) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.Update", trace.WithAttributes(attribute.Int64("user.id", u.ID)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Update(ctx, u)
+}
+
+func (t *tracingUserUsecase) Store(ctx context.Context, u *domain.User) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.Store")
+	defer func() { tracing.End(span, err) }()
+	return t.next.Store(ctx, u)
+}
+
+func (t *tracingUserUsecase) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.Delete", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Delete(ctx, id)
+}
+
+func (t *tracingUserUsecase) Restore(ctx context.Context, id int64) (res domain.User, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.Restore", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Restore(ctx, id)
+}
+
+func (t *tracingUserUsecase) VerifyEmail(ctx context.Context, token string) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.VerifyEmail")
+	defer func() { tracing.End(span, err) }()
+	return t.next.VerifyEmail(ctx, token)
+}
+
+func (t *tracingUserUsecase) ResendVerification(ctx context.Context, req domain.ResendVerificationRequest) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.ResendVerification")
+	defer func() { tracing.End(span, err) }()
+	return t.next.ResendVerification(ctx, req)
+}
+
+func (t *tracingUserUsecase) ChangePassword(ctx context.Context, id int64, req domain.ChangePasswordRequest) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.ChangePassword", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.ChangePassword(ctx, id, req)
+}
+
+func (t *tracingUserUsecase) FetchAudit(ctx context.Context, id int64, cursor string, num int64) (res []domain.AuditEntry, nextCursor string, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.FetchAudit", trace.WithAttributes(attribute.Int64("user.id", id)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.FetchAudit(ctx, id, cursor, num)
+}
+
+func (t *tracingUserUsecase) Import(ctx context.Context, r domain.UserReader, dryRun bool) (res domain.ImportReport, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.Import", trace.WithAttributes(attribute.Bool("import.dry_run", dryRun)))
+	defer func() {
+		span.SetAttributes(attribute.Int("import.total", res.Total), attribute.Int("import.imported", res.Imported))
+		tracing.End(span, err)
+	}()
+	return t.next.Import(ctx, r, dryRun)
+}
+
+func (t *tracingUserUsecase) Export(ctx context.Context, fn func(domain.User) error) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.Export")
+	defer func() { tracing.End(span, err) }()
+	return t.next.Export(ctx, fn)
+}
+
+func (t *tracingUserUsecase) GetProfile(ctx context.Context, userID int64) (res domain.Profile, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.GetProfile", trace.WithAttributes(attribute.Int64("user.id", userID)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetProfile(ctx, userID)
+}
+
+func (t *tracingUserUsecase) UpdateProfile(ctx context.Context, p *domain.Profile) (err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.UpdateProfile", trace.WithAttributes(attribute.Int64("user.id", p.UserID)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.UpdateProfile(ctx, p)
+}
+
+func (t *tracingUserUsecase) UpdateAvatar(ctx context.Context, userID int64, r io.Reader) (res domain.Profile, err error) {
+	ctx, span := t.tracer.Start(ctx, "UserUsecase.UpdateAvatar", trace.WithAttributes(attribute.Int64("user.id", userID)))
+	defer func() { tracing.End(span, err) }()
+	return t.next.UpdateAvatar(ctx, userID, r)
+}
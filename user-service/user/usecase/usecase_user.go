@@ -2,21 +2,52 @@ package usecase
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
 	validator "gopkg.in/go-playground/validator.v9"
 )
 
+// UserConfig configures the userUsecase
+type UserConfig struct {
+	Verification EmailVerification
+	// PasswordHistory is the number of previous passwords a user may not reuse
+	PasswordHistory int64
+	// ImportBatch is the number of users imported per transaction, and exported per page
+	ImportBatch int64
+	Avatar      AvatarConfig
+}
+
 type userUsecase struct {
-	userRepo domain.UserRepository
+	userRepo       domain.UserRepository
+	roleRepo       domain.RoleRepository
+	profileRepo    domain.ProfileRepository
+	sessionRepo    domain.SessionRepository
+	historyRepo    domain.PasswordHistoryRepository
+	auditRepo      domain.AuditRepository
+	outboxRepo     domain.OutboxRepository
+	txManager      domain.TxManager
+	cfg            UserConfig
 	contextTimeout time.Duration
 }
 
-// NewArticleUsecase will create new an userUsecase object representation of domain.userUsecase interface
-func NewUserUsecase(a domain.UserRepository) domain.UserUsecase {
+// NewUserUsecase will create new an userUsecase object representation of domain.UserUsecase interface
+func NewUserUsecase(a domain.UserRepository, r domain.RoleRepository, p domain.ProfileRepository, s domain.SessionRepository, h domain.PasswordHistoryRepository, au domain.AuditRepository, ob domain.OutboxRepository, tx domain.TxManager, cfg UserConfig, timeout time.Duration) domain.UserUsecase {
 	return &userUsecase{
-		userRepo: a,
+		userRepo:       a,
+		roleRepo:       r,
+		profileRepo:    p,
+		sessionRepo:    s,
+		historyRepo:    h,
+		auditRepo:      au,
+		outboxRepo:     ob,
+		txManager:      tx,
+		cfg:            cfg,
+		contextTimeout: timeout,
 	}
 }
 func (a *userUsecase) Fetch(c context.Context, cursor string, num int64) (res []domain.User, nextCursor string, err error) {
@@ -31,10 +62,24 @@ func (a *userUsecase) Fetch(c context.Context, cursor string, num int64) (res []
 	if err != nil {
 		return nil, "", err
 	}
+
+	ids := make([]int64, len(res))
+	for i := range res {
+		ids[i] = res[i].ID
+	}
+	roles, err := a.roleRepo.GetByUserIDs(ctx, ids)
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range res {
+		res[i].Roles = roles[res[i].ID]
+		redact(ctx, &res[i])
+	}
 	return
 }
 
 func (a *userUsecase) GetByID(c context.Context, id int64) (res domain.User, err error) {
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": id})
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
 
@@ -42,43 +87,385 @@ func (a *userUsecase) GetByID(c context.Context, id int64) (res domain.User, err
 	if err != nil {
 		return
 	}
+	res.Roles, err = a.roleRepo.GetByUserID(ctx, id)
+	if err != nil {
+		return domain.User{}, err
+	}
+	redact(ctx, &res)
 	return
 }
 
-func (a *userUsecase) Update(c context.Context, u *domain.User) (err error) {
+// GetByUsername will find the user by its current or former username, the caller compares the
+// username of the result to tell a rename
+func (a *userUsecase) GetByUsername(c context.Context, username string) (res domain.User, err error) {
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
 
-	u.UpdatedAt = time.Now()
+	res, err = a.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return
+	}
+	res.Roles, err = a.roleRepo.GetByUserID(ctx, res.ID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	redact(ctx, &res)
+	return
+}
 
-	if err = isUserValid(u); err != nil {
+// maxBatch is the number of users GetByIDs may look up at once
+const maxBatch = 100
+
+func (a *userUsecase) GetByIDs(c context.Context, ids []int64) (res []domain.User, err error) {
+	if len(ids) > maxBatch {
+		return nil, domain.ErrBadParamInput
+	}
+	if len(ids) == 0 {
+		return []domain.User{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	res, err = a.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := a.roleRepo.GetByUserIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res {
+		res[i].Roles = roles[res[i].ID]
+		redact(ctx, &res[i])
+	}
+	return
+}
+
+func (a *userUsecase) Update(c context.Context, u *domain.User) (err error) {
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": u.ID})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionUpdate, u.ID); err != nil {
 		return
 	}
-	return a.userRepo.Update(ctx, u)
+
+	err = a.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existedUser, err := a.userRepo.GetByID(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+		existedUser.Roles, err = a.roleRepo.GetByUserID(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+
+		rolesChanged := u.Roles != nil && !sameRoles(u.Roles, existedUser.Roles)
+		if rolesChanged {
+			if err = authorize(ctx, actionAssignRoles, u.ID); err != nil {
+				return err
+			}
+		} else {
+			u.Roles = existedUser.Roles
+		}
+
+		// a change of case only keeps resolving the same way, it is not a rename
+		renamed := !strings.EqualFold(u.Username, existedUser.Username)
+		if u.Email != existedUser.Email {
+			u.EmailVerifiedAt = nil
+		} else {
+			u.EmailVerifiedAt = existedUser.EmailVerifiedAt
+		}
+		u.CreatedAt = existedUser.CreatedAt
+		u.UpdatedAt = time.Now()
+
+		// the password is changed through ChangePassword, which checks the current one
+		u.Password = ""
+		if err = validator.New().StructExcept(u, "Password"); err != nil {
+			return err
+		}
+		if renamed {
+			if err = a.usernameAvailable(ctx, u); err != nil {
+				return err
+			}
+		}
+		if err = a.userRepo.Update(ctx, u); err != nil {
+			return err
+		}
+		if renamed {
+			if err = a.userRepo.StoreFormerUsername(ctx, u.ID, existedUser.Username, u.UpdatedAt); err != nil {
+				return err
+			}
+		}
+		if rolesChanged {
+			if err = a.roleRepo.Store(ctx, u.ID, u.Roles); err != nil {
+				return err
+			}
+		}
+		if err = a.record(ctx, domain.AuditUserUpdated, domain.EventUserUpdated, &existedUser, u); err != nil {
+			return err
+		}
+		if u.Email != existedUser.Email {
+			return a.cfg.Verification.send(ctx, u)
+		}
+		return nil
+	})
+	return
 }
-func (a *userUsecase) Store(c context.Context,u *domain.User) (err error) {
+func (a *userUsecase) Store(c context.Context, u *domain.User) (err error) {
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
 
+	if len(u.Roles) == 0 || authorize(ctx, actionAssignRoles, 0) != nil {
+		u.Roles = []domain.Role{domain.RoleReader}
+	}
+	now := time.Now()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+	u.EmailVerifiedAt = nil
+
 	if err = isUserValid(u); err != nil {
 		return
 	}
-	return a.userRepo.Store(ctx, u)
+	if err = isPasswordStrong(u.Password); err != nil {
+		return
+	}
+	if u.Password, err = hashPassword(u.Password); err != nil {
+		return
+	}
+
+	err = a.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := a.usernameAvailable(ctx, u); err != nil {
+			return err
+		}
+		if err := a.userRepo.Store(ctx, u); err != nil {
+			return err
+		}
+		if err := a.roleRepo.Store(ctx, u.ID, u.Roles); err != nil {
+			return err
+		}
+		if err := a.record(ctx, domain.AuditUserCreated, domain.EventUserCreated, nil, u); err != nil {
+			return err
+		}
+		return a.cfg.Verification.send(ctx, u)
+	})
+	if err != nil {
+		return
+	}
+
+	u.Password = ""
+	return
 }
 func (a *userUsecase) Delete(c context.Context, id int64) (err error) {
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": id})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionDelete, id); err != nil {
+		return
+	}
+
+	return a.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existedUser, err := a.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existedUser.ID == 0 {
+			return domain.ErrNotFound
+		}
+		if existedUser.Roles, err = a.roleRepo.GetByUserID(ctx, id); err != nil {
+			return err
+		}
+		if err = a.userRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err = a.sessionRepo.RevokeByUserID(ctx, id, ""); err != nil {
+			return err
+		}
+		return a.record(ctx, domain.AuditUserDeleted, domain.EventUserDeleted, &existedUser, nil)
+	})
+}
+
+// Restore will bring back a deleted user. Its sessions stay revoked, so the user has to log in again.
+func (a *userUsecase) Restore(c context.Context, id int64) (res domain.User, err error) {
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": id})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionRestore, id); err != nil {
+		return
+	}
+
+	err = a.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := a.userRepo.Restore(ctx, id, time.Now()); err != nil {
+			return err
+		}
+		restored, err := a.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if restored.Roles, err = a.roleRepo.GetByUserID(ctx, id); err != nil {
+			return err
+		}
+		res = restored
+		return a.record(ctx, domain.AuditUserRestored, domain.EventUserRestored, nil, &restored)
+	})
+	return
+}
+
+func (a *userUsecase) VerifyEmail(c context.Context, token string) (err error) {
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
-	existedArticle, err := a.userRepo.GetByID(ctx, id)
+
+	id, email, err := a.cfg.Verification.parseToken(token)
 	if err != nil {
 		return
 	}
-	if existedArticle == (domain.User{}) {
-		return domain.ErrNotFound
+
+	err = a.userRepo.MarkEmailVerified(ctx, id, email, time.Now())
+	if err == domain.ErrNotFound {
+		return domain.ErrInvalidToken
 	}
-	return a.userRepo.Delete(ctx, id)
+	return
+}
+
+// ResendVerification will enqueue another verification email when the email belongs to an unverified user.
+// Unknown and verified emails succeed too, so callers cannot probe which accounts exist.
+func (a *userUsecase) ResendVerification(c context.Context, req domain.ResendVerificationRequest) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = validator.New().Struct(req); err != nil {
+		return domain.ErrBadParamInput
+	}
+
+	u, err := a.userRepo.GetByEmail(ctx, req.Email)
+	if err == domain.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	if u.EmailVerifiedAt != nil {
+		return nil
+	}
+	return a.cfg.Verification.send(ctx, &u)
+}
+
+// ChangePassword will set a new password after checking the current one, the strength policy and the
+// recent passwords of the user, then revoke every other session of the user
+func (a *userUsecase) ChangePassword(c context.Context, id int64, req domain.ChangePasswordRequest) (err error) {
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": id})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionChangePassword, id); err != nil {
+		return
+	}
+	if err = validator.New().Struct(req); err != nil {
+		return domain.ErrBadParamInput
+	}
+
+	current, err := a.userRepo.GetPasswordHash(ctx, id)
+	if err != nil {
+		return
+	}
+	if !checkPassword(current, req.CurrentPassword) {
+		return domain.ErrPasswordMismatch
+	}
+	if err = isPasswordStrong(req.NewPassword); err != nil {
+		return
+	}
+
+	recent, err := a.historyRepo.GetRecent(ctx, id, a.cfg.PasswordHistory)
+	if err != nil {
+		return
+	}
+	for _, hash := range append([]string{current}, recent...) {
+		if checkPassword(hash, req.NewPassword) {
+			return domain.ErrPasswordReused
+		}
+	}
+
+	hash, err := hashPassword(req.NewPassword)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	actor, _ := domain.ActorFromContext(ctx)
+	return a.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := a.historyRepo.Store(ctx, id, current, now); err != nil {
+			return err
+		}
+		if err := a.userRepo.UpdatePassword(ctx, id, hash, now); err != nil {
+			return err
+		}
+		return a.sessionRepo.RevokeByUserID(ctx, id, actor.SessionID)
+	})
+}
+
+// FetchAudit will return the audit log of the user, it is restricted to admins
+func (a *userUsecase) FetchAudit(c context.Context, id int64, cursor string, num int64) (res []domain.AuditEntry, nextCursor string, err error) {
+	if num == 0 {
+		num = 10
+	}
+
+	c = logging.WithFields(c, logrus.Fields{"target_user_id": id})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = authorize(ctx, actionViewAudit, id); err != nil {
+		return
+	}
+	return a.auditRepo.FetchByTargetUserID(ctx, id, cursor, num)
 }
 
 func isUserValid(m *domain.User) error {
 	validate := validator.New()
 	return validate.Struct(m)
-}
\ No newline at end of file
+}
+
+// usernameAvailable returns domain.ErrConflict when the username of u belongs to another user,
+// ignoring case. Former usernames stay reserved to their user so that they keep redirecting to it.
+func (a *userUsecase) usernameAvailable(ctx context.Context, u *domain.User) error {
+	taken, _, err := a.userRepo.Taken(ctx, []string{u.Username}, nil)
+	if err != nil {
+		return err
+	}
+	if !taken[u.Username] {
+		return nil
+	}
+	// users may take one of their former usernames back
+	if u.ID != 0 {
+		owner, err := a.userRepo.GetByUsername(ctx, u.Username)
+		if err == nil && owner.ID == u.ID {
+			return nil
+		}
+	}
+	return domain.ErrConflict
+}
+
+// redact will hide the fields of u the actor in ctx is not allowed to see
+func redact(ctx context.Context, u *domain.User) {
+	u.Password = ""
+	if authorize(ctx, actionViewEmail, u.ID) != nil {
+		u.Email = ""
+	}
+}
+
+func sameRoles(a, b []domain.Role) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[domain.Role]bool, len(a))
+	for _, r := range a {
+		set[r] = true
+	}
+	for _, r := range b {
+		if !set[r] {
+			return false
+		}
+	}
+	return true
+}
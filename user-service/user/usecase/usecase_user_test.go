@@ -0,0 +1,253 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/user/usecase"
+)
+
+func readerContext(id int64) context.Context {
+	return domain.NewContextWithActor(context.TODO(), domain.Actor{UserID: id, Roles: []domain.Role{domain.RoleReader}})
+}
+
+func TestGetByIDRedacts(t *testing.T) {
+	stored := domain.User{ID: 2, Username: "dias", Name: "Dias", Email: "dias@gmail.com", Password: "$2a$10$hash"}
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByID", mock.Anything, int64(2)).Return(stored, nil)
+	mockRoleRepo := new(mocks.RoleRepository)
+	mockRoleRepo.On("GetByUserID", mock.Anything, int64(2)).Return([]domain.Role{domain.RoleReader}, nil)
+
+	u := usecase.NewUserUsecase(mockUserRepo, mockRoleRepo, nil, nil, nil, nil, nil, nil, usecase.UserConfig{}, time.Second)
+
+	cases := []struct {
+		name  string
+		ctx   context.Context
+		email string
+	}{
+		{name: "anonymous", ctx: context.TODO(), email: ""},
+		{name: "other user", ctx: readerContext(3), email: ""},
+		{name: "self", ctx: readerContext(2), email: "dias@gmail.com"},
+		{name: "admin", ctx: adminContext(), email: "dias@gmail.com"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := u.GetByID(tc.ctx, 2)
+			require.NoError(t, err)
+			assert.Equal(t, tc.email, res.Email)
+			assert.Empty(t, res.Password, "the password hash is never returned")
+			assert.Equal(t, "dias", res.Username)
+		})
+	}
+}
+
+func TestUpdateAuthorization(t *testing.T) {
+	existed := domain.User{ID: 2, Username: "dias", Name: "Dias", Email: "dias@gmail.com"}
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByID", mock.Anything, int64(2)).Return(existed, nil)
+	mockUserRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockRoleRepo := new(mocks.RoleRepository)
+	mockRoleRepo.On("GetByUserID", mock.Anything, int64(2)).Return([]domain.Role{domain.RoleReader}, nil)
+	mockRoleRepo.On("Store", mock.Anything, int64(2), mock.Anything).Return(nil)
+	mockOutboxRepo := new(mocks.OutboxRepository)
+	mockOutboxRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	mockTx := new(mocks.TxManager)
+	withinTx(mockTx)
+
+	u := usecase.NewUserUsecase(mockUserRepo, mockRoleRepo, nil, nil, nil, mockAuditRepo, mockOutboxRepo, mockTx, usecase.UserConfig{}, time.Second)
+
+	cases := []struct {
+		name  string
+		ctx   context.Context
+		roles []domain.Role
+		err   error
+	}{
+		{name: "anonymous", ctx: context.TODO(), err: domain.ErrUnauthorized},
+		{name: "other user", ctx: readerContext(3), err: domain.ErrForbidden},
+		{name: "self", ctx: readerContext(2)},
+		{name: "self assigning roles", ctx: readerContext(2), roles: []domain.Role{domain.RoleAdmin}, err: domain.ErrForbidden},
+		{name: "admin", ctx: adminContext()},
+		{name: "admin assigning roles", ctx: adminContext(), roles: []domain.Role{domain.RoleAuthor}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := existed
+			user.Name = "Dias Tanjung"
+			user.Roles = tc.roles
+			err := u.Update(tc.ctx, &user)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+	mockUserRepo.AssertNumberOfCalls(t, "Update", 3)
+	mockRoleRepo.AssertNumberOfCalls(t, "Store", 1)
+}
+
+func TestDeleteAuthorization(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByID", mock.Anything, int64(2)).Return(domain.User{ID: 2, Username: "dias"}, nil)
+	mockUserRepo.On("Delete", mock.Anything, int64(2)).Return(nil)
+	mockRoleRepo := new(mocks.RoleRepository)
+	mockRoleRepo.On("GetByUserID", mock.Anything, int64(2)).Return([]domain.Role{domain.RoleReader}, nil)
+	mockSessionRepo := new(mocks.SessionRepository)
+	mockSessionRepo.On("RevokeByUserID", mock.Anything, int64(2), "").Return(nil)
+	mockOutboxRepo := new(mocks.OutboxRepository)
+	mockOutboxRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	mockTx := new(mocks.TxManager)
+	withinTx(mockTx)
+
+	u := usecase.NewUserUsecase(mockUserRepo, mockRoleRepo, nil, mockSessionRepo, nil, mockAuditRepo, mockOutboxRepo, mockTx, usecase.UserConfig{}, time.Second)
+
+	assert.Equal(t, domain.ErrUnauthorized, u.Delete(context.TODO(), 2))
+	assert.Equal(t, domain.ErrForbidden, u.Delete(readerContext(3), 2))
+	mockUserRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	assert.NoError(t, u.Delete(readerContext(2), 2))
+	assert.NoError(t, u.Delete(adminContext(), 2))
+	mockUserRepo.AssertNumberOfCalls(t, "Delete", 2)
+
+	// restoring is kept to admins, even for the deleted user
+	_, err := u.Restore(readerContext(2), 2)
+	assert.Equal(t, domain.ErrForbidden, err)
+}
+
+func TestChangePassword(t *testing.T) {
+	current := hashed(t, "current-password1")
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetPasswordHash", mock.Anything, int64(2)).Return(current, nil)
+	mockUserRepo.On("UpdatePassword", mock.Anything, int64(2), mock.Anything, mock.Anything).Return(nil)
+	mockHistoryRepo := new(mocks.PasswordHistoryRepository)
+	mockHistoryRepo.On("GetRecent", mock.Anything, int64(2), int64(3)).Return([]string{hashed(t, "older-password1")}, nil)
+	mockHistoryRepo.On("Store", mock.Anything, int64(2), current, mock.Anything).Return(nil)
+	mockSessionRepo := new(mocks.SessionRepository)
+	mockSessionRepo.On("RevokeByUserID", mock.Anything, int64(2), "s1").Return(nil)
+	mockTx := new(mocks.TxManager)
+	withinTx(mockTx)
+
+	u := usecase.NewUserUsecase(mockUserRepo, nil, nil, mockSessionRepo, mockHistoryRepo, nil, nil, mockTx, usecase.UserConfig{PasswordHistory: 3}, time.Second)
+	ctx := domain.NewContextWithActor(context.TODO(), domain.Actor{UserID: 2, SessionID: "s1", Roles: []domain.Role{domain.RoleReader}})
+
+	cases := []struct {
+		name string
+		req  domain.ChangePasswordRequest
+		err  error
+	}{
+		{name: "wrong current password", req: domain.ChangePasswordRequest{CurrentPassword: "wrong-password1", NewPassword: "new-password1"}, err: domain.ErrPasswordMismatch},
+		{name: "weak", req: domain.ChangePasswordRequest{CurrentPassword: "current-password1", NewPassword: "short"}, err: domain.ErrWeakPassword},
+		{name: "current reused", req: domain.ChangePasswordRequest{CurrentPassword: "current-password1", NewPassword: "current-password1"}, err: domain.ErrPasswordReused},
+		{name: "history reused", req: domain.ChangePasswordRequest{CurrentPassword: "current-password1", NewPassword: "older-password1"}, err: domain.ErrPasswordReused},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.err, u.ChangePassword(ctx, 2, tc.req))
+		})
+	}
+	mockUserRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	err := u.ChangePassword(ctx, 2, domain.ChangePasswordRequest{CurrentPassword: "current-password1", NewPassword: "new-password1"})
+	require.NoError(t, err)
+	// the old hash joins the history, the other sessions are revoked and the current one is kept
+	mockHistoryRepo.AssertCalled(t, "Store", mock.Anything, int64(2), current, mock.Anything)
+	mockSessionRepo.AssertCalled(t, "RevokeByUserID", mock.Anything, int64(2), "s1")
+
+	assert.Equal(t, domain.ErrForbidden, u.ChangePassword(readerContext(3), 2, domain.ChangePasswordRequest{CurrentPassword: "current-password1", NewPassword: "new-password1"}))
+}
+
+func TestRename(t *testing.T) {
+	existed := domain.User{ID: 2, Username: "dias", Name: "Dias", Email: "dias@gmail.com"}
+	newUsecase := func(mockUserRepo *mocks.UserRepository) domain.UserUsecase {
+		mockUserRepo.On("GetByID", mock.Anything, int64(2)).Return(existed, nil)
+		mockUserRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		mockRoleRepo := new(mocks.RoleRepository)
+		mockRoleRepo.On("GetByUserID", mock.Anything, int64(2)).Return([]domain.Role{domain.RoleReader}, nil)
+		mockOutboxRepo := new(mocks.OutboxRepository)
+		mockOutboxRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+		mockAuditRepo := new(mocks.AuditRepository)
+		mockAuditRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+		mockTx := new(mocks.TxManager)
+		withinTx(mockTx)
+		return usecase.NewUserUsecase(mockUserRepo, mockRoleRepo, nil, nil, nil, mockAuditRepo, mockOutboxRepo, mockTx, usecase.UserConfig{}, time.Second)
+	}
+	rename := func(u domain.UserUsecase, username string) error {
+		user := existed
+		user.Username = username
+		return u.Update(readerContext(2), &user)
+	}
+
+	t.Run("free username", func(t *testing.T) {
+		mockUserRepo := new(mocks.UserRepository)
+		mockUserRepo.On("Taken", mock.Anything, []string{"dias_t"}, mock.Anything).Return(map[string]bool{}, map[string]bool{}, nil)
+		mockUserRepo.On("StoreFormerUsername", mock.Anything, int64(2), "dias", mock.Anything).Return(nil).Once()
+
+		require.NoError(t, rename(newUsecase(mockUserRepo), "dias_t"))
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("change of case", func(t *testing.T) {
+		mockUserRepo := new(mocks.UserRepository)
+
+		require.NoError(t, rename(newUsecase(mockUserRepo), "Dias"))
+		mockUserRepo.AssertNotCalled(t, "Taken", mock.Anything, mock.Anything, mock.Anything)
+		mockUserRepo.AssertNotCalled(t, "StoreFormerUsername", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("username of another user", func(t *testing.T) {
+		mockUserRepo := new(mocks.UserRepository)
+		mockUserRepo.On("Taken", mock.Anything, []string{"tanjung"}, mock.Anything).Return(map[string]bool{"tanjung": true}, map[string]bool{}, nil)
+		// the current or former username of user 3
+		mockUserRepo.On("GetByUsername", mock.Anything, "tanjung").Return(domain.User{ID: 3, Username: "tanjung"}, nil)
+
+		assert.Equal(t, domain.ErrConflict, rename(newUsecase(mockUserRepo), "tanjung"))
+		mockUserRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("former username taken back", func(t *testing.T) {
+		mockUserRepo := new(mocks.UserRepository)
+		mockUserRepo.On("Taken", mock.Anything, []string{"dias_old"}, mock.Anything).Return(map[string]bool{"dias_old": true}, map[string]bool{}, nil)
+		mockUserRepo.On("GetByUsername", mock.Anything, "dias_old").Return(domain.User{ID: 2, Username: "dias"}, nil)
+		mockUserRepo.On("StoreFormerUsername", mock.Anything, int64(2), "dias", mock.Anything).Return(nil).Once()
+
+		require.NoError(t, rename(newUsecase(mockUserRepo), "dias_old"))
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("sign up with a former username", func(t *testing.T) {
+		mockUserRepo := new(mocks.UserRepository)
+		mockUserRepo.On("Taken", mock.Anything, []string{"dias_old"}, mock.Anything).Return(map[string]bool{"dias_old": true}, map[string]bool{}, nil)
+		u := newUsecase(mockUserRepo)
+
+		err := u.Store(context.TODO(), &domain.User{Username: "dias_old", Name: "Other", Email: "other@gmail.com", Password: "s3cret-password"})
+		assert.Equal(t, domain.ErrConflict, err)
+		mockUserRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetByUsername(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepository)
+	// the repository resolves former usernames to the user with its current one
+	mockUserRepo.On("GetByUsername", mock.Anything, "dias_old").Return(domain.User{ID: 2, Username: "dias", Email: "dias@gmail.com"}, nil)
+	mockUserRepo.On("GetByUsername", mock.Anything, "nobody").Return(domain.User{}, domain.ErrNotFound)
+	mockRoleRepo := new(mocks.RoleRepository)
+	mockRoleRepo.On("GetByUserID", mock.Anything, int64(2)).Return([]domain.Role{domain.RoleAuthor}, nil)
+
+	u := usecase.NewUserUsecase(mockUserRepo, mockRoleRepo, nil, nil, nil, nil, nil, nil, usecase.UserConfig{}, time.Second)
+
+	res, err := u.GetByUsername(context.TODO(), "dias_old")
+	require.NoError(t, err)
+	assert.Equal(t, "dias", res.Username)
+	assert.Equal(t, []domain.Role{domain.RoleAuthor}, res.Roles)
+	assert.Empty(t, res.Email)
+
+	_, err = u.GetByUsername(context.TODO(), "nobody")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+)
+
+const verificationAudience = "email-verification"
+
+// EmailVerification configures the verification email sent when a user signs up or changes email
+type EmailVerification struct {
+	// Jobs enqueues the emails, the link is only written when the job worker sends them with Mailer
+	Jobs   domain.JobUsecase
+	Mailer domain.Mailer
+	Secret []byte
+	TTL    time.Duration
+	// URL is the page the token is appended to, e.g. https://blogo.dev/verify?token=
+	URL string
+}
+
+type verificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+func (v EmailVerification) newToken(u *domain.User) (string, error) {
+	now := time.Now()
+	claims := verificationClaims{
+		Email: u.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(u.ID, 10),
+			Audience:  jwt.ClaimStrings{verificationAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(v.TTL)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(v.Secret)
+}
+
+func (v EmailVerification) parseToken(token string) (id int64, email string, err error) {
+	claims := verificationClaims{}
+	_, err = jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, domain.ErrInvalidToken
+		}
+		return v.Secret, nil
+	})
+	if err != nil || !claims.VerifyAudience(verificationAudience, true) {
+		return 0, "", domain.ErrInvalidToken
+	}
+
+	id, err = strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, "", domain.ErrInvalidToken
+	}
+	return id, claims.Email, nil
+}
+
+// send will enqueue the verification email of u in the transaction of ctx, so the email is only sent
+// when the change asking for it commits. The job holds no token, job payloads are kept and shown to admins.
+func (v EmailVerification) send(ctx context.Context, u *domain.User) error {
+	if v.Jobs == nil {
+		return nil
+	}
+	j, err := domain.NewJob(domain.JobSendVerification, domain.VerificationJob{UserID: u.ID, Email: u.Email})
+	if err != nil {
+		return err
+	}
+	return v.Jobs.Enqueue(ctx, j)
+}
+
+// deliver will email a verification link to u
+func (v EmailVerification) deliver(ctx context.Context, u *domain.User) error {
+	token, err := v.newToken(u)
+	if err != nil {
+		return err
+	}
+
+	return v.Mailer.Send(ctx, domain.Message{
+		To:      u.Email,
+		Subject: "Verify your blogo account",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease verify your email address by opening the link below, it expires in %s.\n\n%s%s\n",
+			u.Name, v.TTL, v.URL, token),
+	})
+}
+
+// NewSendVerificationHandler will create the handler of the domain.JobSendVerification jobs. Nothing is sent
+// when the user is gone, already verified, or changed email since the job was enqueued.
+func NewSendVerificationHandler(userRepo domain.UserRepository, v EmailVerification) func(ctx context.Context, p domain.VerificationJob) error {
+	return func(ctx context.Context, p domain.VerificationJob) error {
+		u, err := userRepo.GetByID(ctx, p.UserID)
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if u.Email != p.Email || u.EmailVerifiedAt != nil {
+			return nil
+		}
+		return v.deliver(ctx, &u)
+	}
+}
@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/user/usecase"
+)
+
+func TestSendVerificationHandler(t *testing.T) {
+	verified := time.Now()
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByID", mock.Anything, int64(2)).Return(domain.User{ID: 2, Name: "Dias", Email: "dias@gmail.com"}, nil)
+	mockUserRepo.On("GetByID", mock.Anything, int64(3)).Return(domain.User{ID: 3, Email: "tanjung@gmail.com", EmailVerifiedAt: &verified}, nil)
+	mockUserRepo.On("GetByID", mock.Anything, int64(4)).Return(domain.User{}, domain.ErrNotFound)
+	mockMailer := new(mocks.Mailer)
+	mockMailer.On("Send", mock.Anything, mock.MatchedBy(func(msg domain.Message) bool {
+		return msg.To == "dias@gmail.com"
+	})).Return(nil).Once()
+
+	v := usecase.EmailVerification{Mailer: mockMailer, Secret: testSecret, TTL: time.Hour, URL: "https://blogo.dev/verify?token="}
+	handle := usecase.NewSendVerificationHandler(mockUserRepo, v)
+
+	cases := []struct {
+		name string
+		job  domain.VerificationJob
+	}{
+		{name: "unverified", job: domain.VerificationJob{UserID: 2, Email: "dias@gmail.com"}},
+		{name: "email changed since", job: domain.VerificationJob{UserID: 2, Email: "old@gmail.com"}},
+		{name: "already verified", job: domain.VerificationJob{UserID: 3, Email: "tanjung@gmail.com"}},
+		{name: "deleted user", job: domain.VerificationJob{UserID: 4, Email: "gone@gmail.com"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, handle(context.TODO(), tc.job))
+		})
+	}
+	// only the current, unverified email gets a link
+	mockMailer.AssertExpectations(t)
+	mockMailer.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestResendVerification(t *testing.T) {
+	verified := time.Now()
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("GetByEmail", mock.Anything, "dias@gmail.com").Return(domain.User{ID: 2, Email: "dias@gmail.com"}, nil)
+	mockUserRepo.On("GetByEmail", mock.Anything, "tanjung@gmail.com").Return(domain.User{ID: 3, Email: "tanjung@gmail.com", EmailVerifiedAt: &verified}, nil)
+	mockUserRepo.On("GetByEmail", mock.Anything, "nobody@gmail.com").Return(domain.User{}, domain.ErrNotFound)
+	mockJobs := new(mocks.JobUsecase)
+	mockJobs.On("Enqueue", mock.Anything, mock.MatchedBy(func(j *domain.Job) bool {
+		return j.Type == domain.JobSendVerification
+	})).Return(nil).Once()
+
+	cfg := usecase.UserConfig{Verification: usecase.EmailVerification{Jobs: mockJobs}}
+	u := usecase.NewUserUsecase(mockUserRepo, nil, nil, nil, nil, nil, nil, nil, cfg, time.Second)
+
+	for _, email := range []string{"dias@gmail.com", "tanjung@gmail.com", "nobody@gmail.com"} {
+		t.Run(email, func(t *testing.T) {
+			assert.NoError(t, u.ResendVerification(context.TODO(), domain.ResendVerificationRequest{Email: email}))
+		})
+	}
+	assert.Equal(t, domain.ErrBadParamInput, u.ResendVerification(context.TODO(), domain.ResendVerificationRequest{Email: "dias"}))
+	// only the unverified user gets another link
+	mockJobs.AssertExpectations(t)
+	mockJobs.AssertNumberOfCalls(t, "Enqueue", 1)
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/user/delivery/http/middleware"
+)
+
+// ResponseError represent the reseponse error struct
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+// WebhookHandler represent the httphandler for webhooks
+type WebhookHandler struct {
+	WebhookUsecase domain.WebhookUsecase
+}
+
+// NewWebhooksHandler will initialize the webhooks/ resources endpoint
+func NewWebhooksHandler(e *echo.Echo, wu domain.WebhookUsecase) {
+	handler := &WebhookHandler{
+		WebhookUsecase: wu,
+	}
+	e.GET("/webhooks", handler.Fetch)
+	e.POST("/webhooks", handler.Store)
+	e.GET("/webhooks/:id", handler.GetByID)
+	e.PATCH("/webhooks/:id", handler.Update)
+	e.DELETE("/webhooks/:id", handler.Delete)
+	e.GET("/webhooks/:id/deliveries", handler.FetchDeliveries)
+	e.POST("/webhooks/:id/deliveries/:deliveryID/replay", handler.Replay)
+}
+
+func (a *WebhookHandler) Fetch(c echo.Context) error {
+	num, _ := strconv.Atoi(c.QueryParam("num"))
+	cursor := c.QueryParam("cursor")
+	ctx := c.Request().Context()
+	hooks, nextCursor, err := a.WebhookUsecase.Fetch(ctx, cursor, int64(num))
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	c.Response().Header().Set(`X-Cursor`, nextCursor)
+	return c.JSON(http.StatusOK, hooks)
+}
+
+func (a *WebhookHandler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	ctx := c.Request().Context()
+	w, err := a.WebhookUsecase.GetByID(ctx, id)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, w)
+}
+
+// Store will create a webhook, the response carries its signing secret
+func (a *WebhookHandler) Store(c echo.Context) (err error) {
+	var w domain.Webhook
+	if err = c.Bind(&w); err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	if err = a.WebhookUsecase.Store(ctx, &w); err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusCreated, w)
+}
+
+func (a *WebhookHandler) Update(c echo.Context) (err error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	var u domain.WebhookUpdate
+	if err = c.Bind(&u); err != nil {
+		return middleware.BindError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	w, err := a.WebhookUsecase.Update(ctx, id, u)
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, w)
+}
+
+func (a *WebhookHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err = a.WebhookUsecase.Delete(ctx, id); err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// FetchDeliveries will list the delivery log of the webhook
+func (a *WebhookHandler) FetchDeliveries(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	num, _ := strconv.Atoi(c.QueryParam("num"))
+	cursor := c.QueryParam("cursor")
+	ctx := c.Request().Context()
+	deliveries, nextCursor, err := a.WebhookUsecase.FetchDeliveries(ctx, id, cursor, int64(num))
+	if err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	c.Response().Header().Set(`X-Cursor`, nextCursor)
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// Replay will schedule a delivery again, the dispatcher attempts it asynchronously
+func (a *WebhookHandler) Replay(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryID"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err = a.WebhookUsecase.Replay(ctx, id, deliveryID); err != nil {
+		return c.JSON(getStatusCode(ctx, err), ResponseError{Message: err.Error()})
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func getStatusCode(ctx context.Context, err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	logging.FromContext(ctx).Error(err)
+	switch err {
+	case domain.ErrNotFound:
+		return http.StatusNotFound
+	case domain.ErrBadParamInput:
+		return http.StatusBadRequest
+	case domain.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case domain.ErrForbidden:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
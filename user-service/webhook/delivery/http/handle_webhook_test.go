@@ -0,0 +1,94 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	webhookHttp "github.com/diantanjung/blogo/user-service/webhook/delivery/http"
+)
+
+func TestStore(t *testing.T) {
+	mockUCase := new(mocks.WebhookUsecase)
+	mockUCase.On("Store", mock.Anything, mock.AnythingOfType("*domain.Webhook")).
+		Run(func(args mock.Arguments) {
+			w := args.Get(1).(*domain.Webhook)
+			w.ID, w.Secret = 1, "whsec_test"
+		}).Return(nil)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/webhooks", strings.NewReader(`{"url":"https://example.com/hook","events":["user.created"]}`))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handler := webhookHttp.WebhookHandler{
+		WebhookUsecase: mockUCase,
+	}
+	err = handler.Store(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"secret":"whsec_test"`)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestFetchDeliveries(t *testing.T) {
+	deliveries := []domain.WebhookDelivery{{ID: 3, WebhookID: 1, EventType: domain.WebhookUserCreated, Status: domain.DeliveryDead}}
+	mockUCase := new(mocks.WebhookUsecase)
+	mockUCase.On("FetchDeliveries", mock.Anything, int64(1), "abc", int64(5)).Return(deliveries, "def", nil)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/webhooks/1/deliveries?num=5&cursor=abc", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/webhooks/:id/deliveries")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	handler := webhookHttp.WebhookHandler{
+		WebhookUsecase: mockUCase,
+	}
+	err = handler.FetchDeliveries(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "def", rec.Header().Get("X-Cursor"))
+	mockUCase.AssertExpectations(t)
+}
+
+func TestReplay(t *testing.T) {
+	mockUCase := new(mocks.WebhookUsecase)
+	mockUCase.On("Replay", mock.Anything, int64(1), int64(3)).Return(nil)
+	mockUCase.On("Replay", mock.Anything, int64(1), int64(4)).Return(domain.ErrForbidden)
+
+	handler := webhookHttp.WebhookHandler{
+		WebhookUsecase: mockUCase,
+	}
+	e := echo.New()
+
+	for deliveryID, code := range map[string]int{"3": http.StatusAccepted, "4": http.StatusForbidden} {
+		req, err := http.NewRequest(echo.POST, "/webhooks/1/deliveries/"+deliveryID+"/replay", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/webhooks/:id/deliveries/:deliveryID/replay")
+		c.SetParamNames("id", "deliveryID")
+		c.SetParamValues("1", deliveryID)
+		err = handler.Replay(c)
+		require.NoError(t, err)
+		assert.Equal(t, code, rec.Code)
+	}
+	mockUCase.AssertExpectations(t)
+}
@@ -0,0 +1,133 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/sqltx"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+)
+
+type psqlWebhookRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlWebhookRepository will create an object that represent the domain.WebhookRepository interface
+func NewPsqlWebhookRepository(Conn *sql.DB) domain.WebhookRepository {
+	return &psqlWebhookRepository{Conn}
+}
+
+func (m *psqlWebhookRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.Webhook, err error) {
+	log := logging.FromContext(ctx).WithField("repository", "psql_webhook")
+	rows, err := sqltx.Conn(ctx, m.Conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			log.Error(errRow)
+		}
+	}()
+
+	result = make([]domain.Webhook, 0)
+	for rows.Next() {
+		w := domain.Webhook{}
+		err = rows.Scan(&w.ID, &w.URL, pq.Array(&w.Events), &w.Secret, &w.Active, &w.CreatedAt, &w.UpdatedAt)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		result = append(result, w)
+	}
+
+	return result, nil
+}
+
+func (m *psqlWebhookRepository) Fetch(ctx context.Context, cursor string, num int64) (res []domain.Webhook, nextCursor string, err error) {
+	query := `SELECT id, url, events, secret, active, created_at, updated_at
+  						FROM webhooks WHERE id > $1 ORDER BY id LIMIT $2`
+
+	decodedCursor, err := repository.DecodeIDCursor(cursor)
+	if err != nil && cursor != "" {
+		return nil, "", domain.ErrBadParamInput
+	}
+
+	res, err = m.fetch(ctx, query, decodedCursor, num)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(res) == int(num) {
+		nextCursor = repository.EncodeIDCursor(res[len(res)-1].ID)
+	}
+	return
+}
+
+func (m *psqlWebhookRepository) GetByID(ctx context.Context, id int64) (res domain.Webhook, err error) {
+	query := `SELECT id, url, events, secret, active, created_at, updated_at FROM webhooks WHERE id = $1`
+
+	list, err := m.fetch(ctx, query, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if len(list) == 0 {
+		return domain.Webhook{}, domain.ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (m *psqlWebhookRepository) GetByEvent(ctx context.Context, event string) ([]domain.Webhook, error) {
+	query := `SELECT id, url, events, secret, active, created_at, updated_at
+  						FROM webhooks WHERE active AND $1 = ANY(events) ORDER BY id`
+
+	return m.fetch(ctx, query, event)
+}
+
+func (m *psqlWebhookRepository) Store(ctx context.Context, w *domain.Webhook) (err error) {
+	query := `INSERT INTO webhooks (url, events, secret, active, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err = sqltx.Conn(ctx, m.Conn).QueryRowContext(ctx, query, w.URL, pq.Array(w.Events), w.Secret, w.Active, w.CreatedAt, w.UpdatedAt).Scan(&w.ID)
+	return
+}
+
+func (m *psqlWebhookRepository) Update(ctx context.Context, w *domain.Webhook) (err error) {
+	query := `UPDATE webhooks SET url=$1, events=$2, active=$3, updated_at=$4 WHERE id=$5`
+
+	res, err := sqltx.Conn(ctx, m.Conn).ExecContext(ctx, query, w.URL, pq.Array(w.Events), w.Active, w.UpdatedAt, w.ID)
+	if err != nil {
+		return
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affect != 1 {
+		return domain.ErrNotFound
+	}
+	return
+}
+
+func (m *psqlWebhookRepository) Delete(ctx context.Context, id int64) (err error) {
+	query := `DELETE FROM webhooks WHERE id = $1`
+
+	res, err := sqltx.Conn(ctx, m.Conn).ExecContext(ctx, query, id)
+	if err != nil {
+		return
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affect != 1 {
+		err = fmt.Errorf("Weird  Behavior. Total Affected: %d", affect)
+	}
+	return
+}
@@ -0,0 +1,131 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+	"github.com/diantanjung/blogo/user-service/sqltx"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+)
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+  						last_status_code, last_error, created_at, updated_at`
+
+type psqlWebhookDeliveryRepository struct {
+	Conn *sql.DB
+}
+
+// NewPsqlWebhookDeliveryRepository will create an object that represent the domain.WebhookDeliveryRepository interface
+func NewPsqlWebhookDeliveryRepository(Conn *sql.DB) domain.WebhookDeliveryRepository {
+	return &psqlWebhookDeliveryRepository{Conn}
+}
+
+func (m *psqlWebhookDeliveryRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.WebhookDelivery, err error) {
+	log := logging.FromContext(ctx).WithField("repository", "psql_webhook_delivery")
+	rows, err := sqltx.Conn(ctx, m.Conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			log.Error(errRow)
+		}
+	}()
+
+	result = make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		d := domain.WebhookDelivery{}
+		var payload []byte
+		err = rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		d.Payload = payload
+		result = append(result, d)
+	}
+
+	return result, nil
+}
+
+func (m *psqlWebhookDeliveryRepository) Store(ctx context.Context, d *domain.WebhookDelivery) (err error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+  						VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+  						ON CONFLICT (webhook_id, event_type, event_id) DO NOTHING RETURNING id`
+
+	err = sqltx.Conn(ctx, m.Conn).QueryRowContext(ctx, query, d.WebhookID, d.EventID, d.EventType, []byte(d.Payload), d.Status, d.NextAttemptAt, d.CreatedAt, d.UpdatedAt).Scan(&d.ID)
+	if err == sql.ErrNoRows {
+		// the event is redelivered by the outbox relay, the delivery already exists
+		return nil
+	}
+	return
+}
+
+func (m *psqlWebhookDeliveryRepository) GetByID(ctx context.Context, id int64) (domain.WebhookDelivery, error) {
+	list, err := m.fetch(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	if len(list) == 0 {
+		return domain.WebhookDelivery{}, domain.ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (m *psqlWebhookDeliveryRepository) Fetch(ctx context.Context, webhookID int64, cursor string, num int64) (res []domain.WebhookDelivery, nextCursor string, err error) {
+	query := `SELECT ` + deliveryColumns + `
+  						FROM webhook_deliveries WHERE webhook_id = $1 AND id > $2 ORDER BY id LIMIT $3`
+
+	// the deliveries of an event are created at once and share their created_at, the ids tell them apart
+	decodedCursor, err := repository.DecodeIDCursor(cursor)
+	if err != nil && cursor != "" {
+		return nil, "", domain.ErrBadParamInput
+	}
+
+	res, err = m.fetch(ctx, query, webhookID, decodedCursor, num)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(res) == int(num) {
+		nextCursor = repository.EncodeIDCursor(res[len(res)-1].ID)
+	}
+	return
+}
+
+func (m *psqlWebhookDeliveryRepository) ClaimDue(ctx context.Context, num int64, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	// SKIP LOCKED lets concurrent dispatchers claim different deliveries instead of waiting on each other
+	query := `UPDATE webhook_deliveries SET locked_until = now() + make_interval(secs => $1)
+  						WHERE id IN (
+  							SELECT id FROM webhook_deliveries
+  							WHERE status = 'pending' AND next_attempt_at <= now() AND (locked_until IS NULL OR locked_until < now())
+  							ORDER BY next_attempt_at LIMIT $2 FOR UPDATE SKIP LOCKED)
+  						RETURNING ` + deliveryColumns
+
+	return m.fetch(ctx, query, lease.Seconds(), num)
+}
+
+func (m *psqlWebhookDeliveryRepository) Update(ctx context.Context, d *domain.WebhookDelivery) (err error) {
+	query := `UPDATE webhook_deliveries SET status=$1, attempts=$2, next_attempt_at=$3, last_status_code=$4, last_error=$5,
+  						updated_at=$6, locked_until=NULL WHERE id=$7`
+
+	res, err := sqltx.Conn(ctx, m.Conn).ExecContext(ctx, query, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.UpdatedAt, d.ID)
+	if err != nil {
+		return
+	}
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affect != 1 {
+		return domain.ErrNotFound
+	}
+	return
+}
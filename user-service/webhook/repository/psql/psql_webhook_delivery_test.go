@@ -0,0 +1,125 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/user/repository"
+	webhookPsqlRepo "github.com/diantanjung/blogo/user-service/webhook/repository/psql"
+)
+
+var deliveryColumns = []string{"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at",
+	"last_status_code", "last_error", "created_at", "updated_at"}
+
+func TestStoreDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	d := &domain.WebhookDelivery{
+		WebhookID:     1,
+		EventID:       "evt-1",
+		EventType:     domain.WebhookUserCreated,
+		Payload:       []byte(`{"id":"evt-1"}`),
+		Status:        domain.DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	query := "INSERT INTO webhook_deliveries .* ON CONFLICT \\(webhook_id, event_type, event_id\\) DO NOTHING RETURNING id"
+	mock.ExpectQuery(query).
+		WithArgs(int64(1), "evt-1", domain.WebhookUserCreated, []byte(`{"id":"evt-1"}`), domain.DeliveryPending, now, now, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	r := webhookPsqlRepo.NewPsqlWebhookDeliveryRepository(db)
+
+	err = r.Store(context.TODO(), d)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), d.ID)
+
+	// a redelivered event conflicts with the stored delivery and is ignored
+	dup := *d
+	dup.ID = 0
+	err = r.Store(context.TODO(), &dup)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), dup.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimDueDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows(deliveryColumns).
+		AddRow(3, 1, "evt-1", domain.WebhookUserCreated, []byte(`{"id":"evt-1"}`), domain.DeliveryPending, 0, now, 0, "", now, now)
+
+	query := "UPDATE webhook_deliveries SET locked_until = .* FOR UPDATE SKIP LOCKED\\)"
+	mock.ExpectQuery(query).WithArgs(float64(60), int64(10)).WillReturnRows(rows)
+
+	r := webhookPsqlRepo.NewPsqlWebhookDeliveryRepository(db)
+
+	list, err := r.ClaimDue(context.TODO(), 10, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "evt-1", list[0].EventID)
+	assert.JSONEq(t, `{"id":"evt-1"}`, string(list[0].Payload))
+}
+
+func TestUpdateDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	d := &domain.WebhookDelivery{ID: 3, Status: domain.DeliveryPending, Attempts: 2, NextAttemptAt: now, LastStatusCode: 503,
+		LastError: "webhook answered 503 Service Unavailable", UpdatedAt: now}
+
+	query := "UPDATE webhook_deliveries SET status=\\$1, attempts=\\$2, next_attempt_at=\\$3, last_status_code=\\$4, last_error=\\$5,\\s+updated_at=\\$6, locked_until=NULL WHERE id=\\$7"
+	mock.ExpectExec(query).
+		WithArgs(domain.DeliveryPending, int64(2), now, 503, d.LastError, now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := webhookPsqlRepo.NewPsqlWebhookDeliveryRepository(db)
+
+	err = r.Update(context.TODO(), d)
+	assert.NoError(t, err)
+}
+
+func TestFetchDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	// the deliveries of one event share their created_at
+	now := time.Now()
+	rows := sqlmock.NewRows(deliveryColumns).
+		AddRow(3, 1, "evt-1", domain.WebhookUserCreated, []byte(`{"id":"evt-1"}`), domain.DeliverySucceeded, 1, now, 200, "", now, now).
+		AddRow(4, 1, "evt-1", domain.WebhookUserUpdated, []byte(`{"id":"evt-1"}`), domain.DeliveryPending, 0, now, 0, "", now, now)
+
+	query := "SELECT .* FROM webhook_deliveries WHERE webhook_id = \\$1 AND id > \\$2 ORDER BY id LIMIT \\$3"
+	mock.ExpectQuery(query).WithArgs(int64(1), int64(2), int64(2)).WillReturnRows(rows)
+
+	r := webhookPsqlRepo.NewPsqlWebhookDeliveryRepository(db)
+
+	list, nextCursor, err := r.Fetch(context.TODO(), 1, repository.EncodeIDCursor(2), 2)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, repository.EncodeIDCursor(4), nextCursor)
+
+	_, _, err = r.Fetch(context.TODO(), 1, "not a cursor", 2)
+	assert.Equal(t, domain.ErrBadParamInput, err)
+}
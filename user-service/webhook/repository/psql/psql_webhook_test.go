@@ -0,0 +1,97 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	webhookPsqlRepo "github.com/diantanjung/blogo/user-service/webhook/repository/psql"
+)
+
+var webhookColumns = []string{"id", "url", "events", "secret", "active", "created_at", "updated_at"}
+
+func TestGetWebhooksByEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows(webhookColumns).
+		AddRow(1, "https://example.com/hook", "{user.created,user.deleted}", "whsec_a", true, now, now).
+		AddRow(2, "https://example.org/hook", "{user.created}", "whsec_b", true, now, now)
+
+	query := "SELECT id, url, events, secret, active, created_at, updated_at\\s+FROM webhooks WHERE active AND \\$1 = ANY\\(events\\)"
+	mock.ExpectQuery(query).WithArgs(domain.WebhookUserCreated).WillReturnRows(rows)
+
+	w := webhookPsqlRepo.NewPsqlWebhookRepository(db)
+
+	list, err := w.GetByEvent(context.TODO(), domain.WebhookUserCreated)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, []string{domain.WebhookUserCreated, domain.WebhookUserDeleted}, list[0].Events)
+	assert.Equal(t, "whsec_b", list[1].Secret)
+}
+
+func TestGetWebhookByIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "SELECT id, url, events, secret, active, created_at, updated_at FROM webhooks WHERE id = \\$1"
+	mock.ExpectQuery(query).WithArgs(int64(9)).WillReturnRows(sqlmock.NewRows(webhookColumns))
+
+	w := webhookPsqlRepo.NewPsqlWebhookRepository(db)
+
+	_, err = w.GetByID(context.TODO(), 9)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestStoreWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	hook := &domain.Webhook{
+		URL:       "https://example.com/hook",
+		Events:    []string{domain.WebhookUserCreated},
+		Secret:    "whsec_a",
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	query := "INSERT INTO webhooks \\(url, events, secret, active, created_at, updated_at\\)"
+	mock.ExpectQuery(query).
+		WithArgs(hook.URL, pq.Array(hook.Events), hook.Secret, true, now, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+	w := webhookPsqlRepo.NewPsqlWebhookRepository(db)
+
+	err = w.Store(context.TODO(), hook)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), hook.ID)
+}
+
+func TestUpdateWebhookNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectExec("UPDATE webhooks SET url=\\$1, events=\\$2, active=\\$3, updated_at=\\$4 WHERE id=\\$5").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := webhookPsqlRepo.NewPsqlWebhookRepository(db)
+
+	err = w.Update(context.TODO(), &domain.Webhook{ID: 9, URL: "https://example.com/hook"})
+	assert.Equal(t, domain.ErrNotFound, err)
+}
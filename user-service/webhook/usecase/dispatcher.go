@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+// Headers of webhook deliveries
+const (
+	HeaderSignature  = "X-Blogo-Signature"
+	HeaderEventID    = "X-Blogo-Event-ID"
+	HeaderEventType  = "X-Blogo-Event-Type"
+	HeaderDeliveryID = "X-Blogo-Delivery-ID"
+)
+
+// Sign returns the signature header of a delivery body, "t=<unix time>,v1=<hex HMAC-SHA256 of t.body>".
+// Receivers recompute it with their secret and reject old timestamps to prevent replays.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DispatcherConfig configures the webhook dispatcher
+type DispatcherConfig struct {
+	// Batch is the number of deliveries claimed at once
+	Batch int64
+	// Interval is the wait between polls when no delivery is due
+	Interval time.Duration
+	// Lease is how long claimed deliveries are hidden from other dispatchers
+	Lease time.Duration
+	// Backoff is the wait after the first failed attempt, doubled on every following one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxAttempts is the number of attempts before a delivery is dead-lettered
+	MaxAttempts int64
+}
+
+// Dispatcher posts the due webhook deliveries
+type Dispatcher struct {
+	webhookRepo  domain.WebhookRepository
+	deliveryRepo domain.WebhookDeliveryRepository
+	client       *http.Client
+	cfg          DispatcherConfig
+}
+
+// NewDispatcher will create a Dispatcher posting deliveries with client
+func NewDispatcher(w domain.WebhookRepository, d domain.WebhookDeliveryRepository, client *http.Client, cfg DispatcherConfig) *Dispatcher {
+	return &Dispatcher{webhookRepo: w, deliveryRepo: d, client: client, cfg: cfg}
+}
+
+// Run will dispatch deliveries until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	log := logging.FromContext(ctx).WithField("worker", "webhook_dispatcher")
+	for {
+		n, err := d.DispatchOnce(ctx)
+		if err != nil {
+			log.Error(err)
+		}
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.cfg.Interval):
+		}
+	}
+}
+
+// DispatchOnce will attempt one batch of due deliveries, returning how many were attempted
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.deliveryRepo.ClaimDue(ctx, d.cfg.Batch, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	hooks := make(map[int64]domain.Webhook)
+	for i := range deliveries {
+		del := &deliveries[i]
+		w, ok := hooks[del.WebhookID]
+		if !ok {
+			w, err = d.webhookRepo.GetByID(ctx, del.WebhookID)
+			if err != nil && err != domain.ErrNotFound {
+				return i, err
+			}
+			hooks[del.WebhookID] = w
+		}
+
+		d.attempt(ctx, w, del)
+		if err = d.deliveryRepo.Update(ctx, del); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt will post the delivery once and record the outcome on it
+func (d *Dispatcher) attempt(ctx context.Context, w domain.Webhook, del *domain.WebhookDelivery) {
+	now := time.Now()
+	del.Attempts++
+	del.UpdatedAt = now
+
+	if w.ID == 0 || !w.Active {
+		del.Status = domain.DeliveryDead
+		del.LastError = "webhook is inactive"
+		return
+	}
+
+	status, err := d.post(ctx, w, del, now)
+	del.LastStatusCode = status
+	if err == nil {
+		del.Status = domain.DeliverySucceeded
+		del.LastError = ""
+		return
+	}
+
+	del.LastError = err.Error()
+	log := logging.FromContext(ctx).WithFields(logrus.Fields{"webhook_id": w.ID, "delivery_id": del.ID, "attempts": del.Attempts})
+	if del.Attempts >= d.cfg.MaxAttempts {
+		del.Status = domain.DeliveryDead
+		log.WithError(err).Warn("webhook delivery dead-lettered")
+		return
+	}
+	del.NextAttemptAt = now.Add(d.backoff(del.Attempts))
+	log.WithError(err).Info("webhook delivery failed, retrying")
+}
+
+func (d *Dispatcher) backoff(attempts int64) time.Duration {
+	shift := attempts - 1
+	if shift > 30 {
+		shift = 30
+	}
+	b := d.cfg.Backoff << uint(shift)
+	if b <= 0 || b > d.cfg.MaxBackoff {
+		return d.cfg.MaxBackoff
+	}
+	return b
+}
+
+func (d *Dispatcher) post(ctx context.Context, w domain.Webhook, del *domain.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "blogo-webhooks/1.0")
+	req.Header.Set(HeaderSignature, Sign(w.Secret, now, del.Payload))
+	req.Header.Set(HeaderEventID, del.EventID)
+	req.Header.Set(HeaderEventType, del.EventType)
+	req.Header.Set(HeaderDeliveryID, strconv.FormatInt(del.ID, 10))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook answered %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/webhook/usecase"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"evt-1"}`)
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000."))
+	mac.Write(body)
+
+	sig := usecase.Sign("whsec_test", time.Unix(1700000000, 0), body)
+	assert.Equal(t, "t=1700000000,v1="+hex.EncodeToString(mac.Sum(nil)), sig)
+	assert.NotEqual(t, sig, usecase.Sign("whsec_other", time.Unix(1700000000, 0), body))
+}
+
+func TestDispatchOnce(t *testing.T) {
+	var (
+		gotSignature string
+		gotBody      []byte
+		status       = http.StatusServiceUnavailable
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(usecase.HeaderSignature)
+		gotBody, _ = io.ReadAll(r.Body)
+		assert.Equal(t, "evt-1", r.Header.Get(usecase.HeaderEventID))
+		assert.Equal(t, domain.WebhookUserCreated, r.Header.Get(usecase.HeaderEventType))
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	hook := domain.Webhook{ID: 1, URL: srv.URL, Secret: "whsec_test", Active: true}
+	mockWebhookRepo := new(mocks.WebhookRepository)
+	mockWebhookRepo.On("GetByID", mock.Anything, int64(1)).Return(hook, nil)
+
+	newDelivery := func(attempts int64) domain.WebhookDelivery {
+		return domain.WebhookDelivery{ID: 3, WebhookID: 1, EventID: "evt-1", EventType: domain.WebhookUserCreated,
+			Payload: []byte(`{"id":"evt-1"}`), Status: domain.DeliveryPending, Attempts: attempts}
+	}
+	cfg := usecase.DispatcherConfig{Batch: 10, Lease: time.Minute, Backoff: time.Minute, MaxBackoff: time.Hour, MaxAttempts: 3}
+
+	t.Run("retry", func(t *testing.T) {
+		var updated domain.WebhookDelivery
+		mockDeliveryRepo := new(mocks.WebhookDeliveryRepository)
+		mockDeliveryRepo.On("ClaimDue", mock.Anything, int64(10), time.Minute).Return([]domain.WebhookDelivery{newDelivery(1)}, nil).Once()
+		mockDeliveryRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.WebhookDelivery")).
+			Run(func(args mock.Arguments) { updated = *args.Get(1).(*domain.WebhookDelivery) }).Return(nil).Once()
+
+		start := time.Now()
+		n, err := usecase.NewDispatcher(mockWebhookRepo, mockDeliveryRepo, srv.Client(), cfg).DispatchOnce(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		assert.Equal(t, domain.DeliveryPending, updated.Status)
+		assert.Equal(t, int64(2), updated.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, updated.LastStatusCode)
+		// the second failure waits twice the base backoff
+		assert.WithinDuration(t, start.Add(2*time.Minute), updated.NextAttemptAt, 5*time.Second)
+
+		assert.JSONEq(t, `{"id":"evt-1"}`, string(gotBody))
+		assert.Equal(t, usecase.Sign("whsec_test", updated.UpdatedAt, gotBody), gotSignature)
+		mockDeliveryRepo.AssertExpectations(t)
+	})
+
+	t.Run("dead", func(t *testing.T) {
+		var updated domain.WebhookDelivery
+		mockDeliveryRepo := new(mocks.WebhookDeliveryRepository)
+		mockDeliveryRepo.On("ClaimDue", mock.Anything, int64(10), time.Minute).Return([]domain.WebhookDelivery{newDelivery(2)}, nil).Once()
+		mockDeliveryRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.WebhookDelivery")).
+			Run(func(args mock.Arguments) { updated = *args.Get(1).(*domain.WebhookDelivery) }).Return(nil).Once()
+
+		_, err := usecase.NewDispatcher(mockWebhookRepo, mockDeliveryRepo, srv.Client(), cfg).DispatchOnce(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeliveryDead, updated.Status)
+		assert.Equal(t, int64(3), updated.Attempts)
+		mockDeliveryRepo.AssertExpectations(t)
+	})
+
+	t.Run("succeeded", func(t *testing.T) {
+		status = http.StatusNoContent
+		var updated domain.WebhookDelivery
+		mockDeliveryRepo := new(mocks.WebhookDeliveryRepository)
+		mockDeliveryRepo.On("ClaimDue", mock.Anything, int64(10), time.Minute).Return([]domain.WebhookDelivery{newDelivery(0)}, nil).Once()
+		mockDeliveryRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.WebhookDelivery")).
+			Run(func(args mock.Arguments) { updated = *args.Get(1).(*domain.WebhookDelivery) }).Return(nil).Once()
+
+		_, err := usecase.NewDispatcher(mockWebhookRepo, mockDeliveryRepo, srv.Client(), cfg).DispatchOnce(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeliverySucceeded, updated.Status)
+		assert.Empty(t, updated.LastError)
+		mockDeliveryRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	validator "gopkg.in/go-playground/validator.v9"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/logging"
+)
+
+type webhookUsecase struct {
+	webhookRepo    domain.WebhookRepository
+	deliveryRepo   domain.WebhookDeliveryRepository
+	contextTimeout time.Duration
+}
+
+// NewWebhookUsecase will create new a webhookUsecase object representation of domain.WebhookUsecase interface
+func NewWebhookUsecase(w domain.WebhookRepository, d domain.WebhookDeliveryRepository, timeout time.Duration) domain.WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepo:    w,
+		deliveryRepo:   d,
+		contextTimeout: timeout,
+	}
+}
+
+func (a *webhookUsecase) Fetch(c context.Context, cursor string, num int64) (res []domain.Webhook, nextCursor string, err error) {
+	if num == 0 {
+		num = 10
+	}
+
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	res, nextCursor, err = a.webhookRepo.Fetch(ctx, cursor, num)
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range res {
+		res[i].Secret = ""
+	}
+	return
+}
+
+func (a *webhookUsecase) GetByID(c context.Context, id int64) (res domain.Webhook, err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	res, err = a.webhookRepo.GetByID(ctx, id)
+	res.Secret = ""
+	return
+}
+
+// Store will create an active webhook with a new signing secret, returned only this once
+func (a *webhookUsecase) Store(c context.Context, w *domain.Webhook) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	if err = validator.New().StructExcept(w, "Secret"); err != nil {
+		return domain.ErrBadParamInput
+	}
+
+	if w.Secret, err = newSecret(); err != nil {
+		return
+	}
+	now := time.Now()
+	w.Active = true
+	w.CreatedAt = now
+	w.UpdatedAt = now
+	return a.webhookRepo.Store(ctx, w)
+}
+
+func (a *webhookUsecase) Update(c context.Context, id int64, u domain.WebhookUpdate) (res domain.Webhook, err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	if err = validator.New().Struct(u); err != nil {
+		return res, domain.ErrBadParamInput
+	}
+
+	res, err = a.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return
+	}
+	res.URL = u.URL
+	res.Events = u.Events
+	if u.Active != nil {
+		res.Active = *u.Active
+	}
+	res.UpdatedAt = time.Now()
+	if err = a.webhookRepo.Update(ctx, &res); err != nil {
+		return domain.Webhook{}, err
+	}
+	res.Secret = ""
+	return
+}
+
+func (a *webhookUsecase) Delete(c context.Context, id int64) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	if _, err = a.webhookRepo.GetByID(ctx, id); err != nil {
+		return
+	}
+	return a.webhookRepo.Delete(ctx, id)
+}
+
+func (a *webhookUsecase) FetchDeliveries(c context.Context, webhookID int64, cursor string, num int64) (res []domain.WebhookDelivery, nextCursor string, err error) {
+	if num == 0 {
+		num = 10
+	}
+
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	if _, err = a.webhookRepo.GetByID(ctx, webhookID); err != nil {
+		return
+	}
+	return a.deliveryRepo.Fetch(ctx, webhookID, cursor, num)
+}
+
+// Replay will schedule the delivery again with a fresh set of attempts, dead deliveries included
+func (a *webhookUsecase) Replay(c context.Context, webhookID, deliveryID int64) (err error) {
+	c = logging.WithFields(c, logrus.Fields{"webhook_id": webhookID, "delivery_id": deliveryID})
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	if err = domain.RequireAdmin(ctx); err != nil {
+		return
+	}
+	d, err := a.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return
+	}
+	if d.WebhookID != webhookID {
+		return domain.ErrNotFound
+	}
+
+	now := time.Now()
+	d.Status = domain.DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = now
+	d.UpdatedAt = now
+	return a.deliveryRepo.Update(ctx, &d)
+}
+
+// webhookEvent is the body posted to webhooks
+type webhookEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserID     int64           `json:"user_id"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+func (a *webhookUsecase) Enqueue(c context.Context, e domain.Event) (err error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+
+	types, err := webhookEvents(e)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, t := range types {
+		hooks, err := a.webhookRepo.GetByEvent(ctx, t)
+		if err != nil {
+			return err
+		}
+		if len(hooks) == 0 {
+			continue
+		}
+
+		payload, err := json.Marshal(webhookEvent{ID: e.ID, Type: t, UserID: e.AggregateID, Data: e.Payload, OccurredAt: e.OccurredAt})
+		if err != nil {
+			return err
+		}
+		for _, w := range hooks {
+			err = a.deliveryRepo.Store(ctx, &domain.WebhookDelivery{
+				WebhookID:     w.ID,
+				EventID:       e.ID,
+				EventType:     t,
+				Payload:       payload,
+				Status:        domain.DeliveryPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// webhookEvents maps a domain event to the webhook events it triggers
+func webhookEvents(e domain.Event) ([]string, error) {
+	switch e.Type {
+	case domain.EventUserCreated:
+		return []string{domain.WebhookUserCreated}, nil
+	case domain.EventUserDeleted:
+		return []string{domain.WebhookUserDeleted}, nil
+	case domain.EventUserUpdated:
+		var payload domain.UserEvent
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			return nil, err
+		}
+		types := []string{domain.WebhookUserUpdated}
+		for _, field := range payload.Changed {
+			if field == "email" {
+				types = append(types, domain.WebhookUserEmailChanged)
+			}
+		}
+		return types, nil
+	}
+	return nil, nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,63 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diantanjung/blogo/user-service/domain"
+	"github.com/diantanjung/blogo/user-service/domain/mocks"
+	"github.com/diantanjung/blogo/user-service/webhook/usecase"
+)
+
+func TestUpdate(t *testing.T) {
+	admin := domain.NewContextWithActor(context.TODO(), domain.Actor{UserID: 1, Roles: []domain.Role{domain.RoleAdmin}})
+	hook := domain.Webhook{ID: 1, URL: "https://partner.dev/hooks", Events: []string{domain.WebhookUserCreated},
+		Secret: "whsec_test", Active: true}
+	inactive := false
+
+	cases := []struct {
+		name       string
+		update     domain.WebhookUpdate
+		wantActive bool
+	}{
+		{
+			name:       "active left out",
+			update:     domain.WebhookUpdate{URL: "https://partner.dev/v2/hooks", Events: []string{domain.WebhookUserDeleted}},
+			wantActive: true,
+		},
+		{
+			name:       "deactivated",
+			update:     domain.WebhookUpdate{URL: hook.URL, Events: hook.Events, Active: &inactive},
+			wantActive: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stored domain.Webhook
+			mockWebhookRepo := new(mocks.WebhookRepository)
+			mockWebhookRepo.On("GetByID", mock.Anything, hook.ID).Return(hook, nil)
+			mockWebhookRepo.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = *args.Get(1).(*domain.Webhook)
+			}).Return(nil)
+			u := usecase.NewWebhookUsecase(mockWebhookRepo, nil, time.Second)
+
+			res, err := u.Update(admin, hook.ID, tc.update)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantActive, stored.Active)
+			assert.Equal(t, tc.update.URL, stored.URL)
+			assert.Equal(t, tc.update.Events, stored.Events)
+			assert.Empty(t, res.Secret, "the secret is only returned when the webhook is created")
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		u := usecase.NewWebhookUsecase(new(mocks.WebhookRepository), nil, time.Second)
+		_, err := u.Update(admin, hook.ID, domain.WebhookUpdate{URL: hook.URL})
+		assert.Equal(t, domain.ErrBadParamInput, err)
+	})
+}